@@ -0,0 +1,187 @@
+package compare
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+const missing = "-"
+
+// only env and alias details are compared. they are what drifts between otherwise
+// identical profiles.
+var comparedTypes = []data.DetailType{data.EnvDetail, data.AliasDetail}
+
+type CompareProfileRepository interface {
+	GetAllProfiles() ([]data.Profile, error)
+	GetDetailsByProfileName(name string) ([]data.Detail, error)
+}
+
+// Entry is a key found in either profile. A and B hold the values to show, with secret
+// values masked. The side the key is missing from is empty.
+type Entry struct {
+	Type data.DetailType
+	Key  string
+	A    string
+	B    string
+}
+
+type Result struct {
+	A           string
+	B           string
+	OnlyInA     []Entry
+	OnlyInB     []Entry
+	Differs     []Entry
+	NotCompared []Entry
+	Same        int
+}
+
+// Drifted reports whether the profiles differ in any compared key. Secrets that were
+// not compared do not count as drift.
+func (r Result) Drifted() bool {
+	return len(r.OnlyInA)+len(r.OnlyInB)+len(r.Differs) > 0
+}
+
+// Profiles compares the env and alias details of two stored profiles.
+func Profiles(repository CompareProfileRepository, a, b string) (Result, error) {
+	if a == "" || b == "" {
+		return Result{}, fmt.Errorf("two profile names are required for compare")
+	}
+	profiles, err := repository.GetAllProfiles()
+	if err != nil {
+		return Result{}, err
+	}
+	for _, name := range []string{a, b} {
+		if !slices.ContainsFunc(profiles, func(profile data.Profile) bool { return profile.Name == name }) {
+			return Result{}, fmt.Errorf("profile %s does not exist", name)
+		}
+	}
+	aDetails, err := repository.GetDetailsByProfileName(a)
+	if err != nil {
+		return Result{}, err
+	}
+	bDetails, err := repository.GetDetailsByProfileName(b)
+	if err != nil {
+		return Result{}, err
+	}
+	return Details(a, aDetails, b, bDetails), nil
+}
+
+// Details compares details as stored. Secret values are sealed and never revealed for a
+// comparison, so a key that is secret in either profile is listed as not compared.
+// Entries keep the order of a, followed by the keys only found in b.
+func Details(aName string, a []data.Detail, bName string, b []data.Detail) Result {
+	result := Result{A: aName, B: bName}
+	find := func(details []data.Detail, detail data.Detail) (data.Detail, bool) {
+		for _, candidate := range details {
+			if candidate.DetailType == detail.DetailType && candidate.Key == detail.Key {
+				return candidate, true
+			}
+		}
+		return data.Detail{}, false
+	}
+	for _, detail := range a {
+		if !slices.Contains(comparedTypes, detail.DetailType) {
+			continue
+		}
+		other, ok := find(b, detail)
+		if !ok {
+			result.OnlyInA = append(result.OnlyInA, Entry{Type: detail.DetailType, Key: detail.Key, A: displayValue(detail)})
+			continue
+		}
+		if detail.Secret || other.Secret {
+			result.NotCompared = append(result.NotCompared, Entry{Type: detail.DetailType, Key: detail.Key, A: displayValue(detail), B: displayValue(other)})
+			continue
+		}
+		if data.FormatValue(detail.Kind, detail.Value) == data.FormatValue(other.Kind, other.Value) {
+			result.Same++
+			continue
+		}
+		result.Differs = append(result.Differs, Entry{Type: detail.DetailType, Key: detail.Key, A: displayValue(detail), B: displayValue(other)})
+	}
+	for _, detail := range b {
+		if !slices.Contains(comparedTypes, detail.DetailType) {
+			continue
+		}
+		if _, ok := find(a, detail); !ok {
+			result.OnlyInB = append(result.OnlyInB, Entry{Type: detail.DetailType, Key: detail.Key, B: displayValue(detail)})
+		}
+	}
+	return result
+}
+
+func displayValue(detail data.Detail) string {
+	if detail.Secret {
+		return data.SecretMask
+	}
+	return data.FormatValue(detail.Kind, detail.Value)
+}
+
+// Format lays out the result as sections with the values of both profiles side by side.
+// Values are cut to fit width when it is above zero.
+func Format(r Result, width int) string {
+	typeWidth, keyWidth := len("alias"), len("key")
+	for _, section := range [][]Entry{r.OnlyInA, r.OnlyInB, r.Differs, r.NotCompared} {
+		for _, entry := range section {
+			keyWidth = max(keyWidth, len(entry.Key))
+		}
+	}
+	valueWidth := 0
+	if width > 0 {
+		valueWidth = max((width-typeWidth-keyWidth-8)/2, 8)
+	}
+	cut := func(value string) string {
+		value = strings.ReplaceAll(value, "\n", `\n`)
+		if valueWidth > 0 && len(value) > valueWidth {
+			return value[:valueWidth-3] + "..."
+		}
+		return value
+	}
+	orMissing := func(value string, present bool) string {
+		if !present {
+			return missing
+		}
+		return value
+	}
+	aWidth := len(cut(r.A))
+	for _, section := range [][]Entry{r.OnlyInA, r.Differs, r.NotCompared} {
+		for _, entry := range section {
+			aWidth = max(aWidth, len(cut(entry.A)))
+		}
+	}
+	pad := func(value string) string {
+		return fmt.Sprintf("%-*s", aWidth, value)
+	}
+
+	var b strings.Builder
+	section := func(title string, entries []Entry, inA, inB bool) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s (%d)\n", title, len(entries))
+		fmt.Fprintf(&b, "  %-*s  %-*s  %s  %s\n", typeWidth, "type", keyWidth, "key", pad(cut(r.A)), cut(r.B))
+		for _, entry := range entries {
+			a := pad(cut(orMissing(entry.A, inA)))
+			fmt.Fprintf(&b, "  %-*s  %-*s  %s  %s\n", typeWidth, entry.Type, keyWidth, entry.Key, a, cut(orMissing(entry.B, inB)))
+		}
+	}
+	section("only in "+r.A, r.OnlyInA, true, false)
+	section("only in "+r.B, r.OnlyInB, false, true)
+	section("differs", r.Differs, true, true)
+	section("secret, not compared", r.NotCompared, true, true)
+	fmt.Fprintf(&b, "%d only in %s, %d only in %s, %d differ, %d same", len(r.OnlyInA), r.A, len(r.OnlyInB), r.B, len(r.Differs), r.Same)
+	if len(r.NotCompared) > 0 {
+		fmt.Fprintf(&b, ", %d not compared", len(r.NotCompared))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Write prints the formatted result without cutting values.
+func Write(w io.Writer, r Result) error {
+	_, err := io.WriteString(w, Format(r, 0))
+	return err
+}
@@ -0,0 +1,109 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+type compareRepositoryStub map[string][]data.Detail
+
+func (c compareRepositoryStub) GetAllProfiles() ([]data.Profile, error) {
+	var profiles []data.Profile
+	for name := range c {
+		profiles = append(profiles, data.Profile{Name: name})
+	}
+	return profiles, nil
+}
+
+func (c compareRepositoryStub) GetDetailsByProfileName(name string) ([]data.Detail, error) {
+	return append([]data.Detail{}, c[name]...), nil
+}
+
+func TestProfiles(t *testing.T) {
+	repository := compareRepositoryStub{
+		"staging": {
+			{Key: "CLUSTER", Value: "staging", DetailType: data.EnvDetail},
+			{Key: "REGION", Value: "eu-west-1", DetailType: data.EnvDetail},
+			{Key: "TOKEN", Value: "enc:v2:a", DetailType: data.EnvDetail, Secret: true},
+			{Key: "API_KEY", Value: "plain", DetailType: data.EnvDetail},
+			{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+			{Key: "DEBUG", Value: "1", DetailType: data.EnvDetail},
+			{Key: "PATH", Value: "/opt/staging", DetailType: data.PathDetail},
+		},
+		"prod": {
+			{Key: "CLUSTER", Value: "prod", DetailType: data.EnvDetail},
+			{Key: "REGION", Value: "eu-west-1", DetailType: data.EnvDetail},
+			{Key: "TOKEN", Value: "enc:v2:b", DetailType: data.EnvDetail, Secret: true},
+			{Key: "API_KEY", Value: "enc:v2:c", DetailType: data.EnvDetail, Secret: true},
+			{Key: "k", Value: "kubectl", DetailType: data.AliasDetail, Kind: data.CommandValue},
+			{Key: "deploy", Value: "make deploy", DetailType: data.AliasDetail},
+		},
+	}
+	result, err := Profiles(repository, "staging", "prod")
+	assert.Nil(t, err)
+	assert.Equal(t, Result{
+		A:       "staging",
+		B:       "prod",
+		OnlyInA: []Entry{{Type: data.EnvDetail, Key: "DEBUG", A: "1"}},
+		OnlyInB: []Entry{{Type: data.AliasDetail, Key: "deploy", B: "make deploy"}},
+		Differs: []Entry{
+			{Type: data.EnvDetail, Key: "CLUSTER", A: "staging", B: "prod"},
+			{Type: data.AliasDetail, Key: "k", A: "kubectl", B: "cmd:kubectl"},
+		},
+		NotCompared: []Entry{
+			{Type: data.EnvDetail, Key: "TOKEN", A: data.SecretMask, B: data.SecretMask},
+			{Type: data.EnvDetail, Key: "API_KEY", A: "plain", B: data.SecretMask},
+		},
+		Same: 1,
+	}, result)
+	assert.True(t, result.Drifted())
+
+	result, err = Profiles(repository, "staging", "staging")
+	assert.Nil(t, err)
+	assert.False(t, result.Drifted())
+	assert.Equal(t, 1, len(result.NotCompared))
+
+	_, err = Profiles(repository, "staging", "dev")
+	assert.EqualError(t, err, "profile dev does not exist")
+}
+
+func TestFormat(t *testing.T) {
+	result := Result{
+		A:       "staging",
+		B:       "prod",
+		OnlyInA: []Entry{{Type: data.EnvDetail, Key: "DEBUG", A: "1"}},
+		Differs: []Entry{{Type: data.EnvDetail, Key: "CLUSTER", A: "staging-cluster-in-eu-west-1", B: "prod"}},
+		Same:    3,
+	}
+	assert.Equal(t, `only in staging (1)
+  type   key      staging                       prod
+  env    DEBUG    1                             -
+differs (1)
+  type   key      staging                       prod
+  env    CLUSTER  staging-cluster-in-eu-west-1  prod
+1 only in staging, 0 only in prod, 1 differ, 3 same
+`, Format(result, 0))
+
+	assert.Equal(t, `only in staging (1)
+  type   key      staging   prod
+  env    DEBUG    1         -
+differs (1)
+  type   key      staging   prod
+  env    CLUSTER  stagi...  prod
+1 only in staging, 0 only in prod, 1 differ, 3 same
+`, Format(result, 20))
+
+	result = Result{
+		A:           "staging",
+		B:           "prod",
+		NotCompared: []Entry{{Type: data.EnvDetail, Key: "TOKEN", A: data.SecretMask, B: data.SecretMask}},
+		Same:        1,
+	}
+	assert.Equal(t, `secret, not compared (1)
+  type   key    staging   prod
+  env    TOKEN  <secret>  <secret>
+0 only in staging, 0 only in prod, 0 differ, 1 same, 1 not compared
+`, Format(result, 0))
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DetailEdit is a set of changes made to the details of a profile at once, e.g. in an
+// editor. Values are plaintext. Updated details carry their new key and value along
+// with everything else as stored.
+type DetailEdit struct {
+	Added   []Detail
+	Updated []Detail
+	Deleted []Detail
+}
+
+// Len is the number of details the edit changes.
+func (e DetailEdit) Len() int {
+	return len(e.Added) + len(e.Updated) + len(e.Deleted)
+}
+
+// EditDetails applies every change of edit in one transaction. It is journaled as a
+// single operation, so an undo takes back the whole edit. Nothing is written if any
+// detail fails validation.
+func (mr *MaggiRepository) EditDetails(edit DetailEdit) error {
+	for _, detail := range append(append([]Detail{}, edit.Added...), edit.Updated...) {
+		if err := CheckDetail(detail); err != nil {
+			return fmt.Errorf("%s: %w", describeDetail(detail), err)
+		}
+	}
+	now := mr.timestamp()
+	return mr.mutate("edit "+describeEdit(edit), func(tx *sql.Tx, entry *journalEntry) error {
+		for _, detail := range edit.Deleted {
+			if err := trashDetail(tx, entry, detail, mr.now()); err != nil {
+				return err
+			}
+		}
+		for _, detail := range edit.Updated {
+			before, err := getDetail(tx, detail.ID)
+			if err != nil {
+				return err
+			}
+			value, err := mr.sealValue(detail.Value, detail.Secret)
+			if err != nil {
+				return err
+			}
+			setDetailDefaults(&detail)
+			stmt := "UPDATE details SET key = ?, value = ?, secret = ?, kind = ?, position = ?, separator = ?, guard = ?, shells = ?, description = ?, updated_at = ? WHERE id = ?;"
+			_, err = tx.Exec(stmt, detail.Key, value, detail.Secret, detail.Kind.String(), detail.Position.String(), detail.Separator, detail.Guard, strings.Join(detail.Shells, ","), detail.Description, now.Unix(), detail.ID)
+			if err != nil {
+				return err
+			}
+			after, err := getDetail(tx, detail.ID)
+			if err != nil {
+				return err
+			}
+			entry.detail(&before, &after)
+		}
+		for _, detail := range edit.Added {
+			value, err := mr.sealValue(detail.Value, detail.Secret)
+			if err != nil {
+				return err
+			}
+			setDetailDefaults(&detail)
+			detail.Value = value
+			detail.CreatedAt = now
+			detail.UpdatedAt = now
+			id, err := insertDetail(tx, detail)
+			if err != nil {
+				return err
+			}
+			detail.ID = id
+			added := detail
+			entry.detail(nil, &added)
+		}
+		return nil
+	})
+}
+
+// describeEdit names an edit in journal labels, e.g. "env EDITOR" or "3 details"
+func describeEdit(edit DetailEdit) string {
+	var details []Detail
+	details = append(details, edit.Added...)
+	details = append(details, edit.Updated...)
+	details = append(details, edit.Deleted...)
+	return describeDetails(details)
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditDetails(t *testing.T) {
+	repository := newTestRepository(t)
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	for _, detail := range []Detail{
+		{Key: "EDITOR", Value: "vim", DetailType: EnvDetail, ProfileID: work.ID},
+		{Key: "PAGER", Value: "less", DetailType: EnvDetail, ProfileID: work.ID},
+		{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: work.ID, Secret: true},
+	} {
+		_, err := repository.AddDetail(detail)
+		require.Nil(t, err)
+	}
+	before, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+
+	editor, pager, token := before[0], before[1], before[2]
+	editor.Key = "VISUAL"
+	editor.Value = "nvim"
+	token.Value = "def"
+	edit := DetailEdit{
+		Added:   []Detail{{Key: "ll", Value: "ls -l", DetailType: AliasDetail, ProfileID: work.ID}},
+		Updated: []Detail{editor, token},
+		Deleted: []Detail{pager},
+	}
+	assert.Equal(t, 4, edit.Len())
+	require.Nil(t, repository.EditDetails(edit))
+	after, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	require.Len(t, after, 3)
+	assert.Equal(t, "VISUAL", after[0].Key)
+	assert.Equal(t, "nvim", after[0].Value)
+	assert.True(t, after[1].Secret)
+	revealed, err := repository.RevealValue(after[1])
+	require.Nil(t, err)
+	assert.Equal(t, "def", revealed)
+	assert.Equal(t, "ll", after[2].Key)
+
+	// the whole edit is a single operation
+	op, err := repository.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "edit 4 details", op.Label)
+	undone, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, before, undone)
+
+	// an invalid detail stops the whole edit
+	pager.Value = "more"
+	err = repository.EditDetails(DetailEdit{
+		Added:   []Detail{{Key: "1BAD", Value: "x", DetailType: EnvDetail, ProfileID: work.ID}},
+		Updated: []Detail{pager},
+	})
+	assert.ErrorContains(t, err, "env 1BAD")
+	unchanged, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, before, unchanged)
+}
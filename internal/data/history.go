@@ -0,0 +1,244 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source is where a change to the repository came from, as kept in the history
+type Source string
+
+func (s Source) String() string {
+	return string(s)
+}
+
+const (
+	TUISource Source = "tui"
+	CLISource Source = "cli"
+)
+
+// HistoryAction is what a change did to a value
+type HistoryAction string
+
+func (h HistoryAction) String() string {
+	return string(h)
+}
+
+const (
+	HistoryAdded   HistoryAction = "add"
+	HistoryUpdated HistoryAction = "update"
+	HistoryDeleted HistoryAction = "delete"
+)
+
+var ErrNotRestorable = errors.New("history entry has no value to restore")
+
+// HistoryEntry is one value change of a detail, or a rename of a profile. DetailType
+// and Key are empty for profile changes, whose values are the profile names. Values
+// of secrets stay sealed, see OldSecret and NewSecret. OldKind and NewKind are empty for
+// changes recorded before kinds were kept.
+type HistoryEntry struct {
+	ID          int
+	ProfileID   int
+	ProfileName string
+	DetailID    int
+	DetailType  DetailType
+	Key         string
+	Action      HistoryAction
+	OldValue    string
+	NewValue    string
+	OldSecret   bool
+	NewSecret   bool
+	OldKind     ValueKind
+	NewKind     ValueKind
+	Source      Source
+	CreatedAt   time.Time
+}
+
+// HistoryFilter narrows GetHistory down. Empty fields match everything. ProfileName
+// matches the name at the time of the change as well as the current one.
+type HistoryFilter struct {
+	ProfileName string
+	Key         string
+	DetailID    int
+}
+
+// SetSource sets where the following changes come from. Changes are recorded as
+// coming from the cli unless told otherwise.
+func (mr *MaggiRepository) SetSource(source Source) {
+	mr.source = source
+}
+
+// recordHistory adds a history row for every change that touched a value. Rows in
+// the trash count as gone, so deleting and restoring show as delete and add.
+func (mr *MaggiRepository) recordHistory(tx *sql.Tx, changes []journalChange) error {
+	source := mr.source
+	if source == "" {
+		source = CLISource
+	}
+	now := mr.now().Unix()
+	names := make(map[int]string)
+	for _, change := range changes {
+		if change.Profile == nil {
+			continue
+		}
+		if after := change.Profile.After; after != nil {
+			names[after.ID] = after.Name
+		} else {
+			names[change.Profile.Before.ID] = change.Profile.Before.Name
+		}
+	}
+	stmt := "INSERT INTO history (profile_id, profile_name, detail_id, type, key, old_value, new_value, old_secret, new_secret, old_kind, new_kind, source, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);"
+	for _, change := range changes {
+		if change.Profile != nil {
+			before, after := change.Profile.Before, change.Profile.After
+			var oldName, newName sql.NullString
+			if before != nil && before.DeletedAt.IsZero() {
+				oldName = sql.NullString{String: before.Name, Valid: true}
+			}
+			if after != nil && after.DeletedAt.IsZero() {
+				newName = sql.NullString{String: after.Name, Valid: true}
+			}
+			if oldName == newName {
+				continue
+			}
+			profile := after
+			if profile == nil {
+				profile = before
+			}
+			if _, err := tx.Exec(stmt, profile.ID, profile.Name, 0, "", "", oldName, newName, false, false, "", "", source.String(), now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		before, after := change.Detail.Before, change.Detail.After
+		var oldValue, newValue sql.NullString
+		if before != nil && before.DeletedAt.IsZero() {
+			oldValue = sql.NullString{String: before.Value, Valid: true}
+		}
+		if after != nil && after.DeletedAt.IsZero() {
+			newValue = sql.NullString{String: after.Value, Valid: true}
+		}
+		detail := after
+		if detail == nil {
+			detail = before
+		}
+		// flags alone, e.g. the position of a path, are left to the journal
+		if oldValue == newValue && (!oldValue.Valid || before.Key == after.Key) {
+			continue
+		}
+		name, ok := names[detail.ProfileID]
+		if !ok {
+			profile, err := getProfile(tx, detail.ProfileID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			name = profile.Name
+			names[detail.ProfileID] = name
+		}
+		var oldSecret, newSecret bool
+		var oldKind, newKind ValueKind
+		if before != nil {
+			oldSecret, oldKind = before.Secret, before.Kind
+		}
+		if after != nil {
+			newSecret, newKind = after.Secret, after.Kind
+		}
+		if _, err := tx.Exec(stmt, detail.ProfileID, name, detail.ID, detail.DetailType.String(), detail.Key, oldValue, newValue, oldSecret, newSecret, oldKind.String(), newKind.String(), source.String(), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistory returns the changes matching the filter, newest first
+func (mr *MaggiRepository) GetHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	var conditions []string
+	var args []any
+	if filter.ProfileName != "" {
+		// the profile may have been renamed since
+		conditions = append(conditions, "(profile_name = ? OR profile_id IN (SELECT id FROM profiles WHERE name = ?))")
+		args = append(args, filter.ProfileName, filter.ProfileName)
+	}
+	if filter.Key != "" {
+		conditions = append(conditions, "key = ?")
+		args = append(args, filter.Key)
+	}
+	if filter.DetailID != 0 {
+		conditions = append(conditions, "detail_id = ?")
+		args = append(args, filter.DetailID)
+	}
+	stmt := "SELECT id, profile_id, profile_name, detail_id, type, key, old_value, new_value, old_secret, new_secret, old_kind, new_kind, source, created_at FROM history"
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := mr.db.Query(stmt+" ORDER BY id DESC;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []HistoryEntry{}
+	for rows.Next() {
+		var entry HistoryEntry
+		var typeStr, oldKind, newKind, sourceStr string
+		var oldValue, newValue sql.NullString
+		var createdAt int64
+		err := rows.Scan(&entry.ID, &entry.ProfileID, &entry.ProfileName, &entry.DetailID, &typeStr, &entry.Key, &oldValue, &newValue, &entry.OldSecret, &entry.NewSecret, &oldKind, &newKind, &sourceStr, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.DetailType = DetailType(typeStr)
+		entry.OldKind = ValueKind(oldKind)
+		entry.NewKind = ValueKind(newKind)
+		entry.Source = Source(sourceStr)
+		entry.OldValue = oldValue.String
+		entry.NewValue = newValue.String
+		entry.CreatedAt = time.Unix(createdAt, 0)
+		switch {
+		case !oldValue.Valid:
+			entry.Action = HistoryAdded
+		case !newValue.Valid:
+			entry.Action = HistoryDeleted
+		default:
+			entry.Action = HistoryUpdated
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RevealHistoryValue returns the plaintext value the entry set
+func (mr *MaggiRepository) RevealHistoryValue(entry HistoryEntry) (string, error) {
+	if entry.Action == HistoryDeleted {
+		return "", ErrNotRestorable
+	}
+	return mr.RevealValue(Detail{Value: entry.NewValue, Secret: entry.NewSecret})
+}
+
+// RestoreValue sets the detail back to the value set by a history entry, along with
+// whether it was secret and its kind, so a secret is never restored in plaintext and
+// literal text never turns into a command. Entries from before kinds were kept restore
+// as literals. The restore is an update like any other, so it shows up in the history
+// and can be undone.
+func (mr *MaggiRepository) RestoreValue(detail Detail, entry HistoryEntry) (*Detail, error) {
+	if entry.DetailType == "" {
+		return nil, fmt.Errorf("%w: profile %s", ErrNotRestorable, entry.ProfileName)
+	}
+	value, err := mr.RevealHistoryValue(entry)
+	if err != nil {
+		return nil, err
+	}
+	detail.Secret = entry.NewSecret
+	detail.Kind = entry.NewKind
+	if detail.Kind == "" {
+		detail.Kind = LiteralValue
+	}
+	return mr.UpdateDetail(detail, detail.Key, value)
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	repository := newTestRepository(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	editor, err := repository.AddDetail(Detail{Key: "EDITOR", Value: "vim", DetailType: EnvDetail, ProfileID: work.ID})
+	require.Nil(t, err)
+	token, err := repository.AddDetail(Detail{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: work.ID, Secret: true})
+	require.Nil(t, err)
+	now = now.Add(time.Minute)
+	repository.SetSource(TUISource)
+	editor, err = repository.UpdateDetail(*editor, "EDITOR", "nvim")
+	require.Nil(t, err)
+	_, err = repository.UpdateDetail(*token, "TOKEN", "def")
+	require.Nil(t, err)
+	require.Nil(t, repository.DeleteDetail(*editor))
+	_, err = repository.UpdateProfile(work, "office")
+	require.Nil(t, err)
+
+	entries, err := repository.GetHistory(HistoryFilter{Key: "EDITOR"})
+	require.Nil(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, HistoryEntry{ID: entries[1].ID, ProfileID: work.ID, ProfileName: "work", DetailID: editor.ID, DetailType: EnvDetail, Key: "EDITOR", Action: HistoryUpdated, OldValue: "vim", NewValue: "nvim", OldKind: LiteralValue, NewKind: LiteralValue, Source: TUISource, CreatedAt: time.Unix(now.Unix(), 0)}, entries[1])
+	assert.Equal(t, HistoryDeleted, entries[0].Action)
+	assert.Equal(t, HistoryAdded, entries[2].Action)
+	assert.Equal(t, CLISource, entries[2].Source)
+
+	// secrets stay sealed until revealed
+	entries, err = repository.GetHistory(HistoryFilter{DetailID: token.ID})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, entries[0].OldSecret)
+	assert.NotEqual(t, "def", entries[0].NewValue)
+	value, err := repository.RevealHistoryValue(entries[0])
+	require.Nil(t, err)
+	assert.Equal(t, "def", value)
+
+	// the current name also finds changes made under the old one, and the rename itself
+	entries, err = repository.GetHistory(HistoryFilter{ProfileName: "office"})
+	require.Nil(t, err)
+	require.Len(t, entries, 7)
+	assert.Equal(t, HistoryEntry{ID: entries[0].ID, ProfileID: work.ID, ProfileName: "office", Action: HistoryUpdated, OldValue: "work", NewValue: "office", Source: TUISource, CreatedAt: time.Unix(now.Unix(), 0)}, entries[0])
+
+	// undoing is recorded in the direction it was applied
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	entries, err = repository.GetHistory(HistoryFilter{Key: "EDITOR"})
+	require.Nil(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, HistoryAdded, entries[0].Action)
+	assert.Equal(t, "nvim", entries[0].NewValue)
+}
+
+func TestRestoreValue(t *testing.T) {
+	repository := newTestRepository(t)
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	token, err := repository.AddDetail(Detail{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: work.ID, Secret: true})
+	require.Nil(t, err)
+	token, err = repository.UpdateDetail(*token, "TOKEN", "def")
+	require.Nil(t, err)
+
+	entries, err := repository.GetHistory(HistoryFilter{DetailID: token.ID})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	restored, err := repository.RestoreValue(*token, entries[1])
+	require.Nil(t, err)
+	value, err := repository.RevealValue(*restored)
+	require.Nil(t, err)
+	assert.Equal(t, "abc", value)
+
+	require.Nil(t, repository.DeleteDetail(*restored))
+	entries, err = repository.GetHistory(HistoryFilter{DetailID: token.ID})
+	require.Nil(t, err)
+	_, err = repository.RestoreValue(*restored, entries[0])
+	assert.ErrorIs(t, err, ErrNotRestorable)
+}
+
+func TestRestoreValueFlags(t *testing.T) {
+	repository := newTestRepository(t)
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+
+	// a secret restored onto a detail that is no longer secret is sealed again
+	token, err := repository.AddDetail(Detail{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: work.ID, Secret: true})
+	require.Nil(t, err)
+	plain := *token
+	plain.Secret = false
+	token, err = repository.UpdateDetail(plain, "TOKEN", "public")
+	require.Nil(t, err)
+	entries, err := repository.GetHistory(HistoryFilter{DetailID: token.ID})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, entries[1].NewSecret)
+	restored, err := repository.RestoreValue(*token, entries[1])
+	require.Nil(t, err)
+	assert.True(t, restored.Secret)
+	details, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.True(t, details[0].Secret)
+	assert.NotContains(t, details[0].Value, "abc")
+	value, err := repository.RevealValue(details[0])
+	require.Nil(t, err)
+	assert.Equal(t, "abc", value)
+
+	// a literal restored onto a detail that is now a command stays a literal
+	editor, err := repository.AddDetail(Detail{Key: "EDITOR", Value: "rm -rf build", DetailType: EnvDetail, ProfileID: work.ID, Kind: LiteralValue})
+	require.Nil(t, err)
+	command := *editor
+	command.Kind = CommandValue
+	editor, err = repository.UpdateDetail(command, "EDITOR", "which nvim")
+	require.Nil(t, err)
+	entries, err = repository.GetHistory(HistoryFilter{DetailID: editor.ID})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, CommandValue, entries[0].NewKind)
+	assert.Equal(t, LiteralValue, entries[1].NewKind)
+	restored, err = repository.RestoreValue(*editor, entries[1])
+	require.Nil(t, err)
+	assert.Equal(t, LiteralValue, restored.Kind)
+	details, err = repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, LiteralValue, details[1].Kind)
+	assert.Equal(t, "rm -rf build", details[1].Value)
+}
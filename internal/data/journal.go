@@ -0,0 +1,245 @@
+package data
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultJournalWindow is how long an operation can be undone after it was made.
+// Older entries are dropped from the journal.
+const DefaultJournalWindow = 12 * time.Hour
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Operation is one entry of the journal: a single add, update or delete as made by
+// the user, which can touch several rows (e.g. a profile and all its details).
+type Operation struct {
+	ID        int
+	Label     string
+	CreatedAt time.Time
+}
+
+// journalChange is one row an operation changed. Before is nil for an insert and
+// After is nil for a delete. Detail values are kept as stored, so secrets stay sealed.
+type journalChange struct {
+	Profile *profileChange `json:"profile,omitempty"`
+	Detail  *detailChange  `json:"detail,omitempty"`
+}
+
+type profileChange struct {
+	Before *Profile `json:"before,omitempty"`
+	After  *Profile `json:"after,omitempty"`
+}
+
+type detailChange struct {
+	Before *Detail `json:"before,omitempty"`
+	After  *Detail `json:"after,omitempty"`
+}
+
+// inverse is the change that takes the row back to how it was
+func (c journalChange) inverse() journalChange {
+	switch {
+	case c.Profile != nil:
+		return journalChange{Profile: &profileChange{Before: c.Profile.After, After: c.Profile.Before}}
+	case c.Detail != nil:
+		return journalChange{Detail: &detailChange{Before: c.Detail.After, After: c.Detail.Before}}
+	}
+	return c
+}
+
+// journalEntry collects the changes of an operation while it runs
+type journalEntry struct {
+	label   string
+	changes []journalChange
+}
+
+func (j *journalEntry) profile(before, after *Profile) {
+	j.changes = append(j.changes, journalChange{Profile: &profileChange{Before: before, After: after}})
+}
+
+func (j *journalEntry) detail(before, after *Detail) {
+	j.changes = append(j.changes, journalChange{Detail: &detailChange{Before: before, After: after}})
+}
+
+// mutate runs fn in a transaction and journals what it changed in the same
+// transaction, so an operation is never applied without its journal entry.
+func (mr *MaggiRepository) mutate(label string, fn func(tx *sql.Tx, entry *journalEntry) error) error {
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return err
+	}
+	entry := &journalEntry{label: label}
+	if err := fn(tx, entry); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := mr.record(tx, entry); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := mr.recordHistory(tx, entry.changes); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+func (mr *MaggiRepository) record(tx *sql.Tx, entry *journalEntry) error {
+	if len(entry.changes) == 0 {
+		return nil
+	}
+	changes, err := json.Marshal(entry.changes)
+	if err != nil {
+		return err
+	}
+	now := mr.now()
+	// a new operation starts a new branch, so whatever was undone can't be redone
+	if _, err := tx.Exec("DELETE FROM journal WHERE undone = 1 OR created_at < ?;", now.Add(-mr.journalWindow).Unix()); err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO journal (label, changes, undone, created_at) VALUES (?, ?, 0, ?);", entry.label, string(changes), now.Unix())
+	return err
+}
+
+// Undo reverts the latest operation in the journal window. ErrNothingToUndo is
+// returned if there is none.
+func (mr *MaggiRepository) Undo() (Operation, error) {
+	stmt := "SELECT id, label, changes, created_at FROM journal WHERE undone = 0 AND created_at >= ? ORDER BY id DESC LIMIT 1;"
+	return mr.replay(stmt, ErrNothingToUndo, true)
+}
+
+// Redo applies the operation undone last again. ErrNothingToRedo is returned if
+// nothing was undone since the last operation.
+func (mr *MaggiRepository) Redo() (Operation, error) {
+	stmt := "SELECT id, label, changes, created_at FROM journal WHERE undone = 1 AND created_at >= ? ORDER BY id ASC LIMIT 1;"
+	return mr.replay(stmt, ErrNothingToRedo, false)
+}
+
+func (mr *MaggiRepository) replay(stmt string, errNone error, undo bool) (Operation, error) {
+	var op Operation
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return op, err
+	}
+	var changesStr string
+	var createdAt int64
+	err = tx.QueryRow(stmt, mr.now().Add(-mr.journalWindow).Unix()).Scan(&op.ID, &op.Label, &changesStr, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return op, errors.Join(errNone, tx.Rollback())
+	}
+	if err != nil {
+		return op, errors.Join(err, tx.Rollback())
+	}
+	op.CreatedAt = time.Unix(createdAt, 0)
+
+	var changes []journalChange
+	if err := json.Unmarshal([]byte(changesStr), &changes); err != nil {
+		return op, errors.Join(err, tx.Rollback())
+	}
+	if undo {
+		// the inverse of each change, last one first
+		slices.Reverse(changes)
+	}
+	applied := make([]journalChange, 0, len(changes))
+	for _, change := range changes {
+		if undo {
+			change = change.inverse()
+		}
+		var err error
+		switch {
+		case change.Profile != nil:
+			err = writeProfile(tx, change.Profile.Before, change.Profile.After)
+		case change.Detail != nil:
+			err = writeDetail(tx, change.Detail.Before, change.Detail.After)
+		}
+		if err != nil {
+			return op, errors.Join(fmt.Errorf("replaying %q: %w", op.Label, err), tx.Rollback())
+		}
+		applied = append(applied, change)
+	}
+	if err := mr.recordHistory(tx, applied); err != nil {
+		return op, errors.Join(err, tx.Rollback())
+	}
+
+	if _, err := tx.Exec("UPDATE journal SET undone = ? WHERE id = ?;", undo, op.ID); err != nil {
+		return op, errors.Join(err, tx.Rollback())
+	}
+	return op, tx.Commit()
+}
+
+// writeProfile turns the row from into to. rows are written with their original id,
+// so later entries in the journal still point at them. a row purged from the trash
+// since can't be brought back, which is reported rather than silently skipped.
+func writeProfile(tx *sql.Tx, from, to *Profile) error {
+	var res sql.Result
+	var err error
+	switch {
+	case to == nil:
+		if err := writeTags(tx, from.ID, nil); err != nil {
+			return err
+		}
+		res, err = tx.Exec("DELETE FROM profiles WHERE id = ?;", from.ID)
+	case from == nil:
+		res, err = tx.Exec("INSERT INTO profiles (id, name, description, deleted_at, created_at, updated_at, last_applied_at) VALUES (?, ?, ?, ?, ?, ?, ?);", to.ID, to.Name, to.Description, nullUnix(to.DeletedAt), nullUnix(to.CreatedAt), nullUnix(to.UpdatedAt), nullUnix(to.LastAppliedAt))
+	default:
+		// last_applied_at is left alone, as applying a profile is not journaled
+		res, err = tx.Exec("UPDATE profiles SET name = ?, description = ?, deleted_at = ?, created_at = ?, updated_at = ? WHERE id = ?;", to.Name, to.Description, nullUnix(to.DeletedAt), nullUnix(to.CreatedAt), nullUnix(to.UpdatedAt), to.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkWritten(res, "profile"); err != nil || to == nil {
+		return err
+	}
+	return writeTags(tx, to.ID, to.Tags)
+}
+
+func writeDetail(tx *sql.Tx, from, to *Detail) error {
+	var res sql.Result
+	var err error
+	switch {
+	case to == nil:
+		res, err = tx.Exec("DELETE FROM details WHERE id = ?;", from.ID)
+	case from == nil:
+		_, err = insertDetail(tx, *to)
+		return err
+	default:
+		stmt := "UPDATE details SET key = ?, value = ?, type = ?, profile_id = ?, secret = ?, kind = ?, position = ?, separator = ?, guard = ?, shells = ?, deleted_at = ?, created_at = ?, updated_at = ?, description = ? WHERE id = ?;"
+		res, err = tx.Exec(stmt, to.Key, to.Value, to.DetailType.String(), to.ProfileID, to.Secret, to.Kind.String(), to.Position.String(), to.Separator, to.Guard, strings.Join(to.Shells, ","), nullUnix(to.DeletedAt), nullUnix(to.CreatedAt), nullUnix(to.UpdatedAt), to.Description, to.ID)
+	}
+	if err != nil {
+		return err
+	}
+	return checkWritten(res, "detail")
+}
+
+func checkWritten(res sql.Result, row string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s no longer exists", row)
+	}
+	return nil
+}
+
+// nullUnix stores a zero time as null
+func nullUnix(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Unix()
+}
+
+func unixTime(v sql.NullInt64) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+	return time.Unix(v.Int64, 0)
+}
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	repository := newTestRepository(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	editor, err := repository.AddDetail(Detail{Key: "EDITOR", Value: "vim", DetailType: EnvDetail, ProfileID: work.ID})
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: work.ID, Secret: true})
+	require.Nil(t, err)
+	_, err = repository.UpdateDetail(*editor, "EDITOR", "nvim")
+	require.Nil(t, err)
+	before, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+
+	require.Nil(t, repository.DeleteProfile(work))
+	op, err := repository.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "delete profile work", op.Label)
+	profiles, err := repository.GetAllProfiles()
+	require.Nil(t, err)
+	assert.Equal(t, []Profile{work}, profiles)
+	restored, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, before, restored)
+	token, err := repository.RevealValue(restored[1])
+	require.Nil(t, err)
+	assert.Equal(t, "abc", token)
+
+	op, err = repository.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "update env EDITOR", op.Label)
+	details, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "vim", details[0].Value)
+
+	// redo replays in the original order and survives a new repository on the same db
+	repository = &MaggiRepository{db: repository.db, loadKey: repository.loadKey, now: repository.now, journalWindow: DefaultJournalWindow}
+	op, err = repository.Redo()
+	require.Nil(t, err)
+	assert.Equal(t, "update env EDITOR", op.Label)
+	op, err = repository.Redo()
+	require.Nil(t, err)
+	assert.Equal(t, "delete profile work", op.Label)
+	_, err = repository.Redo()
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+	profiles, err = repository.GetAllProfiles()
+	require.Nil(t, err)
+	assert.Empty(t, profiles)
+
+	// a new operation drops what could be redone
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	_, err = repository.AddProfile("home")
+	require.Nil(t, err)
+	_, err = repository.Redo()
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	// operations older than the window are out of reach
+	now = now.Add(DefaultJournalWindow + time.Minute)
+	_, err = repository.Undo()
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+func TestJournalTransfer(t *testing.T) {
+	repository := newTestRepository(t)
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	home, err := repository.AddProfile("home")
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "EDITOR", Value: "vim", DetailType: EnvDetail, ProfileID: work.ID})
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "EDITOR", Value: "nano", DetailType: EnvDetail, ProfileID: home.ID})
+	require.Nil(t, err)
+	workDetails, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	homeDetails, err := repository.GetAllDetails(home.ID)
+	require.Nil(t, err)
+
+	_, err = repository.MoveDetails(workDetails, home, OverwriteConflicts)
+	require.Nil(t, err)
+	op, err := repository.Undo()
+	require.Nil(t, err)
+	assert.Equal(t, "move env EDITOR to home", op.Label)
+	restored, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, workDetails, restored)
+	restored, err = repository.GetAllDetails(home.ID)
+	require.Nil(t, err)
+	assert.Equal(t, homeDetails, restored)
+}
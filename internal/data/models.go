@@ -1,8 +1,25 @@
 package data
 
+import (
+	"strings"
+	"time"
+)
+
 type Profile struct {
 	ID   int
 	Name string
+	// Description is an optional note on what the profile is for
+	Description string
+	// Tags are sorted, nil when the profile has none
+	Tags []string
+	// DeletedAt is set while the profile is in the trash
+	DeletedAt time.Time
+	// CreatedAt, UpdatedAt and LastAppliedAt are zero when not known, for profiles
+	// added before they were tracked. UpdatedAt as listed by GetAllProfiles includes
+	// changes to the details of the profile.
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	LastAppliedAt time.Time
 }
 
 type DetailType string
@@ -14,12 +31,86 @@ func (d DetailType) String() string {
 const (
 	AliasDetail DetailType = "alias"
 	EnvDetail   DetailType = "env"
+	PathDetail  DetailType = "path"
+	// FunctionDetail holds a shell function. Key is the function name and Value the
+	// body, which can span multiple lines.
+	FunctionDetail DetailType = "function"
+	// SourceDetail sources the file in Value. Key is only a label.
+	SourceDetail DetailType = "source"
+	// RawDetail is a shell snippet written to the generated script as is. Key is only
+	// a label.
+	RawDetail DetailType = "raw"
+)
+
+// PathPosition decides where a path detail adds its entry to the list held by the key.
+type PathPosition string
+
+func (p PathPosition) String() string {
+	return string(p)
+}
+
+const (
+	PrependPath PathPosition = "prepend"
+	AppendPath  PathPosition = "append"
 )
 
+const DefaultPathSeparator = ":"
+
+// ValueKind decides how a detail value is turned into the final value during generate.
+type ValueKind string
+
+func (v ValueKind) String() string {
+	return string(v)
+}
+
+const (
+	LiteralValue ValueKind = "literal"
+	CommandValue ValueKind = "cmd"
+	FileValue    ValueKind = "file"
+)
+
+// ParseValue splits user input like `cmd:pass show foo` into its kind and value.
+// Input without a known prefix is a literal.
+func ParseValue(input string) (ValueKind, string) {
+	for _, kind := range []ValueKind{CommandValue, FileValue} {
+		if value, ok := strings.CutPrefix(input, kind.String()+":"); ok {
+			return kind, strings.TrimSpace(value)
+		}
+	}
+	return LiteralValue, input
+}
+
+// FormatValue is the inverse of ParseValue.
+func FormatValue(kind ValueKind, value string) string {
+	switch kind {
+	case CommandValue, FileValue:
+		return kind.String() + ":" + value
+	default:
+		return value
+	}
+}
+
 type Detail struct {
 	ID         int
 	Key        string
 	Value      string
 	DetailType DetailType
 	ProfileID  int
+	// Secret values are stored encrypted. Value holds the ciphertext until revealed
+	Secret bool
+	Kind   ValueKind
+	// Position and Separator only apply to path details, where Key is the list
+	// variable (e.g. PATH) and Value is the entry to add.
+	Position  PathPosition
+	Separator string
+	// Guard skips a source detail when its file does not exist
+	Guard bool
+	// Shells limits a raw detail to the listed shells. Empty means every shell.
+	Shells []string
+	// Description is an optional note on why the detail is set
+	Description string
+	// DeletedAt is set while the detail is in the trash
+	DeletedAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValue(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+		kind  ValueKind
+		value string
+	}{
+		{
+			name:  "plain input is literal",
+			input: "some value",
+			kind:  LiteralValue,
+			value: "some value",
+		},
+		{
+			name:  "cmd prefix is parsed",
+			input: "cmd: gcloud auth print-access-token",
+			kind:  CommandValue,
+			value: "gcloud auth print-access-token",
+		},
+		{
+			name:  "file prefix is parsed",
+			input: "file:~/.tokens/github",
+			kind:  FileValue,
+			value: "~/.tokens/github",
+		},
+		{
+			name:  "prefix in the middle is literal",
+			input: "echo cmd:foo",
+			kind:  LiteralValue,
+			value: "echo cmd:foo",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			kind, value := ParseValue(testcase.input)
+			assert.Equal(t, testcase.kind, kind)
+			assert.Equal(t, testcase.value, value)
+			if kind != LiteralValue {
+				assert.Equal(t, testcase.kind.String()+":"+testcase.value, FormatValue(kind, value))
+			}
+		})
+	}
+}
@@ -2,39 +2,95 @@ package data
 
 import (
 	"database/sql"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrProfileExists   = errors.New("profile already exists")
+	ErrProfileNotFound = errors.New("profile not found")
 )
 
 type MaggiRepository struct {
-	db *sql.DB
+	db            *sql.DB
+	loadKey       func() (SecretKey, error)
+	secretBoxes   map[string]*secretBox
+	now           func() time.Time
+	journalWindow time.Duration
+	source        Source
 }
 
 func NewMaggiRepository(db *sql.DB) *MaggiRepository {
-	return &MaggiRepository{db: db}
+	return &MaggiRepository{db: db, loadKey: LoadSecretKey, now: time.Now, journalWindow: DefaultJournalWindow, source: CLISource}
 }
 
-func (mr *MaggiRepository) GetDetailsByProfileName(profileName string) ([]Detail, error) {
-	stmt := "select details.id, details.key, details.value, details.type, details.profile_id from details join profiles where details.profile_id = profiles.id and profiles.name = ?;"
-	rows, err := mr.db.Query(stmt, profileName)
+// timestamp is the current time as stored, to the second
+func (mr *MaggiRepository) timestamp() time.Time {
+	return time.Unix(mr.now().Unix(), 0)
+}
+
+// detailColumns are the columns of details in the order scanDetail reads them and
+// insertDetail writes them
+const detailColumns = "id, key, value, type, profile_id, secret, kind, position, separator, guard, shells, deleted_at, created_at, updated_at, description"
+
+// scanDetail reads a row selected with detailColumns
+func scanDetail(row interface{ Scan(dest ...any) error }) (Detail, error) {
+	detail := Detail{}
+	var typeStr, kindStr, positionStr, shellsStr string
+	var deletedAt, createdAt, updatedAt sql.NullInt64
+	err := row.Scan(&detail.ID, &detail.Key, &detail.Value, &typeStr, &detail.ProfileID, &detail.Secret, &kindStr, &positionStr, &detail.Separator, &detail.Guard, &shellsStr, &deletedAt, &createdAt, &updatedAt, &detail.Description)
 	if err != nil {
-		return nil, err
+		return Detail{}, err
 	}
-	defer rows.Close()
+	detail.DeletedAt = unixTime(deletedAt)
+	detail.CreatedAt = unixTime(createdAt)
+	detail.UpdatedAt = unixTime(updatedAt)
+	switch kindStr {
+	case "cmd":
+		detail.Kind = CommandValue
+	case "file":
+		detail.Kind = FileValue
+	default:
+		detail.Kind = LiteralValue
+	}
+	switch typeStr {
+	case "alias":
+		detail.DetailType = AliasDetail
+	case "env":
+		detail.DetailType = EnvDetail
+	case "path":
+		detail.DetailType = PathDetail
+	case "function":
+		detail.DetailType = FunctionDetail
+	case "source":
+		detail.DetailType = SourceDetail
+	case "raw":
+		detail.DetailType = RawDetail
+	}
+	if shellsStr != "" {
+		detail.Shells = strings.Split(shellsStr, ",")
+	}
+	switch positionStr {
+	case "append":
+		detail.Position = AppendPath
+	default:
+		detail.Position = PrependPath
+	}
+	return detail, nil
+}
+
+func scanDetails(rows *sql.Rows) ([]Detail, error) {
 	details := []Detail{}
 	for rows.Next() {
-		detail := &Detail{}
-		var typeStr string
-		err = rows.Scan(&detail.ID, &detail.Key, &detail.Value, &typeStr, &detail.ProfileID)
+		detail, err := scanDetail(rows)
 		if err != nil {
 			return nil, err
 		}
-		switch typeStr {
-		case "alias":
-			detail.DetailType = AliasDetail
-		case "env":
-			detail.DetailType = EnvDetail
-		}
-		details = append(details, *detail)
+		details = append(details, detail)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -42,70 +98,270 @@ func (mr *MaggiRepository) GetDetailsByProfileName(profileName string) ([]Detail
 	return details, nil
 }
 
-func (mr *MaggiRepository) GetAllDetails(profileId int) ([]Detail, error) {
-	stmt := "SELECT id, key, value, type, profile_id FROM details WHERE profile_id = ?;"
-	rows, err := mr.db.Query(stmt, profileId)
+// insertDetail writes all columns of the detail and returns its id. a detail without
+// an id gets a new one.
+func insertDetail(tx *sql.Tx, detail Detail) (int, error) {
+	var id any
+	if detail.ID != 0 {
+		id = detail.ID
+	}
+	stmt := "INSERT INTO details (" + detailColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);"
+	res, err := tx.Exec(stmt, id, detail.Key, detail.Value, detail.DetailType.String(), detail.ProfileID, detail.Secret, detail.Kind.String(), detail.Position.String(), detail.Separator, detail.Guard, strings.Join(detail.Shells, ","), nullUnix(detail.DeletedAt), nullUnix(detail.CreatedAt), nullUnix(detail.UpdatedAt), detail.Description)
+	if err != nil {
+		return 0, err
+	}
+	inserted, err := res.LastInsertId()
+	return int(inserted), err
+}
+
+func setDetailDefaults(detail *Detail) {
+	if detail.Kind == "" {
+		detail.Kind = LiteralValue
+	}
+	if detail.Position == "" {
+		detail.Position = PrependPath
+	}
+	if detail.Separator == "" {
+		detail.Separator = DefaultPathSeparator
+	}
+}
+
+// the key is only loaded when a secret is first touched, so profiles without
+// secrets keep working when no passphrase or key file is set up. prefix picks the
+// format: new values use the salt stored with the database, older ones the fixed salt.
+func (mr *MaggiRepository) getSecretBox(prefix string) (*secretBox, error) {
+	if box, ok := mr.secretBoxes[prefix]; ok {
+		return box, nil
+	}
+	key, err := mr.loadKey()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	details := []Detail{}
-	for rows.Next() {
-		detail := &Detail{}
-		var typeStr string
-		err = rows.Scan(&detail.ID, &detail.Key, &detail.Value, &typeStr, &detail.ProfileID)
-		if err != nil {
+	salt := []byte(legacyPassphraseSalt)
+	if prefix == secretPrefix {
+		if salt, err = mr.secretSalt(); err != nil {
 			return nil, err
 		}
-		switch typeStr {
-		case "alias":
-			detail.DetailType = AliasDetail
-		case "env":
-			detail.DetailType = EnvDetail
-		}
-		details = append(details, *detail)
 	}
-	if err := rows.Err(); err != nil {
+	box, err := newSecretBox(key.derive(salt), prefix)
+	if err != nil {
 		return nil, err
 	}
+	if mr.secretBoxes == nil {
+		mr.secretBoxes = make(map[string]*secretBox)
+	}
+	mr.secretBoxes[prefix] = box
+	return box, nil
+}
 
-	return details, nil
+// secretSalt returns the salt stored with the database when it was set up
+func (mr *MaggiRepository) secretSalt() ([]byte, error) {
+	var stored string
+	err := mr.db.QueryRow("SELECT salt FROM secret_salt WHERE id = 1;").Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoSecretSalt
+	}
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(stored)
+	if err != nil || len(salt) == 0 {
+		return nil, ErrNoSecretSalt
+	}
+	return salt, nil
+}
+
+func (mr *MaggiRepository) sealValue(value string, secret bool) (string, error) {
+	if !secret {
+		return value, nil
+	}
+	box, err := mr.getSecretBox(secretPrefix)
+	if err != nil {
+		return "", err
+	}
+	return box.seal(value)
+}
+
+// RevealValue returns the plaintext value of a detail. Non secret details are
+// returned as is.
+func (mr *MaggiRepository) RevealValue(detail Detail) (string, error) {
+	if !detail.Secret {
+		return detail.Value, nil
+	}
+	box, err := mr.getSecretBox(sealedVersion(detail.Value))
+	if err != nil {
+		return "", err
+	}
+	return box.open(detail.Value)
 }
 
-func (mr *MaggiRepository) AddDetail(key string, value string, detailType DetailType, profileID int) (*Detail, error) {
-	var detail Detail
-	stmt := "INSERT INTO details (key, value, type, profile_id) VALUES (?, ?, ?, ?);"
-	res, err := mr.db.Exec(stmt, key, value, detailType.String(), profileID)
+func (mr *MaggiRepository) GetDetailsByProfileName(profileName string) ([]Detail, error) {
+	stmt := "select details.id, details.key, details.value, details.type, details.profile_id, details.secret, details.kind, details.position, details.separator, details.guard, details.shells, details.deleted_at, details.created_at, details.updated_at, details.description from details join profiles where details.profile_id = profiles.id and profiles.name = ? and profiles.deleted_at is null and details.deleted_at is null;"
+	rows, err := mr.db.Query(stmt, profileName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDetails(rows)
+}
+
+func (mr *MaggiRepository) GetAllDetails(profileId int) ([]Detail, error) {
+	stmt := "SELECT " + detailColumns + " FROM details WHERE profile_id = ? AND deleted_at IS NULL;"
+	rows, err := mr.db.Query(stmt, profileId)
 	if err != nil {
 		return nil, err
 	}
-	var id int64
-	if id, err = res.LastInsertId(); err != nil {
+	defer rows.Close()
+	return scanDetails(rows)
+}
+
+// AddDetail stores a new detail. The value is expected in plaintext and is
+// encrypted before writing if the detail is marked secret. Details that fail
+// validation are not stored.
+func (mr *MaggiRepository) AddDetail(detail Detail) (*Detail, error) {
+	if err := CheckDetail(detail); err != nil {
+		return nil, err
+	}
+	value, err := mr.sealValue(detail.Value, detail.Secret)
+	if err != nil {
+		return nil, err
+	}
+	setDetailDefaults(&detail)
+	detail.Value = value
+	detail.CreatedAt = mr.timestamp()
+	detail.UpdatedAt = detail.CreatedAt
+	err = mr.mutate("add "+describeDetail(detail), func(tx *sql.Tx, entry *journalEntry) error {
+		id, err := insertDetail(tx, detail)
+		if err != nil {
+			return err
+		}
+		detail.ID = id
+		added := detail
+		entry.detail(nil, &added)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	detail = Detail{ID: int(id), Key: key, Value: value, DetailType: detailType, ProfileID: profileID}
 	return &detail, nil
 }
 
+// UpdateDetail sets a new key and plaintext value on the detail. The secret flag,
+// value kind and description are taken from the passed detail, so toggling secret
+// re-encrypts or decrypts the value.
 func (mr *MaggiRepository) UpdateDetail(detail Detail, key string, value string) (*Detail, error) {
-	stmt := "UPDATE details SET key = ?, value = ? WHERE id = ?;"
-	_, err := mr.db.Exec(stmt, key, value, detail.ID)
+	checked := detail
+	checked.Key = key
+	checked.Value = value
+	if err := CheckDetail(checked); err != nil {
+		return nil, err
+	}
+	value, err := mr.sealValue(value, detail.Secret)
+	if err != nil {
+		return nil, err
+	}
+	setDetailDefaults(&detail)
+	updatedAt := mr.timestamp()
+	err = mr.mutate("update "+describeDetail(detail), func(tx *sql.Tx, entry *journalEntry) error {
+		before, err := getDetail(tx, detail.ID)
+		if err != nil {
+			return err
+		}
+		stmt := "UPDATE details SET key = ?, value = ?, secret = ?, kind = ?, position = ?, separator = ?, guard = ?, shells = ?, description = ?, updated_at = ? WHERE id = ?;"
+		_, err = tx.Exec(stmt, key, value, detail.Secret, detail.Kind.String(), detail.Position.String(), detail.Separator, detail.Guard, strings.Join(detail.Shells, ","), detail.Description, updatedAt.Unix(), detail.ID)
+		if err != nil {
+			return err
+		}
+		after, err := getDetail(tx, detail.ID)
+		if err != nil {
+			return err
+		}
+		entry.detail(&before, &after)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	detail.Key = key
 	detail.Value = value
+	detail.UpdatedAt = updatedAt
 	return &detail, nil
 }
 
+// DeleteDetail moves the detail to the trash, where it stays until restored or purged.
 func (mr *MaggiRepository) DeleteDetail(detail Detail) error {
-	stmt := "DELETE FROM details WHERE id = ?;"
-	_, err := mr.db.Exec(stmt, detail.ID)
-	return err
+	return mr.mutate("delete "+describeDetail(detail), func(tx *sql.Tx, entry *journalEntry) error {
+		return trashDetail(tx, entry, detail, mr.now())
+	})
+}
+
+// DeleteDetails moves every passed detail to the trash in one transaction. Either all
+// of them are deleted or none.
+func (mr *MaggiRepository) DeleteDetails(details []Detail) error {
+	deletedAt := mr.now()
+	return mr.mutate("delete "+describeDetails(details), func(tx *sql.Tx, entry *journalEntry) error {
+		for _, detail := range details {
+			if err := trashDetail(tx, entry, detail, deletedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getDetail reads the detail as currently stored, for the journal
+func getDetail(tx *sql.Tx, id int) (Detail, error) {
+	detail, err := scanDetail(tx.QueryRow("SELECT "+detailColumns+" FROM details WHERE id = ?;", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Detail{}, fmt.Errorf("detail %d: %w", id, sql.ErrNoRows)
+	}
+	return detail, err
+}
+
+func trashDetail(tx *sql.Tx, entry *journalEntry, detail Detail, deletedAt time.Time) error {
+	before, err := getDetail(tx, detail.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE details SET deleted_at = ? WHERE id = ?;", deletedAt.Unix(), detail.ID); err != nil {
+		return err
+	}
+	after := before
+	after.DeletedAt = time.Unix(deletedAt.Unix(), 0)
+	entry.detail(&before, &after)
+	return nil
+}
+
+// removeDetail deletes the row for good, for details that live on elsewhere
+func removeDetail(tx *sql.Tx, entry *journalEntry, detail Detail) error {
+	before, err := getDetail(tx, detail.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM details WHERE id = ?;", detail.ID); err != nil {
+		return err
+	}
+	entry.detail(&before, nil)
+	return nil
+}
+
+// describeDetail names a detail in journal labels, e.g. "env EDITOR"
+func describeDetail(detail Detail) string {
+	return fmt.Sprintf("%s %s", detail.DetailType, detail.Key)
 }
 
+func describeDetails(details []Detail) string {
+	if len(details) == 1 {
+		return describeDetail(details[0])
+	}
+	return fmt.Sprintf("%d details", len(details))
+}
+
+// GetAllProfiles lists the live profiles. UpdatedAt is the latest change to the
+// profile or any of its details, including details moved to the trash.
 func (mr *MaggiRepository) GetAllProfiles() ([]Profile, error) {
-	stmt := "SELECT id, name from profiles"
+	stmt := `SELECT id, name, description, created_at, MAX(COALESCE(updated_at, 0), COALESCE((SELECT MAX(MAX(COALESCE(updated_at, 0), COALESCE(deleted_at, 0))) FROM details WHERE profile_id = profiles.id), 0)), last_applied_at
+    FROM profiles WHERE deleted_at IS NULL`
 	rows, err := mr.db.Query(stmt)
 	if err != nil {
 		return nil, err
@@ -114,72 +370,393 @@ func (mr *MaggiRepository) GetAllProfiles() ([]Profile, error) {
 	profiles := []Profile{}
 	for rows.Next() {
 		profile := &Profile{}
-		err = rows.Scan(&profile.ID, &profile.Name)
+		var createdAt, lastAppliedAt sql.NullInt64
+		var updatedAt int64
+		err = rows.Scan(&profile.ID, &profile.Name, &profile.Description, &createdAt, &updatedAt, &lastAppliedAt)
 		if err != nil {
 			return nil, err
 		}
+		profile.CreatedAt = unixTime(createdAt)
+		profile.UpdatedAt = unixTime(sql.NullInt64{Int64: updatedAt, Valid: updatedAt > 0})
+		profile.LastAppliedAt = unixTime(lastAppliedAt)
 		profiles = append(profiles, *profile)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	tags, err := getTags(mr.db, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		profiles[i].Tags = tags[profiles[i].ID]
+	}
 	return profiles, nil
 }
 
+// GetProfileByName returns the live profile with the name, as stored
+func (mr *MaggiRepository) GetProfileByName(name string) (Profile, error) {
+	profile := Profile{Name: name}
+	var createdAt, updatedAt, lastAppliedAt sql.NullInt64
+	err := mr.db.QueryRow("SELECT id, description, created_at, updated_at, last_applied_at FROM profiles WHERE name = ? AND deleted_at IS NULL;", name).Scan(&profile.ID, &profile.Description, &createdAt, &updatedAt, &lastAppliedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+	profile.CreatedAt = unixTime(createdAt)
+	profile.UpdatedAt = unixTime(updatedAt)
+	profile.LastAppliedAt = unixTime(lastAppliedAt)
+	tags, err := getTags(mr.db, profile.ID)
+	if err != nil {
+		return Profile{}, err
+	}
+	profile.Tags = tags[profile.ID]
+	return profile, nil
+}
+
 func (mr *MaggiRepository) AddProfile(name string) (Profile, error) {
 	var profile Profile
-	stmt := "INSERT INTO profiles (name) VALUES (?);"
-	res, err := mr.db.Exec(stmt, name)
+	createdAt := mr.timestamp()
+	err := mr.mutate("add profile "+name, func(tx *sql.Tx, entry *journalEntry) error {
+		res, err := tx.Exec("INSERT INTO profiles (name, created_at, updated_at) VALUES (?, ?, ?);", name, createdAt.Unix(), createdAt.Unix())
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		profile = Profile{ID: int(id), Name: name, CreatedAt: createdAt, UpdatedAt: createdAt}
+		added := profile
+		entry.profile(nil, &added)
+		return nil
+	})
 	if err != nil {
-		return profile, err
-	}
-	var id int64
-	if id, err = res.LastInsertId(); err != nil {
-		return profile, err
+		return Profile{}, err
 	}
-	return Profile{ID: int(id), Name: name}, nil
+	return profile, nil
 }
 
+// UpdateProfile renames the profile. The description is taken from the passed profile,
+// so passing the current name only updates the description.
 func (mr *MaggiRepository) UpdateProfile(profile Profile, newName string) (Profile, error) {
-	stmt := "UPDATE profiles SET name = ? WHERE id = ?;"
-	_, err := mr.db.Exec(stmt, newName, profile.ID)
+	updatedAt := mr.timestamp()
+	label := fmt.Sprintf("rename profile %s to %s", profile.Name, newName)
+	if newName == profile.Name {
+		label = "update profile " + profile.Name
+	}
+	err := mr.mutate(label, func(tx *sql.Tx, entry *journalEntry) error {
+		before, err := getProfile(tx, profile.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE profiles SET name = ?, description = ?, updated_at = ? WHERE id = ?;", newName, profile.Description, updatedAt.Unix(), profile.ID); err != nil {
+			return err
+		}
+		after := before
+		after.Name = newName
+		after.Description = profile.Description
+		after.UpdatedAt = updatedAt
+		entry.profile(&before, &after)
+		return nil
+	})
 	if err != nil {
 		return profile, err
 	}
 	profile.Name = newName
+	profile.UpdatedAt = updatedAt
 	return profile, nil
 }
 
+// MarkApplied records the profiles as applied now, e.g. by generate. Names without a
+// live profile are ignored.
+func (mr *MaggiRepository) MarkApplied(profileNames ...string) error {
+	appliedAt := mr.timestamp().Unix()
+	for _, name := range profileNames {
+		if _, err := mr.db.Exec("UPDATE profiles SET last_applied_at = ? WHERE name = ? AND deleted_at IS NULL;", appliedAt, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteProfile moves the profile and its details to the trash.
 func (mr *MaggiRepository) DeleteProfile(profile Profile) error {
-	tx, err := mr.db.Begin()
-	if err != nil {
-		rollbackErr := tx.Rollback()
-		if rollbackErr != nil {
-			return errors.Join(err, rollbackErr)
+	return mr.mutate("delete profile "+profile.Name, func(tx *sql.Tx, entry *journalEntry) error {
+		return trashProfile(tx, entry, profile, mr.now())
+	})
+}
+
+// DeleteProfiles moves the passed profiles and their details to the trash in one
+// transaction.
+func (mr *MaggiRepository) DeleteProfiles(profiles []Profile) error {
+	deletedAt := mr.now()
+	label := fmt.Sprintf("delete %d profiles", len(profiles))
+	if len(profiles) == 1 {
+		label = "delete profile " + profiles[0].Name
+	}
+	return mr.mutate(label, func(tx *sql.Tx, entry *journalEntry) error {
+		for _, profile := range profiles {
+			if err := trashProfile(tx, entry, profile, deletedAt); err != nil {
+				return err
+			}
 		}
-		return err
+		return nil
+	})
+}
+
+// getProfile reads the profile row as stored, so UpdatedAt leaves out its details
+func getProfile(tx *sql.Tx, id int) (Profile, error) {
+	profile := Profile{ID: id}
+	var deletedAt, createdAt, updatedAt, lastAppliedAt sql.NullInt64
+	if err := tx.QueryRow("SELECT name, description, deleted_at, created_at, updated_at, last_applied_at FROM profiles WHERE id = ?;", id).Scan(&profile.Name, &profile.Description, &deletedAt, &createdAt, &updatedAt, &lastAppliedAt); err != nil {
+		return profile, fmt.Errorf("profile %d: %w", id, err)
+	}
+	profile.DeletedAt = unixTime(deletedAt)
+	profile.CreatedAt = unixTime(createdAt)
+	profile.UpdatedAt = unixTime(updatedAt)
+	profile.LastAppliedAt = unixTime(lastAppliedAt)
+	tags, err := getTags(tx, id)
+	if err != nil {
+		return profile, err
 	}
+	profile.Tags = tags[id]
+	return profile, nil
+}
 
-	stmt := "DELETE FROM details WHERE profile_id = ?;"
-	_, err = tx.Exec(stmt, profile.ID)
+// trashProfile deletes the live details of the profile with the same timestamp as the
+// profile, which is how restoring the profile finds them again.
+func trashProfile(tx *sql.Tx, entry *journalEntry, profile Profile, deletedAt time.Time) error {
+	before, err := getProfile(tx, profile.ID)
 	if err != nil {
-		rollbackErr := tx.Rollback()
-		if rollbackErr != nil {
-			return errors.Join(err, rollbackErr)
+		return err
+	}
+	rows, err := tx.Query("SELECT "+detailColumns+" FROM details WHERE profile_id = ? AND deleted_at IS NULL;", profile.ID)
+	if err != nil {
+		return err
+	}
+	details, err := scanDetails(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	for _, detail := range details {
+		if err := trashDetail(tx, entry, detail, deletedAt); err != nil {
+			return err
 		}
+	}
+	if _, err := tx.Exec("UPDATE profiles SET deleted_at = ? WHERE id = ?;", deletedAt.Unix(), profile.ID); err != nil {
 		return err
 	}
+	after := before
+	after.DeletedAt = time.Unix(deletedAt.Unix(), 0)
+	entry.profile(&before, &after)
+	return nil
+}
+
+// CloneProfile creates a profile named newName with a copy of every detail and tag of
+// src. Secret values are copied sealed. Nothing is written if any step fails.
+func (mr *MaggiRepository) CloneProfile(src Profile, newName string) (Profile, error) {
+	var clone Profile
+	createdAt := mr.timestamp()
+	err := mr.mutate(fmt.Sprintf("clone profile %s to %s", src.Name, newName), func(tx *sql.Tx, entry *journalEntry) error {
+		var taken int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM profiles WHERE name = ? AND deleted_at IS NULL;", newName).Scan(&taken); err != nil {
+			return err
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: %s", ErrProfileExists, newName)
+		}
 
-	stmt = "DELETE FROM profiles WHERE id = ?;"
-	_, err = tx.Exec(stmt, profile.ID)
+		res, err := tx.Exec("INSERT INTO profiles (name, created_at, updated_at, description) VALUES (?, ?, ?, ?);", newName, createdAt.Unix(), createdAt.Unix(), src.Description)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		tags, err := getTags(tx, src.ID)
+		if err != nil {
+			return err
+		}
+		if err := writeTags(tx, int(id), tags[src.ID]); err != nil {
+			return err
+		}
+		clone = Profile{ID: int(id), Name: newName, Description: src.Description, Tags: tags[src.ID], CreatedAt: createdAt, UpdatedAt: createdAt}
+		added := clone
+		entry.profile(nil, &added)
 
-	err = tx.Commit()
+		rows, err := tx.Query("SELECT "+detailColumns+" FROM details WHERE profile_id = ? AND deleted_at IS NULL ORDER BY id;", src.ID)
+		if err != nil {
+			return err
+		}
+		details, err := scanDetails(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		for _, detail := range details {
+			detail.ID = 0
+			detail.ProfileID = clone.ID
+			detail.CreatedAt = createdAt
+			detail.UpdatedAt = createdAt
+			if detail.ID, err = insertDetail(tx, detail); err != nil {
+				return err
+			}
+			added := detail
+			entry.detail(nil, &added)
+		}
+		return nil
+	})
 	if err != nil {
-		rollbackErr := tx.Rollback()
-		if rollbackErr != nil {
-			return errors.Join(err, rollbackErr)
+		return Profile{}, err
+	}
+	return clone, nil
+}
+
+// ConflictPolicy decides what CopyDetails and MoveDetails do with a detail whose key
+// is already taken in the target profile.
+type ConflictPolicy string
+
+func (c ConflictPolicy) String() string {
+	return string(c)
+}
+
+const (
+	SkipConflicts      ConflictPolicy = "skip"
+	OverwriteConflicts ConflictPolicy = "overwrite"
+	RenameConflicts    ConflictPolicy = "rename"
+)
+
+// TransferResult is what CopyDetails or MoveDetails did with the passed details.
+type TransferResult struct {
+	// Transferred holds the details as stored in the target profile
+	Transferred []Detail
+	// Skipped details are left where they were, also when moving
+	Skipped     []Detail
+	Overwritten int
+	Renamed     int
+}
+
+// FindConflict returns the detail in existing that detail would clash with. Keys are
+// unique within a profile across types, except for path details where only the same
+// entry for the same list clashes.
+func FindConflict(existing []Detail, detail Detail) (Detail, bool) {
+	for _, other := range existing {
+		if other.Key != detail.Key {
+			continue
+		}
+		if detail.DetailType == PathDetail && other.DetailType == PathDetail {
+			if other.Value == detail.Value {
+				return other, true
+			}
+			continue
+		}
+		if detail.DetailType != PathDetail && other.DetailType != PathDetail {
+			return other, true
 		}
-		return err
 	}
-	return nil
+	return Detail{}, false
+}
+
+// freeKey appends _copy, then _copy2 and so on, until the key is not taken. the suffix
+// keeps env, alias and function names valid.
+func freeKey(existing []Detail, detail Detail) string {
+	for i := 1; ; i++ {
+		candidate := detail.Key + "_copy"
+		if i > 1 {
+			candidate = fmt.Sprintf("%s%d", candidate, i)
+		}
+		renamed := detail
+		renamed.Key = candidate
+		if _, taken := FindConflict(existing, renamed); !taken {
+			return candidate
+		}
+	}
+}
+
+// CopyDetails copies details into target, handling keys already taken there with
+// policy. Secret values are copied sealed. Nothing is written if any step fails.
+func (mr *MaggiRepository) CopyDetails(details []Detail, target Profile, policy ConflictPolicy) (TransferResult, error) {
+	return mr.transferDetails(details, target, policy, false)
+}
+
+// MoveDetails works like CopyDetails and removes the transferred details from their
+// profile in the same transaction, so a move either fully happens or not at all.
+func (mr *MaggiRepository) MoveDetails(details []Detail, target Profile, policy ConflictPolicy) (TransferResult, error) {
+	return mr.transferDetails(details, target, policy, true)
+}
+
+func (mr *MaggiRepository) transferDetails(details []Detail, target Profile, policy ConflictPolicy, move bool) (TransferResult, error) {
+	var result TransferResult
+	verb := "copy"
+	if move {
+		verb = "move"
+	}
+	label := fmt.Sprintf("%s %s to %s", verb, describeDetails(details), target.Name)
+	createdAt := mr.timestamp()
+	err := mr.mutate(label, func(tx *sql.Tx, entry *journalEntry) error {
+		rows, err := tx.Query("SELECT "+detailColumns+" FROM details WHERE profile_id = ? AND deleted_at IS NULL;", target.ID)
+		if err != nil {
+			return err
+		}
+		existing, err := scanDetails(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, detail := range details {
+			if detail.ProfileID == target.ID {
+				return fmt.Errorf("%s is already in profile %s", detail.Key, target.Name)
+			}
+			if conflict, ok := FindConflict(existing, detail); ok {
+				switch {
+				case policy == OverwriteConflicts:
+					if err := trashDetail(tx, entry, conflict, mr.now()); err != nil {
+						return err
+					}
+					existing = slices.DeleteFunc(existing, func(other Detail) bool { return other.ID == conflict.ID })
+					result.Overwritten++
+				case policy == RenameConflicts && detail.DetailType != PathDetail:
+					detail.Key = freeKey(existing, detail)
+					result.Renamed++
+				default:
+					// a path entry already in the list has nothing to rename
+					result.Skipped = append(result.Skipped, detail)
+					continue
+				}
+			}
+
+			setDetailDefaults(&detail)
+			transferred := detail
+			transferred.ID = 0
+			transferred.ProfileID = target.ID
+			transferred.DeletedAt = time.Time{}
+			transferred.CreatedAt = createdAt
+			transferred.UpdatedAt = createdAt
+			id, err := insertDetail(tx, transferred)
+			if err != nil {
+				return err
+			}
+			if move {
+				if err := removeDetail(tx, entry, detail); err != nil {
+					return err
+				}
+			}
+			transferred.ID = id
+			added := transferred
+			entry.detail(nil, &added)
+			existing = append(existing, transferred)
+			result.Transferred = append(result.Transferred, transferred)
+		}
+		return nil
+	})
+	if err != nil {
+		return TransferResult{}, err
+	}
+	return result, nil
 }
@@ -0,0 +1,275 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepository sets up a fresh database under a temporary home directory
+func newTestRepository(t *testing.T) *MaggiRepository {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MAGGI_PASSPHRASE", "test passphrase")
+	db, err := Setup()
+	require.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+	return NewMaggiRepository(db)
+}
+
+func TestCloneProfile(t *testing.T) {
+	repository := newTestRepository(t)
+	// the copies are stamped with the time of the clone
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+	src, err := repository.AddProfile("prod-us")
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "REGION", Value: "us-east-1", DetailType: EnvDetail, ProfileID: src.ID})
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: src.ID, Secret: true})
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "PATH", Value: "/opt/bin", DetailType: PathDetail, ProfileID: src.ID, Position: AppendPath, Separator: ":"})
+	require.Nil(t, err)
+
+	clone, err := repository.CloneProfile(src, "prod-eu")
+	require.Nil(t, err)
+	assert.Equal(t, "prod-eu", clone.Name)
+	assert.NotEqual(t, src.ID, clone.ID)
+
+	original, err := repository.GetAllDetails(src.ID)
+	require.Nil(t, err)
+	cloned, err := repository.GetAllDetails(clone.ID)
+	require.Nil(t, err)
+	require.Len(t, cloned, len(original))
+	for i := range original {
+		assert.NotEqual(t, original[i].ID, cloned[i].ID)
+		assert.Equal(t, clone.ID, cloned[i].ProfileID)
+		original[i].ID, original[i].ProfileID = cloned[i].ID, cloned[i].ProfileID
+		assert.Equal(t, original[i], cloned[i])
+	}
+	token, err := repository.RevealValue(cloned[1])
+	require.Nil(t, err)
+	assert.Equal(t, "abc", token)
+
+	_, err = repository.CloneProfile(src, "prod-eu")
+	assert.ErrorIs(t, err, ErrProfileExists)
+	profiles, err := repository.GetAllProfiles()
+	require.Nil(t, err)
+	assert.Len(t, profiles, 2)
+}
+
+func TestTransferDetails(t *testing.T) {
+	setup := func(t *testing.T) (*MaggiRepository, Profile, Profile, []Detail) {
+		repository := newTestRepository(t)
+		src, err := repository.AddProfile("work")
+		require.Nil(t, err)
+		target, err := repository.AddProfile("home")
+		require.Nil(t, err)
+		for _, detail := range []Detail{
+			{Key: "EDITOR", Value: "vim", DetailType: EnvDetail, ProfileID: src.ID},
+			{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: src.ID, Secret: true},
+			{Key: "PATH", Value: "/opt/bin", DetailType: PathDetail, ProfileID: src.ID},
+			{Key: "EDITOR", Value: "nano", DetailType: EnvDetail, ProfileID: target.ID},
+			{Key: "PATH", Value: "/opt/bin", DetailType: PathDetail, ProfileID: target.ID},
+		} {
+			_, err := repository.AddDetail(detail)
+			require.Nil(t, err)
+		}
+		details, err := repository.GetAllDetails(src.ID)
+		require.Nil(t, err)
+		return repository, src, target, details
+	}
+	keys := func(details []Detail) []string {
+		var res []string
+		for _, detail := range details {
+			res = append(res, detail.Key+"="+detail.Value)
+		}
+		return res
+	}
+
+	t.Run("copy skips conflicts", func(t *testing.T) {
+		repository, src, target, details := setup(t)
+		result, err := repository.CopyDetails(details, target, SkipConflicts)
+		require.Nil(t, err)
+		assert.Len(t, result.Transferred, 1)
+		assert.Len(t, result.Skipped, 2)
+		stored, err := repository.GetAllDetails(target.ID)
+		require.Nil(t, err)
+		require.Len(t, stored, 3)
+		assert.Equal(t, "EDITOR=nano", keys(stored)[0])
+		token, err := repository.RevealValue(stored[2])
+		require.Nil(t, err)
+		assert.Equal(t, "abc", token)
+		left, err := repository.GetAllDetails(src.ID)
+		require.Nil(t, err)
+		assert.Len(t, left, 3)
+	})
+
+	t.Run("copy renames conflicts", func(t *testing.T) {
+		repository, _, target, details := setup(t)
+		_, err := repository.AddDetail(Detail{Key: "EDITOR_copy", Value: "ed", DetailType: EnvDetail, ProfileID: target.ID})
+		require.Nil(t, err)
+		result, err := repository.CopyDetails(details[:1], target, RenameConflicts)
+		require.Nil(t, err)
+		assert.Equal(t, 1, result.Renamed)
+		assert.Equal(t, "EDITOR_copy2", result.Transferred[0].Key)
+	})
+
+	t.Run("move overwrites conflicts", func(t *testing.T) {
+		repository, src, target, details := setup(t)
+		result, err := repository.MoveDetails(details, target, OverwriteConflicts)
+		require.Nil(t, err)
+		assert.Equal(t, 2, result.Overwritten)
+		stored, err := repository.GetAllDetails(target.ID)
+		require.Nil(t, err)
+		require.Len(t, stored, 3)
+		assert.Equal(t, "EDITOR=vim", keys(stored)[0])
+		assert.Equal(t, "PATH=/opt/bin", keys(stored)[2])
+		left, err := repository.GetAllDetails(src.ID)
+		require.Nil(t, err)
+		assert.Empty(t, left)
+	})
+
+	t.Run("failed move changes nothing", func(t *testing.T) {
+		repository, src, target, details := setup(t)
+		// the last detail already belongs to the target, which fails the move
+		ownDetails, err := repository.GetAllDetails(target.ID)
+		require.Nil(t, err)
+		_, err = repository.MoveDetails(append(details, ownDetails[0]), target, OverwriteConflicts)
+		assert.NotNil(t, err)
+		left, err := repository.GetAllDetails(src.ID)
+		require.Nil(t, err)
+		assert.Equal(t, details, left)
+		stored, err := repository.GetAllDetails(target.ID)
+		require.Nil(t, err)
+		assert.Equal(t, ownDetails, stored)
+	})
+}
+
+func TestDeleteInBulk(t *testing.T) {
+	repository := newTestRepository(t)
+	// the listed profiles carry the time of their last change
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	home, err := repository.AddProfile("home")
+	require.Nil(t, err)
+	scratch, err := repository.AddProfile("scratch")
+	require.Nil(t, err)
+	for _, profile := range []Profile{work, home, scratch} {
+		for _, key := range []string{"EDITOR", "PAGER", "LANG"} {
+			_, err := repository.AddDetail(Detail{Key: key, Value: "x", DetailType: EnvDetail, ProfileID: profile.ID})
+			require.Nil(t, err)
+		}
+	}
+
+	details, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	require.Nil(t, repository.DeleteDetails(details[:2]))
+	left, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, details[2:], left)
+
+	require.Nil(t, repository.DeleteProfiles([]Profile{home, scratch}))
+	profiles, err := repository.GetAllProfiles()
+	require.Nil(t, err)
+	assert.Equal(t, []Profile{work}, profiles)
+	for _, profile := range []Profile{home, scratch} {
+		details, err := repository.GetAllDetails(profile.ID)
+		require.Nil(t, err)
+		assert.Empty(t, details)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	repository := newTestRepository(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+	at := func(t time.Time) time.Time { return time.Unix(t.Unix(), 0) }
+
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	home, err := repository.AddProfile("home")
+	require.Nil(t, err)
+	created := now
+	now = now.Add(time.Hour)
+	editor, err := repository.AddDetail(Detail{Key: "EDITOR", Value: "vim", DetailType: EnvDetail, ProfileID: work.ID})
+	require.Nil(t, err)
+	now = now.Add(time.Hour)
+	editor, err = repository.UpdateDetail(*editor, "EDITOR", "nvim")
+	require.Nil(t, err)
+	require.Nil(t, repository.MarkApplied("home", "missing"))
+
+	details, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, at(created.Add(time.Hour)), details[0].CreatedAt)
+	assert.Equal(t, at(now), details[0].UpdatedAt)
+	assert.Equal(t, *editor, details[0])
+
+	// editing a detail counts as editing its profile
+	profiles, err := repository.GetAllProfiles()
+	require.Nil(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, Profile{ID: work.ID, Name: "work", CreatedAt: at(created), UpdatedAt: at(now)}, profiles[0])
+	assert.Equal(t, Profile{ID: home.ID, Name: "home", CreatedAt: at(created), UpdatedAt: at(created), LastAppliedAt: at(now)}, profiles[1])
+}
+
+func TestSettings(t *testing.T) {
+	repository := newTestRepository(t)
+	value, err := repository.GetSetting("profiles.sort")
+	require.Nil(t, err)
+	assert.Equal(t, "", value)
+	require.Nil(t, repository.SetSetting("profiles.sort", "name"))
+	require.Nil(t, repository.SetSetting("profiles.sort", "used"))
+	value, err = repository.GetSetting("profiles.sort")
+	require.Nil(t, err)
+	assert.Equal(t, "used", value)
+}
+
+func TestDescriptions(t *testing.T) {
+	repository := newTestRepository(t)
+	legacy, err := repository.AddProfile("legacy")
+	require.Nil(t, err)
+	legacy.Description = "old node services"
+	legacy, err = repository.UpdateProfile(legacy, "legacy")
+	require.Nil(t, err)
+	options, err := repository.AddDetail(Detail{Key: "NODE_OPTIONS", Value: "--openssl-legacy-provider", DetailType: EnvDetail, ProfileID: legacy.ID, Description: "webpack 4 needs md4"})
+	require.Nil(t, err)
+
+	// updates keep the description of the passed detail
+	_, err = repository.UpdateDetail(*options, "NODE_OPTIONS", "--openssl-legacy-provider --no-warnings")
+	require.Nil(t, err)
+	details, err := repository.GetDetailsByProfileName("legacy")
+	require.Nil(t, err)
+	require.Len(t, details, 1)
+	assert.Equal(t, "webpack 4 needs md4", details[0].Description)
+
+	profile, err := repository.GetProfileByName("legacy")
+	require.Nil(t, err)
+	assert.Equal(t, "old node services", profile.Description)
+	clone, err := repository.CloneProfile(profile, "legacy-copy")
+	require.Nil(t, err)
+	assert.Equal(t, "old node services", clone.Description)
+	details, err = repository.GetAllDetails(clone.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "webpack 4 needs md4", details[0].Description)
+
+	// undoing the clone and the description update leaves the profile without one
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	profile, err = repository.GetProfileByName("legacy")
+	require.Nil(t, err)
+	assert.Equal(t, "", profile.Description)
+
+	_, err = repository.GetProfileByName("legacy-copy")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+}
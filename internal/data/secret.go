@@ -0,0 +1,174 @@
+package data
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// values sealed with a passphrase stretched with the salt of the database
+	secretPrefix = "enc:v2:"
+	// values sealed before each database had a salt, with the same one for everyone
+	legacySecretPrefix   = "enc:v1:"
+	legacyPassphraseSalt = "maggi-secret-v1"
+	passphraseEnv        = "MAGGI_PASSPHRASE"
+	keyFileEnv           = "MAGGI_KEY_FILE"
+	keyFileDir           = "maggi"
+	keyFileName          = "key"
+	passphraseIteration  = 210000
+)
+
+// SecretMask stands in for the value of a secret wherever values are shown
+const SecretMask = "<secret>"
+
+var (
+	ErrNoSecretKey   = errors.New("no key for secrets found. set MAGGI_PASSPHRASE or create ~/.config/maggi/key")
+	ErrInvalidSecret = errors.New("stored secret is not in a known format")
+	ErrSecretDecrypt = errors.New("unable to decrypt secret. check the passphrase or key file in use")
+	ErrNoSecretSalt  = errors.New("the database has no valid salt for secrets")
+)
+
+// SecretKey is the passphrase or key file contents secret values are sealed with
+type SecretKey struct {
+	passphrase []byte
+	file       []byte
+}
+
+// LoadSecretKey returns the key used for secret values. A passphrase in
+// MAGGI_PASSPHRASE takes precedence over the key file. The key file location can
+// be overridden with MAGGI_KEY_FILE and defaults to ~/.config/maggi/key
+// (respecting XDG_CONFIG_HOME).
+func LoadSecretKey() (SecretKey, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return SecretKey{passphrase: []byte(passphrase)}, nil
+	}
+
+	keyFile := os.Getenv(keyFileEnv)
+	if keyFile == "" {
+		configDir := os.Getenv("XDG_CONFIG_HOME")
+		if configDir == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return SecretKey{}, err
+			}
+			configDir = filepath.Join(homeDir, ".config")
+		}
+		keyFile = filepath.Join(configDir, keyFileDir, keyFileName)
+	}
+	content, err := os.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		return SecretKey{}, ErrNoSecretKey
+	}
+	if err != nil {
+		return SecretKey{}, err
+	}
+	content = []byte(strings.TrimSpace(string(content)))
+	if len(content) == 0 {
+		return SecretKey{}, ErrNoSecretKey
+	}
+	return SecretKey{file: content}, nil
+}
+
+// derive returns the 32 byte key for the salt. key files are expected to hold random
+// material, so hashing is enough there and the salt is not needed.
+func (k SecretKey) derive(salt []byte) []byte {
+	if k.passphrase != nil {
+		return pbkdf2SHA256(k.passphrase, salt, passphraseIteration, 32)
+	}
+	key := sha256.Sum256(k.file)
+	return key[:]
+}
+
+// sealedVersion returns the prefix of the format the value was sealed in
+func sealedVersion(sealed string) string {
+	if strings.HasPrefix(sealed, legacySecretPrefix) {
+		return legacySecretPrefix
+	}
+	return secretPrefix
+}
+
+// secretBox seals and opens the values of one format, told apart by their prefix
+type secretBox struct {
+	aead   cipher.AEAD
+	prefix string
+}
+
+func newSecretBox(key []byte, prefix string) (*secretBox, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretBox{aead: aead, prefix: prefix}, nil
+}
+
+func (s *secretBox) seal(plain string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return s.prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *secretBox) open(sealed string) (string, error) {
+	encoded, ok := strings.CutPrefix(sealed, s.prefix)
+	if !ok {
+		return "", ErrInvalidSecret
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidSecret
+	}
+	if len(raw) < s.aead.NonceSize() {
+		return "", ErrInvalidSecret
+	}
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrSecretDecrypt
+	}
+	return string(plain), nil
+}
+
+// pbkdf2SHA256 is PBKDF2 (RFC 8018) with HMAC-SHA256. kept here to avoid pulling
+// in x/crypto for a single function.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+	var counter [4]byte
+	out := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+		t := make([]byte, hashLen)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbkdf2SHA256(t *testing.T) {
+	testcases := []struct {
+		name       string
+		iterations int
+		res        string
+	}{
+		{
+			name:       "single iteration matches known vector",
+			iterations: 1,
+			res:        "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b",
+		},
+		{
+			name:       "two iterations matches known vector",
+			iterations: 2,
+			res:        "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			res := pbkdf2SHA256([]byte("password"), []byte("salt"), testcase.iterations, 32)
+			assert.Equal(t, testcase.res, hex.EncodeToString(res))
+		})
+	}
+}
+
+func TestSecretBox(t *testing.T) {
+	box, err := newSecretBox(make([]byte, 32), secretPrefix)
+	assert.Nil(t, err)
+
+	t.Run("sealed value can be opened", func(t *testing.T) {
+		sealed, err := box.seal("super secret")
+		assert.Nil(t, err)
+		assert.NotContains(t, sealed, "super secret")
+		res, err := box.open(sealed)
+		assert.Nil(t, err)
+		assert.Equal(t, "super secret", res)
+	})
+
+	t.Run("plaintext value is rejected", func(t *testing.T) {
+		_, err := box.open("super secret")
+		assert.Equal(t, ErrInvalidSecret, err)
+	})
+
+	t.Run("value sealed with another key fails", func(t *testing.T) {
+		otherKey := make([]byte, 32)
+		otherKey[0] = 1
+		other, err := newSecretBox(otherKey, secretPrefix)
+		assert.Nil(t, err)
+		sealed, err := other.seal("super secret")
+		assert.Nil(t, err)
+		_, err = box.open(sealed)
+		assert.Equal(t, ErrSecretDecrypt, err)
+	})
+
+	t.Run("value sealed in another format is rejected", func(t *testing.T) {
+		legacy, err := newSecretBox(make([]byte, 32), legacySecretPrefix)
+		assert.Nil(t, err)
+		sealed, err := legacy.seal("super secret")
+		assert.Nil(t, err)
+		assert.Equal(t, legacySecretPrefix, sealedVersion(sealed))
+		_, err = box.open(sealed)
+		assert.Equal(t, ErrInvalidSecret, err)
+	})
+}
+
+func TestSecretSalt(t *testing.T) {
+	repository := newTestRepository(t)
+	salt, err := repository.secretSalt()
+	require.Nil(t, err)
+	assert.Len(t, salt, 16)
+	// every database gets a salt of its own
+	other := newTestRepository(t)
+	otherSalt, err := other.secretSalt()
+	require.Nil(t, err)
+	assert.NotEqual(t, salt, otherSalt)
+
+	profile, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	_, err = repository.AddDetail(Detail{Key: "TOKEN", Value: "abc", DetailType: EnvDetail, ProfileID: profile.ID, Secret: true})
+	require.Nil(t, err)
+	details, err := repository.GetAllDetails(profile.ID)
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(details[0].Value, secretPrefix))
+	value, err := repository.RevealValue(details[0])
+	require.Nil(t, err)
+	assert.Equal(t, "abc", value)
+
+	// values sealed with the fixed salt of earlier versions still open
+	key, err := LoadSecretKey()
+	require.Nil(t, err)
+	legacy, err := newSecretBox(key.derive([]byte(legacyPassphraseSalt)), legacySecretPrefix)
+	require.Nil(t, err)
+	sealed, err := legacy.seal("old")
+	require.Nil(t, err)
+	value, err = repository.RevealValue(Detail{Key: "OLD", Value: sealed, DetailType: EnvDetail, Secret: true})
+	require.Nil(t, err)
+	assert.Equal(t, "old", value)
+
+	// a database without its salt cannot seal new values
+	_, err = other.db.Exec("DELETE FROM secret_salt;")
+	require.Nil(t, err)
+	_, err = other.sealValue("abc", true)
+	assert.ErrorIs(t, err, ErrNoSecretSalt)
+}
@@ -0,0 +1,23 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// GetSetting returns the stored value of a setting, or "" if it was never set
+func (mr *MaggiRepository) GetSetting(key string) (string, error) {
+	var value string
+	err := mr.db.QueryRow("SELECT value FROM settings WHERE key = ?;", key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetSetting stores the value of a setting, replacing what was there. Settings are
+// preferences, not data, so they are not journaled.
+func (mr *MaggiRepository) SetSetting(key, value string) error {
+	_, err := mr.db.Exec("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value;", key, value)
+	return err
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -40,6 +41,146 @@ const (
     COMMIT;`
 )
 
+// migrations are applied in order on top of the base DDL. the index of the last
+// applied migration is tracked in sqlite's user_version pragma, so new entries
+// must only ever be appended.
+var migrations = []string{
+	`ALTER TABLE details ADD COLUMN secret INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE details ADD COLUMN kind STRING CHECK( kind IN ('literal', 'cmd', 'file') ) NOT NULL DEFAULT 'literal';`,
+	// sqlite cannot alter a CHECK constraint, so the table is rebuilt to allow path details
+	`
+    CREATE TABLE details_new (
+    id INTEGER NOT NULL PRIMARY KEY,
+    key STRING NOT NULL,
+    value STRING NOT NULL,
+    type STRING CHECK( type IN ('alias', 'env', 'path') ) NOT NULL,
+    profile_id INTEGER NOT NULL,
+    secret INTEGER NOT NULL DEFAULT 0,
+    kind STRING CHECK( kind IN ('literal', 'cmd', 'file') ) NOT NULL DEFAULT 'literal',
+    position STRING CHECK( position IN ('prepend', 'append') ) NOT NULL DEFAULT 'prepend',
+    separator STRING NOT NULL DEFAULT ':',
+    FOREIGN KEY(profile_id) REFERENCES profiles(id)
+    );
+    INSERT INTO details_new (id, key, value, type, profile_id, secret, kind)
+    SELECT id, key, value, type, profile_id, secret, kind FROM details;
+    DROP TABLE details;
+    ALTER TABLE details_new RENAME TO details;
+    CREATE INDEX IF NOT EXISTS details_profile_idx ON details (profile_id);
+    CREATE INDEX IF NOT EXISTS details_type_idx ON details (type);`,
+	`
+    CREATE TABLE details_new (
+    id INTEGER NOT NULL PRIMARY KEY,
+    key STRING NOT NULL,
+    value STRING NOT NULL,
+    type STRING CHECK( type IN ('alias', 'env', 'path', 'function') ) NOT NULL,
+    profile_id INTEGER NOT NULL,
+    secret INTEGER NOT NULL DEFAULT 0,
+    kind STRING CHECK( kind IN ('literal', 'cmd', 'file') ) NOT NULL DEFAULT 'literal',
+    position STRING CHECK( position IN ('prepend', 'append') ) NOT NULL DEFAULT 'prepend',
+    separator STRING NOT NULL DEFAULT ':',
+    FOREIGN KEY(profile_id) REFERENCES profiles(id)
+    );
+    INSERT INTO details_new (id, key, value, type, profile_id, secret, kind, position, separator)
+    SELECT id, key, value, type, profile_id, secret, kind, position, separator FROM details;
+    DROP TABLE details;
+    ALTER TABLE details_new RENAME TO details;
+    CREATE INDEX IF NOT EXISTS details_profile_idx ON details (profile_id);
+    CREATE INDEX IF NOT EXISTS details_type_idx ON details (type);`,
+	`
+    CREATE TABLE details_new (
+    id INTEGER NOT NULL PRIMARY KEY,
+    key STRING NOT NULL,
+    value STRING NOT NULL,
+    type STRING CHECK( type IN ('alias', 'env', 'path', 'function', 'source', 'raw') ) NOT NULL,
+    profile_id INTEGER NOT NULL,
+    secret INTEGER NOT NULL DEFAULT 0,
+    kind STRING CHECK( kind IN ('literal', 'cmd', 'file') ) NOT NULL DEFAULT 'literal',
+    position STRING CHECK( position IN ('prepend', 'append') ) NOT NULL DEFAULT 'prepend',
+    separator STRING NOT NULL DEFAULT ':',
+    guard INTEGER NOT NULL DEFAULT 0,
+    shells STRING NOT NULL DEFAULT '',
+    FOREIGN KEY(profile_id) REFERENCES profiles(id)
+    );
+    INSERT INTO details_new (id, key, value, type, profile_id, secret, kind, position, separator)
+    SELECT id, key, value, type, profile_id, secret, kind, position, separator FROM details;
+    DROP TABLE details;
+    ALTER TABLE details_new RENAME TO details;
+    CREATE INDEX IF NOT EXISTS details_profile_idx ON details (profile_id);
+    CREATE INDEX IF NOT EXISTS details_type_idx ON details (type);`,
+	// every add, update and delete is journaled so it can be undone. changes holds the
+	// rows before and after as json
+	`
+    CREATE TABLE journal (
+    id INTEGER NOT NULL PRIMARY KEY,
+    label STRING NOT NULL,
+    changes STRING NOT NULL,
+    undone INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL
+    );`,
+	// deleted rows stay in the trash until purged. deleted_at is null for live rows
+	`
+    ALTER TABLE profiles ADD COLUMN deleted_at INTEGER;
+    ALTER TABLE details ADD COLUMN deleted_at INTEGER;`,
+	// history of every value, kept apart from the journal as it outlives the undo window
+	// and purged rows. profile changes have an empty type and key
+	`
+    CREATE TABLE history (
+    id INTEGER NOT NULL PRIMARY KEY,
+    profile_id INTEGER NOT NULL,
+    profile_name STRING NOT NULL,
+    detail_id INTEGER NOT NULL DEFAULT 0,
+    type STRING NOT NULL DEFAULT '',
+    key STRING NOT NULL DEFAULT '',
+    old_value STRING,
+    new_value STRING,
+    old_secret INTEGER NOT NULL DEFAULT 0,
+    new_secret INTEGER NOT NULL DEFAULT 0,
+    source STRING CHECK( source IN ('tui', 'cli', 'import') ) NOT NULL,
+    created_at INTEGER NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS history_detail_idx ON history (detail_id);
+    CREATE INDEX IF NOT EXISTS history_profile_idx ON history (profile_name);`,
+	// rows from before this migration have null timestamps, shown as unknown. settings
+	// keeps preferences of maggi ui such as the sort of its lists
+	`
+    ALTER TABLE profiles ADD COLUMN created_at INTEGER;
+    ALTER TABLE profiles ADD COLUMN updated_at INTEGER;
+    ALTER TABLE profiles ADD COLUMN last_applied_at INTEGER;
+    ALTER TABLE details ADD COLUMN created_at INTEGER;
+    ALTER TABLE details ADD COLUMN updated_at INTEGER;
+    CREATE TABLE settings (
+    key STRING NOT NULL PRIMARY KEY,
+    value STRING NOT NULL
+    );`,
+	// optional notes on why a profile or detail exists
+	`
+    ALTER TABLE profiles ADD COLUMN description STRING NOT NULL DEFAULT '';
+    ALTER TABLE details ADD COLUMN description STRING NOT NULL DEFAULT '';`,
+	// free-form tags of profiles, e.g. aws or client-x
+	`
+    CREATE TABLE profile_tags (
+    profile_id INTEGER NOT NULL,
+    tag STRING NOT NULL,
+    PRIMARY KEY(profile_id, tag),
+    FOREIGN KEY(profile_id) REFERENCES profiles(id)
+    );
+    CREATE INDEX IF NOT EXISTS profile_tags_tag_idx ON profile_tags (tag);`,
+	// the random salt passphrases are stretched with for secrets, so the same passphrase
+	// gives a different key in every database. values sealed before keep the fixed salt.
+	// the table holds a single row
+	`
+    CREATE TABLE secret_salt (
+    id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1),
+    salt STRING NOT NULL
+    );
+    INSERT INTO secret_salt (id, salt) VALUES (1, lower(hex(randomblob(16))));`,
+	// the kind of the values in the history, so a restore brings it back too. empty for
+	// rows from before
+	`
+    ALTER TABLE history ADD COLUMN old_kind STRING NOT NULL DEFAULT '';
+    ALTER TABLE history ADD COLUMN new_kind STRING NOT NULL DEFAULT '';`,
+}
+
 func Setup() (*sql.DB, error) {
 	var err error
 	homeDir, err := os.UserHomeDir()
@@ -80,5 +221,33 @@ func Setup() (*sql.DB, error) {
 		return nil, err
 	}
 
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version;").Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+		// pragma does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d;", i+1)); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
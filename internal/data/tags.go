@@ -0,0 +1,150 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ParseTags splits tags separated by commas or whitespace, e.g. `aws, k8s client-x`.
+// The tags come back sorted and without duplicates.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+func checkTag(tag string) error {
+	if tag == "" || strings.ContainsFunc(tag, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+	}
+	return nil
+}
+
+// SetProfileTags replaces the tags of the profile. Tags can't be empty or contain
+// commas or whitespace.
+func (mr *MaggiRepository) SetProfileTags(profile Profile, tags []string) (Profile, error) {
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	for _, tag := range tags {
+		if err := checkTag(tag); err != nil {
+			return profile, err
+		}
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	updatedAt := mr.timestamp()
+	err := mr.mutate("tag profile "+profile.Name, func(tx *sql.Tx, entry *journalEntry) error {
+		before, err := getProfile(tx, profile.ID)
+		if err != nil {
+			return err
+		}
+		if err := writeTags(tx, profile.ID, tags); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE profiles SET updated_at = ? WHERE id = ?;", updatedAt.Unix(), profile.ID); err != nil {
+			return err
+		}
+		after := before
+		after.Tags = tags
+		after.UpdatedAt = updatedAt
+		entry.profile(&before, &after)
+		return nil
+	})
+	if err != nil {
+		return profile, err
+	}
+	profile.Tags = tags
+	profile.UpdatedAt = updatedAt
+	return profile, nil
+}
+
+// GetTags returns every tag used by a live profile, sorted
+func (mr *MaggiRepository) GetTags() ([]string, error) {
+	rows, err := mr.db.Query("SELECT DISTINCT tag FROM profile_tags WHERE profile_id IN (SELECT id FROM profiles WHERE deleted_at IS NULL) ORDER BY tag;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetProfilesByTag returns the live profiles tagged with tag, ordered by name so that
+// applying them is repeatable
+func (mr *MaggiRepository) GetProfilesByTag(tag string) ([]Profile, error) {
+	profiles, err := mr.GetAllProfiles()
+	if err != nil {
+		return nil, err
+	}
+	tagged := []Profile{}
+	for _, profile := range profiles {
+		if slices.Contains(profile.Tags, tag) {
+			tagged = append(tagged, profile)
+		}
+	}
+	slices.SortFunc(tagged, func(a, b Profile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tagged, nil
+}
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// getTags returns the tags of the given profile, or of every profile if id is 0
+func getTags(q queryer, id int) (map[int][]string, error) {
+	stmt := "SELECT profile_id, tag FROM profile_tags ORDER BY profile_id, tag;"
+	var args []any
+	if id != 0 {
+		stmt = "SELECT profile_id, tag FROM profile_tags WHERE profile_id = ? ORDER BY tag;"
+		args = append(args, id)
+	}
+	rows, err := q.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var profileID int
+		var tag string
+		if err := rows.Scan(&profileID, &tag); err != nil {
+			return nil, err
+		}
+		tags[profileID] = append(tags[profileID], tag)
+	}
+	return tags, rows.Err()
+}
+
+func writeTags(tx *sql.Tx, profileID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM profile_tags WHERE profile_id = ?;", profileID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT INTO profile_tags (profile_id, tag) VALUES (?, ?);", profileID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
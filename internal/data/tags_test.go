@@ -0,0 +1,74 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	assert.Equal(t, []string{"aws", "client-x", "k8s"}, ParseTags(" k8s,aws  client-x, aws,"))
+	assert.Nil(t, ParseTags(" , "))
+}
+
+func TestProfileTags(t *testing.T) {
+	repository := newTestRepository(t)
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	eu, err := repository.AddProfile("eu")
+	require.Nil(t, err)
+	_, err = repository.AddProfile("home")
+	require.Nil(t, err)
+
+	work, err = repository.SetProfileTags(work, []string{"k8s", "aws", "aws"})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"aws", "k8s"}, work.Tags)
+	_, err = repository.SetProfileTags(eu, []string{"aws"})
+	require.Nil(t, err)
+	_, err = repository.SetProfileTags(eu, []string{"two words"})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	tags, err := repository.GetTags()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"aws", "k8s"}, tags)
+	tagged, err := repository.GetProfilesByTag("aws")
+	require.Nil(t, err)
+	require.Len(t, tagged, 2)
+	assert.Equal(t, "eu", tagged[0].Name)
+	assert.Equal(t, "work", tagged[1].Name)
+	stored, err := repository.GetProfileByName("work")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"aws", "k8s"}, stored.Tags)
+
+	clone, err := repository.CloneProfile(work, "work-eu")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"aws", "k8s"}, clone.Tags)
+
+	// tags come and go with undo and redo
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	_, err = repository.Undo()
+	require.Nil(t, err)
+	tagged, err = repository.GetProfilesByTag("aws")
+	require.Nil(t, err)
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "work", tagged[0].Name)
+	_, err = repository.Redo()
+	require.Nil(t, err)
+	tagged, err = repository.GetProfilesByTag("aws")
+	require.Nil(t, err)
+	assert.Len(t, tagged, 2)
+
+	// profiles in the trash are not applied, and purging takes their tags along
+	require.Nil(t, repository.DeleteProfile(work))
+	tags, err = repository.GetTags()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"aws"}, tags)
+	_, err = repository.PurgeTrash(time.Now().Add(time.Minute))
+	require.Nil(t, err)
+	tagged, err = repository.GetProfilesByTag("k8s")
+	require.Nil(t, err)
+	assert.Empty(t, tagged)
+}
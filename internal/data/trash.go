@@ -0,0 +1,271 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultTrashRetention is how long deleted profiles and details are kept before
+// being purged, unless MAGGI_TRASH_RETENTION says otherwise.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+var ErrDetailExists = errors.New("detail already exists")
+
+// TrashedProfile is a deleted profile with the number of details deleted with it
+type TrashedProfile struct {
+	Profile
+	Details int
+}
+
+// TrashedDetail is a detail deleted on its own, with the name of its profile
+type TrashedDetail struct {
+	Detail
+	ProfileName string
+}
+
+// Trash is everything deleted and not purged yet, most recently deleted first.
+// Details deleted along with their profile are only counted on the profile.
+type Trash struct {
+	Profiles []TrashedProfile
+	Details  []TrashedDetail
+}
+
+func (t Trash) Empty() bool {
+	return len(t.Profiles) == 0 && len(t.Details) == 0
+}
+
+// TrashRetention reads the retention period from MAGGI_TRASH_RETENTION, e.g. `168h`.
+// 0 keeps the trash until purged by hand.
+func TrashRetention() (time.Duration, error) {
+	value, ok := os.LookupEnv("MAGGI_TRASH_RETENTION")
+	if !ok || value == "" {
+		return DefaultTrashRetention, nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		return 0, fmt.Errorf("MAGGI_TRASH_RETENTION must be a positive duration like 168h, got %q", value)
+	}
+	return retention, nil
+}
+
+func (mr *MaggiRepository) GetTrash() (Trash, error) {
+	var trash Trash
+	rows, err := mr.db.Query("SELECT id, name, deleted_at FROM profiles ORDER BY deleted_at DESC, id;")
+	if err != nil {
+		return trash, err
+	}
+	defer rows.Close()
+	names := make(map[int]string)
+	deletedProfiles := make(map[int]time.Time)
+	for rows.Next() {
+		var profile TrashedProfile
+		var deletedAt sql.NullInt64
+		if err := rows.Scan(&profile.ID, &profile.Name, &deletedAt); err != nil {
+			return trash, err
+		}
+		names[profile.ID] = profile.Name
+		if deletedAt.Valid {
+			profile.DeletedAt = unixTime(deletedAt)
+			deletedProfiles[profile.ID] = profile.DeletedAt
+			trash.Profiles = append(trash.Profiles, profile)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return trash, err
+	}
+
+	detailRows, err := mr.db.Query("SELECT " + detailColumns + " FROM details WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id;")
+	if err != nil {
+		return trash, err
+	}
+	defer detailRows.Close()
+	details, err := scanDetails(detailRows)
+	if err != nil {
+		return trash, err
+	}
+	for _, detail := range details {
+		profileDeletedAt, ok := deletedProfiles[detail.ProfileID]
+		if ok && profileDeletedAt.Equal(detail.DeletedAt) {
+			for i := range trash.Profiles {
+				if trash.Profiles[i].ID == detail.ProfileID {
+					trash.Profiles[i].Details++
+				}
+			}
+			continue
+		}
+		trash.Details = append(trash.Details, TrashedDetail{Detail: detail, ProfileName: names[detail.ProfileID]})
+	}
+	return trash, nil
+}
+
+// RestoreProfile takes the profile out of the trash along with the details deleted
+// with it. It fails if another profile took the name in the meantime.
+func (mr *MaggiRepository) RestoreProfile(profile Profile) error {
+	return mr.mutate("restore profile "+profile.Name, func(tx *sql.Tx, entry *journalEntry) error {
+		before, err := getProfile(tx, profile.ID)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt.IsZero() {
+			return fmt.Errorf("profile %s is not in the trash", before.Name)
+		}
+		var taken int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM profiles WHERE name = ? AND deleted_at IS NULL;", before.Name).Scan(&taken); err != nil {
+			return err
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: %s", ErrProfileExists, before.Name)
+		}
+
+		if _, err := tx.Exec("UPDATE profiles SET deleted_at = NULL WHERE id = ?;", profile.ID); err != nil {
+			return err
+		}
+		after := before
+		after.DeletedAt = time.Time{}
+		entry.profile(&before, &after)
+
+		rows, err := tx.Query("SELECT "+detailColumns+" FROM details WHERE profile_id = ? AND deleted_at = ?;", profile.ID, before.DeletedAt.Unix())
+		if err != nil {
+			return err
+		}
+		details, err := scanDetails(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		for _, detail := range details {
+			if err := restoreDetail(tx, entry, detail); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RestoreDetail takes a detail out of the trash. Its profile has to be live and the
+// key must not have been taken since.
+func (mr *MaggiRepository) RestoreDetail(detail Detail) error {
+	return mr.mutate("restore "+describeDetail(detail), func(tx *sql.Tx, entry *journalEntry) error {
+		stored, err := getDetail(tx, detail.ID)
+		if err != nil {
+			return err
+		}
+		if stored.DeletedAt.IsZero() {
+			return fmt.Errorf("%s is not in the trash", stored.Key)
+		}
+		profile, err := getProfile(tx, stored.ProfileID)
+		if err != nil {
+			return err
+		}
+		if !profile.DeletedAt.IsZero() {
+			return fmt.Errorf("profile %s is in the trash, restore it first", profile.Name)
+		}
+		rows, err := tx.Query("SELECT "+detailColumns+" FROM details WHERE profile_id = ? AND deleted_at IS NULL;", stored.ProfileID)
+		if err != nil {
+			return err
+		}
+		existing, err := scanDetails(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if _, ok := FindConflict(existing, stored); ok {
+			return fmt.Errorf("%w: %s in profile %s", ErrDetailExists, stored.Key, profile.Name)
+		}
+		return restoreDetail(tx, entry, stored)
+	})
+}
+
+func restoreDetail(tx *sql.Tx, entry *journalEntry, detail Detail) error {
+	if _, err := tx.Exec("UPDATE details SET deleted_at = NULL WHERE id = ?;", detail.ID); err != nil {
+		return err
+	}
+	before := detail
+	after := detail
+	after.DeletedAt = time.Time{}
+	entry.detail(&before, &after)
+	return nil
+}
+
+// PurgeProfile removes a profile in the trash and all its details for good. Purging
+// is not journaled, so it can't be undone.
+func (mr *MaggiRepository) PurgeProfile(profile Profile) error {
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM details WHERE profile_id = ? AND (SELECT deleted_at FROM profiles WHERE id = ?) IS NOT NULL;", profile.ID, profile.ID); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if _, err := tx.Exec("DELETE FROM profile_tags WHERE profile_id = ? AND (SELECT deleted_at FROM profiles WHERE id = ?) IS NOT NULL;", profile.ID, profile.ID); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	res, err := tx.Exec("DELETE FROM profiles WHERE id = ? AND deleted_at IS NOT NULL;", profile.ID)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := checkWritten(res, "trashed profile"); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// PurgeDetail removes a detail in the trash for good.
+func (mr *MaggiRepository) PurgeDetail(detail Detail) error {
+	res, err := mr.db.Exec("DELETE FROM details WHERE id = ? AND deleted_at IS NOT NULL;", detail.ID)
+	if err != nil {
+		return err
+	}
+	return checkWritten(res, "trashed detail")
+}
+
+// PurgeTrash removes everything deleted at or before the given time for good and
+// returns how many profiles and details were purged.
+func (mr *MaggiRepository) PurgeTrash(before time.Time) (int, error) {
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	purged, err := purgeTrash(tx, before)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+	return purged, tx.Commit()
+}
+
+func purgeTrash(tx *sql.Tx, before time.Time) (int, error) {
+	cutoff := before.Unix()
+	// details of purged profiles go too, whether they were deleted with it or not
+	res, err := tx.Exec("DELETE FROM details WHERE deleted_at <= ? OR profile_id IN (SELECT id FROM profiles WHERE deleted_at <= ?);", cutoff, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	details, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM profile_tags WHERE profile_id IN (SELECT id FROM profiles WHERE deleted_at <= ?);", cutoff); err != nil {
+		return 0, err
+	}
+	res, err = tx.Exec("DELETE FROM profiles WHERE deleted_at <= ?;", cutoff)
+	if err != nil {
+		return 0, err
+	}
+	profiles, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(details + profiles), nil
+}
+
+// PurgeExpired empties the trash of everything deleted longer than retention ago and
+// returns how many profiles and details were purged. 0 keeps the trash as is.
+func (mr *MaggiRepository) PurgeExpired(retention time.Duration) (int, error) {
+	if retention == 0 {
+		return 0, nil
+	}
+	return mr.PurgeTrash(mr.now().Add(-retention))
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	repository := newTestRepository(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+
+	work, err := repository.AddProfile("work")
+	require.Nil(t, err)
+	home, err := repository.AddProfile("home")
+	require.Nil(t, err)
+	for _, key := range []string{"EDITOR", "PAGER"} {
+		_, err := repository.AddDetail(Detail{Key: key, Value: "x", DetailType: EnvDetail, ProfileID: work.ID})
+		require.Nil(t, err)
+	}
+	_, err = repository.AddDetail(Detail{Key: "LANG", Value: "C", DetailType: EnvDetail, ProfileID: home.ID})
+	require.Nil(t, err)
+	workDetails, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	homeDetails, err := repository.GetAllDetails(home.ID)
+	require.Nil(t, err)
+
+	// deleted rows drop out of every listing but stay in the trash
+	require.Nil(t, repository.DeleteDetail(workDetails[1]))
+	now = now.Add(time.Hour)
+	require.Nil(t, repository.DeleteProfile(home))
+	profiles, err := repository.GetAllProfiles()
+	require.Nil(t, err)
+	assert.Equal(t, []Profile{work}, profiles)
+	details, err := repository.GetAllDetails(work.ID)
+	require.Nil(t, err)
+	assert.Equal(t, workDetails[:1], details)
+	details, err = repository.GetDetailsByProfileName("home")
+	require.Nil(t, err)
+	assert.Empty(t, details)
+
+	trash, err := repository.GetTrash()
+	require.Nil(t, err)
+	require.Len(t, trash.Profiles, 1)
+	assert.Equal(t, "home", trash.Profiles[0].Name)
+	assert.Equal(t, 1, trash.Profiles[0].Details)
+	assert.True(t, trash.Profiles[0].DeletedAt.Equal(now))
+	require.Len(t, trash.Details, 1)
+	assert.Equal(t, "PAGER", trash.Details[0].Key)
+	assert.Equal(t, "work", trash.Details[0].ProfileName)
+
+	// the name of a deleted profile is free again, but then blocks restoring it
+	other, err := repository.AddProfile("home")
+	require.Nil(t, err)
+	assert.ErrorIs(t, repository.RestoreProfile(home), ErrProfileExists)
+	require.Nil(t, repository.DeleteProfile(other))
+	require.Nil(t, repository.PurgeProfile(other))
+	require.Nil(t, repository.RestoreProfile(home))
+	details, err = repository.GetAllDetails(home.ID)
+	require.Nil(t, err)
+	assert.Equal(t, homeDetails, details)
+
+	_, err = repository.AddDetail(Detail{Key: "PAGER", Value: "less", DetailType: EnvDetail, ProfileID: work.ID})
+	require.Nil(t, err)
+	assert.ErrorIs(t, repository.RestoreDetail(trash.Details[0].Detail), ErrDetailExists)
+
+	// purging by age leaves newer deletions alone
+	require.Nil(t, repository.DeleteDetail(homeDetails[0]))
+	purged, err := repository.PurgeTrash(now.Add(-time.Minute))
+	require.Nil(t, err)
+	assert.Equal(t, 1, purged)
+	trash, err = repository.GetTrash()
+	require.Nil(t, err)
+	assert.Empty(t, trash.Profiles)
+	require.Len(t, trash.Details, 1)
+	assert.Equal(t, "LANG", trash.Details[0].Key)
+	require.Nil(t, repository.PurgeDetail(trash.Details[0].Detail))
+	trash, err = repository.GetTrash()
+	require.Nil(t, err)
+	assert.True(t, trash.Empty())
+
+	// undoing the delete of a purged detail fails rather than doing nothing
+	_, err = repository.Undo()
+	assert.ErrorContains(t, err, "detail no longer exists")
+}
+
+func TestTrashRetention(t *testing.T) {
+	t.Setenv("MAGGI_TRASH_RETENTION", "")
+	retention, err := TrashRetention()
+	require.Nil(t, err)
+	assert.Equal(t, DefaultTrashRetention, retention)
+	t.Setenv("MAGGI_TRASH_RETENTION", "168h")
+	retention, err = TrashRetention()
+	require.Nil(t, err)
+	assert.Equal(t, 168*time.Hour, retention)
+	t.Setenv("MAGGI_TRASH_RETENTION", "a week")
+	_, err = TrashRetention()
+	assert.NotNil(t, err)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	repository := newTestRepository(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	repository.now = func() time.Time { return now }
+
+	old, err := repository.AddProfile("old")
+	require.Nil(t, err)
+	recent, err := repository.AddProfile("recent")
+	require.Nil(t, err)
+	require.Nil(t, repository.DeleteProfile(old))
+	now = now.Add(24 * time.Hour)
+	require.Nil(t, repository.DeleteProfile(recent))
+
+	// 0 keeps everything
+	now = now.Add(time.Hour)
+	purged, err := repository.PurgeExpired(0)
+	require.Nil(t, err)
+	assert.Equal(t, 0, purged)
+
+	purged, err = repository.PurgeExpired(12 * time.Hour)
+	require.Nil(t, err)
+	assert.Equal(t, 1, purged)
+	trash, err := repository.GetTrash()
+	require.Nil(t, err)
+	require.Len(t, trash.Profiles, 1)
+	assert.Equal(t, "recent", trash.Profiles[0].Name)
+}
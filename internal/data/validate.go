@@ -0,0 +1,115 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DetailField names the part of a detail a validation problem was found in.
+type DetailField string
+
+const (
+	KeyField   DetailField = "key"
+	ValueField DetailField = "value"
+)
+
+var (
+	// POSIX names for environment variables, also used for function names
+	envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// the portable filename characters plus the extra ones POSIX allows in alias names
+	aliasNamePattern    = regexp.MustCompile(`^[A-Za-z0-9_.!%,@-]+$`)
+	functionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.:-]*$`)
+)
+
+// env names set by the shell itself. setting them from a profile breaks the shell state
+var reservedEnvNames = []string{"PWD", "OLDPWD", "SHLVL"}
+
+// ValidationError is a problem with one field of a detail. Warnings are worth showing
+// but do not stop the detail from being saved.
+type ValidationError struct {
+	Field   DetailField
+	Message string
+	Warning bool
+}
+
+func (v ValidationError) Error() string {
+	return v.Message
+}
+
+// ValidateDetail returns every problem found in the key and value of the detail,
+// warnings included.
+func ValidateDetail(detail Detail) []ValidationError {
+	var problems []ValidationError
+	if problem, ok := validateKey(detail); ok {
+		problems = append(problems, problem)
+	}
+	if problem, ok := validateValue(detail); ok {
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// CheckDetail returns the problems that stop the detail from being saved, joined into
+// a single error. Use errors.As to get to the individual ValidationError.
+func CheckDetail(detail Detail) error {
+	var errs []error
+	for _, problem := range ValidateDetail(detail) {
+		if !problem.Warning {
+			errs = append(errs, problem)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateKey(detail Detail) (ValidationError, bool) {
+	key := detail.Key
+	invalid := func(format string, args ...any) (ValidationError, bool) {
+		return ValidationError{Field: KeyField, Message: fmt.Sprintf(format, args...)}, true
+	}
+	switch detail.DetailType {
+	case EnvDetail, PathDetail:
+		if !envNamePattern.MatchString(key) {
+			return invalid("%q is not a valid env var name. use letters, digits and _, not starting with a digit", key)
+		}
+		for _, reserved := range reservedEnvNames {
+			if key == reserved {
+				return invalid("%s is managed by the shell and cannot be set from a profile", key)
+			}
+		}
+		if detail.DetailType == EnvDetail && key == "PATH" {
+			return ValidationError{Field: KeyField, Message: "setting PATH replaces it entirely. use a path entry to extend it instead", Warning: true}, true
+		}
+	case AliasDetail:
+		if !aliasNamePattern.MatchString(key) || strings.HasPrefix(key, "-") {
+			return invalid("%q is not a valid alias name. use letters, digits and any of _ . ! %% , @ -, not starting with -", key)
+		}
+	case FunctionDetail:
+		if !functionNamePattern.MatchString(key) {
+			return invalid("%q is not a valid function name. use letters, digits and any of _ . : -, not starting with a digit", key)
+		}
+	default:
+		if strings.ContainsAny(key, "\n\x00") {
+			return invalid("name cannot contain line breaks")
+		}
+	}
+	return ValidationError{}, false
+}
+
+func validateValue(detail Detail) (ValidationError, bool) {
+	value := detail.Value
+	if strings.ContainsRune(value, 0) {
+		return ValidationError{Field: ValueField, Message: "value cannot contain NUL bytes"}, true
+	}
+	if detail.DetailType == PathDetail {
+		separator := detail.Separator
+		if separator == "" {
+			separator = DefaultPathSeparator
+		}
+		if strings.Contains(value, separator) {
+			return ValidationError{Field: ValueField, Message: fmt.Sprintf("path entry cannot contain the separator %q", separator)}, true
+		}
+	}
+	return ValidationError{}, false
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDetail(t *testing.T) {
+	testcases := []struct {
+		name     string
+		detail   Detail
+		problems []ValidationError
+	}{
+		{
+			name:   "valid env",
+			detail: Detail{Key: "AWS_PROFILE", Value: "prod", DetailType: EnvDetail},
+		},
+		{
+			name:   "env with dash is invalid",
+			detail: Detail{Key: "MY-VAR", Value: "x", DetailType: EnvDetail},
+			problems: []ValidationError{
+				{Field: KeyField, Message: `"MY-VAR" is not a valid env var name. use letters, digits and _, not starting with a digit`},
+			},
+		},
+		{
+			name:   "env starting with digit is invalid",
+			detail: Detail{Key: "1PASSWORD", Value: "x", DetailType: EnvDetail},
+			problems: []ValidationError{
+				{Field: KeyField, Message: `"1PASSWORD" is not a valid env var name. use letters, digits and _, not starting with a digit`},
+			},
+		},
+		{
+			name:   "reserved env names are invalid",
+			detail: Detail{Key: "SHLVL", Value: "2", DetailType: EnvDetail},
+			problems: []ValidationError{
+				{Field: KeyField, Message: "SHLVL is managed by the shell and cannot be set from a profile"},
+			},
+		},
+		{
+			name:   "PATH env is a warning",
+			detail: Detail{Key: "PATH", Value: "/bin", DetailType: EnvDetail},
+			problems: []ValidationError{
+				{Field: KeyField, Message: "setting PATH replaces it entirely. use a path entry to extend it instead", Warning: true},
+			},
+		},
+		{
+			name:   "PATH is fine for path details",
+			detail: Detail{Key: "PATH", Value: "/opt/bin", DetailType: PathDetail},
+		},
+		{
+			name:   "path entry containing the separator is invalid",
+			detail: Detail{Key: "PATH", Value: "/a:/b", DetailType: PathDetail, Separator: ":"},
+			problems: []ValidationError{
+				{Field: ValueField, Message: `path entry cannot contain the separator ":"`},
+			},
+		},
+		{
+			name:   "alias with punctuation is valid",
+			detail: Detail{Key: "g.st", Value: "git status", DetailType: AliasDetail},
+		},
+		{
+			name:   "alias with space is invalid",
+			detail: Detail{Key: "foo bar", Value: "ls", DetailType: AliasDetail},
+			problems: []ValidationError{
+				{Field: KeyField, Message: `"foo bar" is not a valid alias name. use letters, digits and any of _ . ! % , @ -, not starting with -`},
+			},
+		},
+		{
+			name:   "function names allow dashes",
+			detail: Detail{Key: "git-root", Value: "git rev-parse --show-toplevel", DetailType: FunctionDetail},
+		},
+		{
+			name:   "key and value problems are both reported",
+			detail: Detail{Key: "foo=bar", Value: "a\x00b", DetailType: AliasDetail},
+			problems: []ValidationError{
+				{Field: KeyField, Message: `"foo=bar" is not a valid alias name. use letters, digits and any of _ . ! % , @ -, not starting with -`},
+				{Field: ValueField, Message: "value cannot contain NUL bytes"},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			assert.Equal(t, testcase.problems, ValidateDetail(testcase.detail))
+		})
+	}
+}
+
+func TestCheckDetail(t *testing.T) {
+	assert.Nil(t, CheckDetail(Detail{Key: "PATH", Value: "/bin", DetailType: EnvDetail}))
+
+	err := CheckDetail(Detail{Key: "MY-VAR", Value: "x", DetailType: EnvDetail})
+	var problem ValidationError
+	assert.True(t, errors.As(err, &problem))
+	assert.Equal(t, KeyField, problem.Field)
+}
@@ -0,0 +1,356 @@
+package edit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+// only env and alias details are written when editing a whole profile. they are the
+// ones that fit a KEY=value line.
+var profileTypes = []data.DetailType{data.EnvDetail, data.AliasDetail}
+
+// Entry is a KEY=value line read back from the editor. Value is as written, with the
+// quotes taken off.
+type Entry struct {
+	Line       int
+	DetailType data.DetailType
+	Key        string
+	Value      string
+}
+
+// Profile writes the env and alias details of a profile in a dotenv like format, a
+// section per type. Values are expected in plaintext. Secrets are left out.
+func Profile(profileName string, details []data.Detail) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# maggi profile %s: one KEY=value per line under [env] and [alias].\n", profileName)
+	b.WriteString("# change, add or remove lines, then save and quit to apply. values with line\n")
+	b.WriteString("# breaks or surrounding spaces go in quotes. secrets and the other types of\n")
+	b.WriteString("# details are not listed here and stay as they are.\n")
+	for _, detailType := range profileTypes {
+		fmt.Fprintf(&b, "\n[%s]\n", detailType)
+		for _, detail := range profileScope(details) {
+			if detail.DetailType == detailType {
+				writeEntry(&b, detail)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Detail writes a single detail as a KEY=value line. The value is expected in
+// plaintext, also for secrets.
+func Detail(profileName string, detail data.Detail) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s of maggi profile %s. save and quit to apply, the key can be\n", detail.DetailType, detail.Key, profileName)
+	b.WriteString("# changed too. values with line breaks or surrounding spaces go in quotes.\n")
+	writeEntry(&b, detail)
+	return b.String()
+}
+
+func writeEntry(b *strings.Builder, detail data.Detail) {
+	b.WriteString(detail.Key + "=" + quote(entryValue(detail)) + "\n")
+}
+
+// entryValue is the value as typed in the ui, with the prefix of command and file values
+func entryValue(detail data.Detail) string {
+	if slices.Contains(profileTypes, detail.DetailType) {
+		return data.FormatValue(detail.Kind, detail.Value)
+	}
+	return detail.Value
+}
+
+// quote leaves values that read back the same as they are. the rest are single quoted,
+// or double quoted where they contain a single quote.
+func quote(value string) string {
+	if !strings.Contains(value, "\n") && value == strings.TrimSpace(value) && !strings.HasPrefix(value, "'") && !strings.HasPrefix(value, `"`) {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// Parse reads the KEY=value lines written by Profile or Detail. Lines before the first
+// section are of detailType. Blank lines and lines starting with # are skipped, and an
+// `export ` in front of a key is dropped. Quoted values can span lines. Within double
+// quotes, \" and \\ are the only escapes.
+func Parse(text string, detailType data.DetailType) ([]Entry, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var entries []Entry
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if section, ok := strings.CutPrefix(line, "["); ok {
+			name, ok := strings.CutSuffix(section, "]")
+			if !ok || !slices.Contains([]data.DetailType{data.EnvDetail, data.AliasDetail, data.PathDetail, data.FunctionDetail, data.SourceDetail, data.RawDetail}, data.DetailType(name)) {
+				return nil, fmt.Errorf("line %d: unknown section %s", i+1, line)
+			}
+			detailType = data.DetailType(name)
+			continue
+		}
+		// the end of the line is kept as is, a quoted value can end in spaces
+		line = strings.TrimPrefix(strings.TrimLeft(lines[i], " \t"), "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", i+1)
+		}
+		entry := Entry{Line: i + 1, DetailType: detailType, Key: key}
+		value = strings.TrimLeft(value, " \t")
+		if value == "" || (value[0] != '\'' && value[0] != '"') {
+			entry.Value = strings.TrimSpace(value)
+			entries = append(entries, entry)
+			continue
+		}
+		// the quoted value runs on until its closing quote, which can be lines below
+		quoted, rest, last, err := unquote(value, lines[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s %w", i+1, key, err)
+		}
+		i += last
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, fmt.Errorf("line %d: unexpected %q after the closing quote of %s", i+1, rest, key)
+		}
+		entry.Value = quoted
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// unquote reads a quoted value starting at the opening quote of value, going on into
+// the lines after it if needed. It returns the value, what follows the closing quote
+// and how many of the lines after were read.
+func unquote(value string, after []string) (string, string, int, error) {
+	quote := value[0]
+	text := value[1:]
+	var b strings.Builder
+	for n := 0; ; n++ {
+		for j := 0; j < len(text); j++ {
+			switch {
+			case text[j] == quote:
+				return b.String(), text[j+1:], n, nil
+			case quote == '"' && text[j] == '\\' && j+1 < len(text) && (text[j+1] == '"' || text[j+1] == '\\'):
+				j++
+				b.WriteByte(text[j])
+			default:
+				b.WriteByte(text[j])
+			}
+		}
+		if n == len(after) {
+			return "", "", 0, fmt.Errorf("has no closing quote")
+		}
+		b.WriteByte('\n')
+		text = after[n]
+	}
+}
+
+// ProfileChanges works out what the entries read back from Profile change in the
+// profile. details are all details of the profile, with plaintext values. Lines that
+// are gone delete their detail, new keys are added and changed values updated.
+func ProfileChanges(profileID int, details []data.Detail, entries []Entry) (data.DetailEdit, error) {
+	scope := profileScope(details)
+	// new and changed keys must not clash with any detail left in place. details whose
+	// line is gone are deleted first, so their keys are free, e.g. to turn an alias into
+	// an env.
+	var kept []data.Detail
+	for _, detail := range details {
+		dropped := slices.ContainsFunc(scope, func(d data.Detail) bool { return d.ID == detail.ID }) &&
+			!slices.ContainsFunc(entries, func(e Entry) bool { return e.Key == detail.Key && e.DetailType == detail.DetailType })
+		if !dropped {
+			kept = append(kept, detail)
+		}
+	}
+	var edit data.DetailEdit
+	seen := map[int]bool{}
+	for i, entry := range entries {
+		if !slices.Contains(profileTypes, entry.DetailType) {
+			return data.DetailEdit{}, fmt.Errorf("line %d: only env and alias details can be edited with the profile", entry.Line)
+		}
+		if j := slices.IndexFunc(entries[:i], func(e Entry) bool { return e.Key == entry.Key }); j >= 0 {
+			return data.DetailEdit{}, fmt.Errorf("line %d: %s is set twice, on line %d too", entry.Line, entry.Key, entries[j].Line)
+		}
+		detail := data.Detail{Key: entry.Key, DetailType: entry.DetailType, ProfileID: profileID}
+		if j := slices.IndexFunc(scope, func(d data.Detail) bool { return d.Key == entry.Key && d.DetailType == entry.DetailType }); j >= 0 {
+			detail = scope[j]
+			seen[detail.ID] = true
+		}
+		others := slices.DeleteFunc(slices.Clone(kept), func(d data.Detail) bool { return d.ID == detail.ID })
+		if conflict, ok := data.FindConflict(others, detail); ok {
+			if slices.ContainsFunc(scope, func(d data.Detail) bool { return d.ID == conflict.ID }) {
+				return data.DetailEdit{}, fmt.Errorf("line %d: %s is already taken by %s %s", entry.Line, entry.Key, conflict.DetailType, conflict.Key)
+			}
+			return data.DetailEdit{}, fmt.Errorf("line %d: %s is taken by a %s that is not listed here", entry.Line, entry.Key, describeHidden(conflict))
+		}
+		changed, ok := applyEntry(detail, entry)
+		if !ok && detail.ID != 0 {
+			continue
+		}
+		if err := data.CheckDetail(changed); err != nil {
+			return data.DetailEdit{}, fmt.Errorf("line %d: %s: %w", entry.Line, entry.Key, err)
+		}
+		if detail.ID == 0 {
+			edit.Added = append(edit.Added, changed)
+		} else {
+			edit.Updated = append(edit.Updated, changed)
+		}
+	}
+	for _, detail := range scope {
+		if !seen[detail.ID] {
+			edit.Deleted = append(edit.Deleted, detail)
+		}
+	}
+	return edit, nil
+}
+
+// DetailChanges works out the change the entries read back from Detail make to the
+// detail. others are the other details of the profile, to check a new key against.
+func DetailChanges(detail data.Detail, others []data.Detail, entries []Entry) (data.DetailEdit, error) {
+	if len(entries) != 1 {
+		return data.DetailEdit{}, fmt.Errorf("expected a single KEY=value line for %s, found %d", detail.Key, len(entries))
+	}
+	entry := entries[0]
+	if entry.DetailType != detail.DetailType {
+		return data.DetailEdit{}, fmt.Errorf("line %d: %s can't be turned into a %s", entry.Line, detail.Key, entry.DetailType)
+	}
+	changed, ok := applyEntry(detail, entry)
+	if !ok {
+		return data.DetailEdit{}, nil
+	}
+	if err := data.CheckDetail(changed); err != nil {
+		return data.DetailEdit{}, fmt.Errorf("line %d: %s: %w", entry.Line, entry.Key, err)
+	}
+	if conflict, ok := data.FindConflict(others, changed); ok && conflict.ID != detail.ID {
+		return data.DetailEdit{}, fmt.Errorf("line %d: %s is already taken by %s %s", entry.Line, entry.Key, conflict.DetailType, conflict.Key)
+	}
+	return data.DetailEdit{Updated: []data.Detail{changed}}, nil
+}
+
+// applyEntry sets the key and value of entry on detail. It reports whether anything
+// changed.
+func applyEntry(detail data.Detail, entry Entry) (data.Detail, bool) {
+	changed := detail
+	changed.Key = entry.Key
+	changed.Value = entry.Value
+	if slices.Contains(profileTypes, detail.DetailType) {
+		changed.Kind, changed.Value = data.ParseValue(entry.Value)
+	}
+	// details stored before kinds were tracked have none, which is a literal
+	kind := detail.Kind
+	if kind == "" && changed.Kind == data.LiteralValue {
+		kind = data.LiteralValue
+	}
+	return changed, changed.Key != detail.Key || changed.Value != detail.Value || changed.Kind != kind
+}
+
+// profileScope is the details Profile writes, sorted by key
+func profileScope(details []data.Detail) []data.Detail {
+	var scope []data.Detail
+	for _, detail := range details {
+		if slices.Contains(profileTypes, detail.DetailType) && !detail.Secret {
+			scope = append(scope, detail)
+		}
+	}
+	slices.SortStableFunc(scope, func(a, b data.Detail) int { return strings.Compare(a.Key, b.Key) })
+	return scope
+}
+
+func describeHidden(detail data.Detail) string {
+	if detail.Secret {
+		return "secret " + detail.DetailType.String()
+	}
+	return detail.DetailType.String()
+}
+
+// Describe lists the changes of an edit for a confirmation, a line per change starting
+// with + for added, ~ for updated and - for deleted details. Values of details spanning
+// lines follow as a line diff. details are the details as they were before the edit.
+func Describe(edit data.DetailEdit, details []data.Detail) []string {
+	var lines []string
+	for _, detail := range edit.Added {
+		lines = append(lines, describeValue("+", detail, entryValue(detail))...)
+	}
+	for _, changed := range edit.Updated {
+		i := slices.IndexFunc(details, func(d data.Detail) bool { return d.ID == changed.ID })
+		if i < 0 {
+			continue
+		}
+		lines = append(lines, describeUpdate(details[i], changed)...)
+	}
+	for _, detail := range edit.Deleted {
+		lines = append(lines, describeValue("-", detail, entryValue(detail))...)
+	}
+	return lines
+}
+
+func describeValue(sign string, detail data.Detail, value string) []string {
+	label := fmt.Sprintf("%s %s %s", sign, detail.DetailType, detail.Key)
+	if detail.Secret {
+		return []string{label}
+	}
+	if !strings.Contains(value, "\n") {
+		return []string{label + "=" + value}
+	}
+	lines := []string{label}
+	for _, line := range strings.Split(value, "\n") {
+		lines = append(lines, "    "+sign+" "+line)
+	}
+	return lines
+}
+
+func describeUpdate(before, after data.Detail) []string {
+	label := fmt.Sprintf("~ %s %s", before.DetailType, before.Key)
+	if after.Key != before.Key {
+		label += " → " + after.Key
+	}
+	old, value := entryValue(before), entryValue(after)
+	switch {
+	case old == value:
+		return []string{label}
+	case after.Secret:
+		return []string{label + ": secret value changed"}
+	case !strings.Contains(old, "\n") && !strings.Contains(value, "\n"):
+		return []string{fmt.Sprintf("%s: %s → %s", label, old, value)}
+	}
+	return append([]string{label}, diffLines(strings.Split(old, "\n"), strings.Split(value, "\n"))...)
+}
+
+// diffLines is a line diff of two values, from their longest common subsequence of
+// lines. values are short enough for the quadratic table.
+func diffLines(a, b []string) []string {
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "      "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, "    - "+a[i])
+			i++
+		default:
+			lines = append(lines, "    + "+b[j])
+			j++
+		}
+	}
+	return lines
+}
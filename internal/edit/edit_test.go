@@ -0,0 +1,160 @@
+package edit
+
+import (
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	details := []data.Detail{
+		{ID: 1, Key: "PAGER", Value: "less", DetailType: data.EnvDetail},
+		{ID: 2, Key: "CONFIG", Value: `{"a": 1, "b": "it's"}`, DetailType: data.EnvDetail},
+		{ID: 8, Key: "QUOTED", Value: `'single' and "double" \ `, DetailType: data.EnvDetail},
+		{ID: 3, Key: "BANNER", Value: "  two\nlines  ", DetailType: data.EnvDetail},
+		{ID: 4, Key: "TOKEN", Value: "abc", DetailType: data.EnvDetail, Kind: data.CommandValue},
+		{ID: 5, Key: "ll", Value: "ls -l", DetailType: data.AliasDetail},
+		{ID: 6, Key: "SECRET", Value: "hidden", DetailType: data.EnvDetail, Secret: true},
+		{ID: 7, Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail},
+	}
+	text := Profile("work", details)
+	assert.Contains(t, text, "[env]\nBANNER='  two\nlines  '\nCONFIG={\"a\": 1, \"b\": \"it's\"}\nPAGER=less\nQUOTED=\"'single' and \\\"double\\\" \\\\ \"\nTOKEN=cmd:abc\n")
+	assert.Contains(t, text, "[alias]\nll=ls -l\n")
+	assert.NotContains(t, text, "hidden")
+	assert.NotContains(t, text, "/opt/bin")
+
+	entries, err := Parse(text, data.EnvDetail)
+	require.Nil(t, err)
+	edit, err := ProfileChanges(1, details, entries)
+	require.Nil(t, err)
+	assert.Equal(t, 0, edit.Len())
+}
+
+func TestParse(t *testing.T) {
+	text := `# a comment
+export EDITOR = vim
+EMPTY=
+MULTI="line one
+  \"two\" \\ \n
+" # trailing comment
+[alias]
+gs='git status'
+`
+	entries, err := Parse(text, data.EnvDetail)
+	require.Nil(t, err)
+	assert.Equal(t, []Entry{
+		{Line: 2, DetailType: data.EnvDetail, Key: "EDITOR", Value: "vim"},
+		{Line: 3, DetailType: data.EnvDetail, Key: "EMPTY", Value: ""},
+		{Line: 4, DetailType: data.EnvDetail, Key: "MULTI", Value: "line one\n  \"two\" \\ \\n\n"},
+		{Line: 8, DetailType: data.AliasDetail, Key: "gs", Value: "git status"},
+	}, entries)
+
+	for text, message := range map[string]string{
+		"EDITOR":             "line 1: expected KEY=value",
+		"=vim":               "line 1: expected KEY=value",
+		"\n[nope]":           "line 2: unknown section [nope]",
+		"A='open\nB=x":       "line 1: A has no closing quote",
+		"A='x' y":            `line 1: unexpected "y" after the closing quote of A`,
+		"A=\"x\ny\" z # ok?": `line 2: unexpected "z # ok?" after the closing quote of A`,
+	} {
+		_, err := Parse(text, data.EnvDetail)
+		assert.EqualError(t, err, message, text)
+	}
+}
+
+func TestProfileChanges(t *testing.T) {
+	details := []data.Detail{
+		{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 2, Key: "PAGER", Value: "less", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 3, Key: "ll", Value: "ls -l", DetailType: data.AliasDetail, ProfileID: 1, Description: "long list"},
+		{ID: 4, Key: "TOKEN", Value: "sealed", DetailType: data.EnvDetail, ProfileID: 1, Secret: true},
+	}
+	entries, err := Parse("EDITOR=vim\nLANG=C\n[alias]\nll=ls -la\n", data.EnvDetail)
+	require.Nil(t, err)
+	edit, err := ProfileChanges(1, details, entries)
+	require.Nil(t, err)
+	assert.Equal(t, data.DetailEdit{
+		Added:   []data.Detail{{Key: "LANG", Value: "C", DetailType: data.EnvDetail, ProfileID: 1, Kind: data.LiteralValue}},
+		Updated: []data.Detail{{ID: 3, Key: "ll", Value: "ls -la", DetailType: data.AliasDetail, ProfileID: 1, Kind: data.LiteralValue, Description: "long list"}},
+		Deleted: []data.Detail{details[1]},
+	}, edit)
+	assert.Equal(t, []string{
+		"+ env LANG=C",
+		"~ alias ll: ls -l → ls -la",
+		"- env PAGER=less",
+	}, Describe(edit, details))
+
+	for text, message := range map[string]string{
+		"EDITOR=vim\nEDITOR=nvim":     "line 2: EDITOR is set twice, on line 1 too",
+		"TOKEN=x":                     "line 1: TOKEN is taken by a secret env that is not listed here",
+		"[path]\nPATH=/bin":           "line 2: only env and alias details can be edited with the profile",
+		"1BAD=x":                      `line 1: 1BAD: "1BAD" is not a valid env var name. use letters, digits and _, not starting with a digit`,
+		"[alias]\nll=ls\n[env]\nll=x": "line 4: ll is set twice, on line 2 too",
+		"ll=x\n[alias]\nll=ls -l":     "line 1: ll is already taken by alias ll",
+	} {
+		entries, err := Parse(text, data.EnvDetail)
+		require.Nil(t, err)
+		_, err = ProfileChanges(1, details, entries)
+		assert.EqualError(t, err, message, text)
+	}
+
+	// an alias dropped from the text frees its key for an env
+	entries, err = Parse("EDITOR=vim\nPAGER=less\nll=ls\n", data.EnvDetail)
+	require.Nil(t, err)
+	edit, err = ProfileChanges(1, details, entries)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"+ env ll=ls", "- alias ll=ls -l"}, Describe(edit, details))
+}
+
+func TestDetailChanges(t *testing.T) {
+	function := data.Detail{ID: 5, Key: "greet", Value: "echo hi\necho there", DetailType: data.FunctionDetail}
+	others := []data.Detail{function, {ID: 6, Key: "other", Value: "true", DetailType: data.FunctionDetail}}
+	text := Detail("work", function)
+	assert.Contains(t, text, "greet='echo hi\necho there'\n")
+
+	entries, err := Parse(text, data.FunctionDetail)
+	require.Nil(t, err)
+	edit, err := DetailChanges(function, others, entries)
+	require.Nil(t, err)
+	assert.Equal(t, 0, edit.Len())
+
+	entries, err = Parse("hello='echo hello\necho there\necho again'", data.FunctionDetail)
+	require.Nil(t, err)
+	edit, err = DetailChanges(function, others, entries)
+	require.Nil(t, err)
+	require.Len(t, edit.Updated, 1)
+	assert.Equal(t, "hello", edit.Updated[0].Key)
+	assert.Equal(t, []string{
+		"~ function greet → hello",
+		"    - echo hi",
+		"    + echo hello",
+		"      echo there",
+		"    + echo again",
+	}, Describe(edit, others))
+
+	secret := data.Detail{ID: 7, Key: "TOKEN", Value: "abc", DetailType: data.EnvDetail, Secret: true}
+	entries, err = Parse("TOKEN=def", data.EnvDetail)
+	require.Nil(t, err)
+	edit, err = DetailChanges(secret, nil, entries)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"~ env TOKEN: secret value changed"}, Describe(edit, []data.Detail{secret}))
+
+	for text, message := range map[string]string{
+		"":                     "expected a single KEY=value line for greet, found 0",
+		"a=x\nb=y":             "expected a single KEY=value line for greet, found 2",
+		"[env]\ngreet=x":       "line 2: greet can't be turned into a env",
+		"other='echo'":         "line 1: other is already taken by function other",
+		"greet='echo \"$@\"' ": "",
+	} {
+		entries, err := Parse(text, data.FunctionDetail)
+		require.Nil(t, err)
+		_, err = DetailChanges(function, others, entries)
+		if message == "" {
+			assert.Nil(t, err, text)
+			continue
+		}
+		assert.EqualError(t, err, message, text)
+	}
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+type ExportProfileRepository interface {
+	GetDetailsByProfileName(name string) ([]data.Detail, error)
+	RevealValue(detail data.Detail) (string, error)
+}
+
+// ExportRepository also looks up profiles by name, for ExportProfile
+type ExportRepository interface {
+	ExportProfileRepository
+	GetProfileByName(name string) (data.Profile, error)
+}
+
+type exportedDetail struct {
+	Key         string   `json:"key"`
+	Value       string   `json:"value"`
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Kind        string   `json:"kind,omitempty"`
+	Secret      bool     `json:"secret,omitempty"`
+	Position    string   `json:"position,omitempty"`
+	Separator   string   `json:"separator,omitempty"`
+	Guard       bool     `json:"guard,omitempty"`
+	Shells      []string `json:"shells,omitempty"`
+}
+
+type exportedProfile struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
+	Details     []exportedDetail `json:"details"`
+}
+
+// ExportProfile writes the details of a profile as json. Secret details are left
+// out unless includeSecrets is set, in which case they are written decrypted.
+func ExportProfile(w io.Writer, profileName string, includeSecrets bool, repository ExportRepository) error {
+	if profileName == "" {
+		return errors.New("profile name is required for export")
+	}
+	stored, err := repository.GetProfileByName(profileName)
+	if err != nil {
+		return err
+	}
+	details, err := repository.GetDetailsByProfileName(profileName)
+	if err != nil {
+		return err
+	}
+	profile, err := exportDetails(stored, details, includeSecrets, repository)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(profile)
+}
+
+// ExportDetails writes the passed details of a profile, in the same format as
+// ExportProfile.
+func ExportDetails(w io.Writer, profile data.Profile, details []data.Detail, includeSecrets bool, repository ExportProfileRepository) error {
+	exported, err := exportDetails(profile, details, includeSecrets, repository)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exported)
+}
+
+// ExportProfiles writes several profiles as a json array of the objects written by
+// ExportProfile.
+func ExportProfiles(w io.Writer, profiles []data.Profile, includeSecrets bool, repository ExportProfileRepository) error {
+	exported := []exportedProfile{}
+	for _, profile := range profiles {
+		details, err := repository.GetDetailsByProfileName(profile.Name)
+		if err != nil {
+			return err
+		}
+		written, err := exportDetails(profile, details, includeSecrets, repository)
+		if err != nil {
+			return err
+		}
+		exported = append(exported, written)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exported)
+}
+
+func exportDetails(stored data.Profile, details []data.Detail, includeSecrets bool, repository ExportProfileRepository) (exportedProfile, error) {
+	profile := exportedProfile{Name: stored.Name, Description: stored.Description, Tags: stored.Tags, Details: []exportedDetail{}}
+	for _, detail := range details {
+		if detail.Secret && !includeSecrets {
+			continue
+		}
+		value, err := repository.RevealValue(detail)
+		if err != nil {
+			return profile, fmt.Errorf("unable to reveal %s: %w", detail.Key, err)
+		}
+		var kind string
+		if detail.Kind != data.LiteralValue {
+			kind = detail.Kind.String()
+		}
+		exported := exportedDetail{
+			Key:         detail.Key,
+			Value:       value,
+			Type:        detail.DetailType.String(),
+			Description: detail.Description,
+			Kind:        kind,
+			Secret:      detail.Secret,
+		}
+		switch detail.DetailType {
+		case data.PathDetail:
+			exported.Position = detail.Position.String()
+			exported.Separator = detail.Separator
+		case data.SourceDetail:
+			exported.Guard = detail.Guard
+		case data.RawDetail:
+			exported.Shells = detail.Shells
+		}
+		profile.Details = append(profile.Details, exported)
+	}
+	return profile, nil
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+type exportRepositoryStub struct {
+	profile data.Profile
+	details []data.Detail
+	err     error
+}
+
+func (e exportRepositoryStub) GetProfileByName(name string) (data.Profile, error) {
+	if e.profile.Name != name {
+		return data.Profile{}, data.ErrProfileNotFound
+	}
+	return e.profile, nil
+}
+
+func (e exportRepositoryStub) GetDetailsByProfileName(profileName string) ([]data.Detail, error) {
+	return e.details, e.err
+}
+
+func (e exportRepositoryStub) RevealValue(detail data.Detail) (string, error) {
+	if detail.Secret {
+		return "plain", nil
+	}
+	return detail.Value, nil
+}
+
+func TestExportProfile(t *testing.T) {
+	details := []data.Detail{
+		{Key: "env_key", Value: "env_value", DetailType: data.EnvDetail},
+		{Key: "token", Value: "sealed", DetailType: data.EnvDetail, Secret: true},
+	}
+	testcases := []struct {
+		name           string
+		includeSecrets bool
+		res            string
+	}{
+		{
+			name: "secrets are skipped by default",
+			res:  "{\n  \"name\": \"test\",\n  \"details\": [\n    {\n      \"key\": \"env_key\",\n      \"value\": \"env_value\",\n      \"type\": \"env\"\n    }\n  ]\n}\n",
+		},
+		{
+			name:           "secrets are written decrypted when included",
+			includeSecrets: true,
+			res:            "{\n  \"name\": \"test\",\n  \"details\": [\n    {\n      \"key\": \"env_key\",\n      \"value\": \"env_value\",\n      \"type\": \"env\"\n    },\n    {\n      \"key\": \"token\",\n      \"value\": \"plain\",\n      \"type\": \"env\",\n      \"secret\": true\n    }\n  ]\n}\n",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := ExportProfile(&b, "test", testcase.includeSecrets, exportRepositoryStub{profile: data.Profile{Name: "test"}, details: details})
+			assert.Nil(t, err)
+			assert.Equal(t, testcase.res, b.String())
+		})
+	}
+}
+
+func TestExportProfiles(t *testing.T) {
+	repository := exportRepositoryStub{details: []data.Detail{{Key: "k", Value: "kubectl", DetailType: data.AliasDetail}}}
+	var b bytes.Buffer
+	err := ExportProfiles(&b, []data.Profile{{Name: "work"}, {Name: "home"}}, false, repository)
+	assert.Nil(t, err)
+	profile := "{\n    \"name\": \"%s\",\n    \"details\": [\n      {\n        \"key\": \"k\",\n        \"value\": \"kubectl\",\n        \"type\": \"alias\"\n      }\n    ]\n  }"
+	assert.Equal(t, "[\n  "+fmt.Sprintf(profile, "work")+",\n  "+fmt.Sprintf(profile, "home")+"\n]\n", b.String())
+}
+
+func TestExportDescriptions(t *testing.T) {
+	repository := exportRepositoryStub{
+		profile: data.Profile{Name: "legacy", Description: "old node services", Tags: []string{"node"}},
+		details: []data.Detail{{Key: "NODE_OPTIONS", Value: "--openssl-legacy-provider", DetailType: data.EnvDetail, Description: "webpack 4 needs md4"}},
+	}
+	var b bytes.Buffer
+	err := ExportProfile(&b, "legacy", false, repository)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"name\": \"legacy\",\n  \"description\": \"old node services\",\n  \"tags\": [\n    \"node\"\n  ],\n  \"details\": [\n    {\n      \"key\": \"NODE_OPTIONS\",\n      \"value\": \"--openssl-legacy-provider\",\n      \"type\": \"env\",\n      \"description\": \"webpack 4 needs md4\"\n    }\n  ]\n}\n", b.String())
+
+	err = ExportProfile(&b, "missing", false, repository)
+	assert.ErrorIs(t, err, data.ErrProfileNotFound)
+}
@@ -0,0 +1,278 @@
+package generate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	addedColor   = lipgloss.Color("#04b575")
+	changedColor = lipgloss.Color("#ffd866")
+	failedColor  = lipgloss.Color("#ff6188")
+	mutedColor   = lipgloss.Color("241")
+)
+
+// DiffOptions tune how a profile is compared with the current shell.
+type DiffOptions struct {
+	Options
+	// Aliases is the output of `alias` in the current shell. Aliases are not compared
+	// when nil.
+	Aliases io.Reader
+}
+
+// DiffProfile compares what the profile would set with the current environment and
+// writes a line per env, path entry and alias. It reports whether anything differs.
+func DiffProfile(w io.Writer, profileName string, repository GenerateProfileRepository, opts DiffOptions) (bool, error) {
+	if profileName == "" {
+		return false, fmt.Errorf("profile name is required for diff")
+	}
+	var aliases map[string]string
+	if opts.Aliases != nil {
+		var err error
+		aliases, err = ParseAliasTable(opts.Aliases)
+		if err != nil {
+			return false, err
+		}
+	}
+	traces, err := trace(repository, newEvaluator(opts.Options), os.LookupEnv, aliases, profileName)
+	if err != nil {
+		return false, err
+	}
+	return writeDiff(w, traces)
+}
+
+func writeDiff(w io.Writer, traces []Trace) (bool, error) {
+	renderer := lipgloss.NewRenderer(w)
+	added := renderer.NewStyle().Foreground(addedColor)
+	changed := renderer.NewStyle().Foreground(changedColor)
+	failed := renderer.NewStyle().Foreground(failedColor)
+	unchanged := renderer.NewStyle().Foreground(mutedColor)
+	mask := func(value string, secret bool) string {
+		if secret {
+			return data.SecretMask
+		}
+		return value
+	}
+
+	var b strings.Builder
+	var addedCount, changedCount, unchangedCount, failedCount int
+	for _, t := range traces {
+		var setting string
+		switch t.Type {
+		case data.EnvDetail:
+			setting = fmt.Sprintf("%s=%s", t.Key, mask(t.Value, t.Secret))
+		case data.PathDetail:
+			setting = fmt.Sprintf("%s += %s", t.Key, mask(t.Value, t.Secret))
+		case data.AliasDetail:
+			setting = fmt.Sprintf("alias %s=%s", t.Key, mask(t.Value, t.Secret))
+		default:
+			continue
+		}
+		if t.Err != nil {
+			failedCount++
+			b.WriteString(failed.Render(fmt.Sprintf("! %s: %s", t.Key, t.Err)) + "\n")
+			continue
+		}
+		switch t.Current {
+		case CurrentUnset:
+			addedCount++
+			b.WriteString(added.Render("+ "+setting) + "\n")
+		case CurrentDiffers:
+			changedCount++
+			current := mask(t.CurrentValue, t.Secret)
+			if t.Type == data.PathDetail {
+				current = "entry missing"
+			}
+			b.WriteString(changed.Render(fmt.Sprintf("~ %s (currently %s)", setting, current)) + "\n")
+		case CurrentMatches:
+			unchangedCount++
+			b.WriteString(unchanged.Render("  "+setting) + "\n")
+		case CurrentUnknown:
+			// secrets and values built from them are not revealed to compare them
+			if t.Type != data.AliasDetail {
+				b.WriteString(unchanged.Render(fmt.Sprintf("? %s (not compared)", setting)) + "\n")
+			}
+		}
+	}
+	fmt.Fprintf(&b, "%d added, %d changed, %d unchanged", addedCount, changedCount, unchangedCount)
+	if failedCount > 0 {
+		fmt.Fprintf(&b, ", %d unresolved", failedCount)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return addedCount+changedCount+failedCount > 0, err
+}
+
+// ParseAliasTable reads the output of `alias` from bash (`alias ll='ls -l'`), zsh
+// (`ll='ls -l'`) or fish (`alias ll 'ls -l'`) into alias values.
+func ParseAliasTable(r io.Reader) (map[string]string, error) {
+	aliases := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		line = strings.TrimPrefix(line, "alias ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.ContainsAny(name, " \t") {
+			// fish separates the name with a space
+			name, value, _ = strings.Cut(line, " ")
+			if wrapped, ok := strings.CutPrefix(value, "--wraps "); ok {
+				_, value, _ = strings.Cut(wrapped, " ")
+			}
+		}
+		aliases[strings.TrimSpace(name)] = ShellUnquote(strings.TrimSpace(value))
+	}
+	return aliases, scanner.Err()
+}
+
+// ShellUnquote removes the quoting from a single shell word the way the shell would,
+// e.g. `'ls -l'` or `"a \"b\""`.
+func ShellUnquote(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\'':
+			end := strings.IndexByte(value[i+1:], '\'')
+			if end < 0 {
+				b.WriteString(value[i+1:])
+				return b.String()
+			}
+			b.WriteString(value[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			for i++; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) && strings.IndexByte("\\\"$`", value[i+1]) >= 0 {
+					i++
+				}
+				b.WriteByte(value[i])
+			}
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				b.WriteByte(value[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// shellValue returns what the shell assigns for a value written unquoted after `NAME=`:
+// quotes are removed, `$NAME` and `${NAME}` are expanded and a leading `~` is the home
+// directory. ok is false when the value needs more of the shell than that, e.g. a
+// command substitution.
+func shellValue(value string, lookupEnv func(string) (string, bool)) (string, bool) {
+	var b strings.Builder
+	if rest, found := strings.CutPrefix(value, "~"); found && (rest == "" || rest[0] == '/') {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		b.WriteString(homeDir)
+		value = rest
+	}
+	// parameter reads the name after a `$` at value[i] and reports where it ends
+	parameter := func(i int) (int, bool) {
+		rest := value[i+1:]
+		braced := strings.HasPrefix(rest, "{")
+		if braced {
+			rest = rest[1:]
+		}
+		end := 0
+		for end < len(rest) && (rest[end] == '_' || isAlpha(rest[end]) || end > 0 && isDigit(rest[end])) {
+			end++
+		}
+		if end == 0 {
+			// a lone `$` is kept, anything else such as `$(` or `$1` is not handled
+			if !braced && (rest == "" || strings.IndexByte(" \t\"", rest[0]) >= 0) {
+				b.WriteByte('$')
+				return i, true
+			}
+			return 0, false
+		}
+		if braced {
+			if end == len(rest) || rest[end] != '}' {
+				return 0, false
+			}
+			current, _ := lookupEnv(rest[:end])
+			b.WriteString(current)
+			return i + end + 2, true
+		}
+		current, _ := lookupEnv(rest[:end])
+		b.WriteString(current)
+		return i + end, true
+	}
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\'':
+			end := strings.IndexByte(value[i+1:], '\'')
+			if end < 0 {
+				return "", false
+			}
+			b.WriteString(value[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			closed := false
+			for i++; i < len(value); i++ {
+				switch value[i] {
+				case '"':
+					closed = true
+				case '\\':
+					if i+1 < len(value) && strings.IndexByte("\\\"$`", value[i+1]) >= 0 {
+						i++
+					}
+					b.WriteByte(value[i])
+				case '$':
+					next, ok := parameter(i)
+					if !ok {
+						return "", false
+					}
+					i = next
+				case '`':
+					return "", false
+				default:
+					b.WriteByte(value[i])
+				}
+				if closed {
+					break
+				}
+			}
+			if !closed {
+				return "", false
+			}
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				b.WriteByte(value[i])
+			}
+		case '$':
+			next, ok := parameter(i)
+			if !ok {
+				return "", false
+			}
+			i = next
+		case '`', ';', '&', '|', '<', '>', '(', ')', ' ', '\t':
+			return "", false
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), true
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
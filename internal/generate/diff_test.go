@@ -0,0 +1,146 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellUnquote(t *testing.T) {
+	testcases := []struct {
+		value string
+		res   string
+	}{
+		{value: "git", res: "git"},
+		{value: "'ls -l'", res: "ls -l"},
+		{value: `'it'\''s'`, res: "it's"},
+		{value: `"say \"hi\" to $USER"`, res: `say "hi" to $USER`},
+		{value: `ls\ -l`, res: "ls -l"},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.res, ShellUnquote(tc.value), tc.value)
+	}
+}
+
+func TestShellValue(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "USER": "me"}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	testcases := []struct {
+		value string
+		res   string
+		ok    bool
+	}{
+		{value: "prod", res: "prod", ok: true},
+		{value: `"a b"`, res: "a b", ok: true},
+		{value: `'$USER'`, res: "$USER", ok: true},
+		{value: "$HOME/bin", res: "/home/me/bin", ok: true},
+		{value: `"${USER}_dev"`, res: "me_dev", ok: true},
+		{value: `"say \"hi\" to $USER"`, res: `say "hi" to me`, ok: true},
+		{value: "$MISSING", res: "", ok: true},
+		{value: "cost$", res: "cost$", ok: true},
+		{value: "~/cache", res: "/home/me/cache", ok: true},
+		{value: "$(date)", ok: false},
+		{value: "`date`", ok: false},
+		{value: "${USER:-me}", ok: false},
+		{value: "a b", ok: false},
+		{value: `"open`, ok: false},
+	}
+	t.Setenv("HOME", "/home/me")
+	for _, tc := range testcases {
+		res, ok := shellValue(tc.value, lookupEnv)
+		assert.Equal(t, tc.ok, ok, tc.value)
+		assert.Equal(t, tc.res, res, tc.value)
+	}
+}
+
+func TestParseAliasTable(t *testing.T) {
+	table := strings.Join([]string{
+		"alias ll='ls -l'",
+		"g=git",
+		"gs='git status --short'",
+		"alias q 'exit 0'",
+		"alias e --wraps nvim nvim",
+		"",
+	}, "\n")
+	aliases, err := ParseAliasTable(strings.NewReader(table))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"ll": "ls -l",
+		"g":  "git",
+		"gs": "git status --short",
+		"q":  "exit 0",
+		"e":  "nvim",
+	}, aliases)
+}
+
+func TestWriteDiff(t *testing.T) {
+	traces := []Trace{
+		{Key: "AWS_PROFILE", Type: data.EnvDetail, Value: "prod", Current: CurrentDiffers, CurrentValue: "dev"},
+		{Key: "CLUSTER", Type: data.EnvDetail, Value: "prod", Current: CurrentUnset},
+		{Key: "TOKEN", Type: data.EnvDetail, Value: "abc", Secret: true, Current: CurrentMatches, CurrentValue: "abc"},
+		{Key: "PATH", Type: data.PathDetail, Value: "/opt/bin", Current: CurrentDiffers, CurrentValue: "/usr/bin"},
+		{Key: "k", Type: data.AliasDetail, Value: "kubectl", Current: CurrentMatches, CurrentValue: "kubectl"},
+		{Key: "u", Type: data.AliasDetail, Value: "uptime", Current: CurrentUnknown},
+		{Key: "greet", Type: data.FunctionDetail, Value: "echo hi", Current: CurrentUnknown},
+		{Key: "BROKEN", Type: data.EnvDetail, Err: errors.New("BROKEN references undefined variable MISSING")},
+		{Key: "API_KEY", Type: data.EnvDetail, Value: data.SecretMask, Secret: true, Current: CurrentUnknown},
+	}
+	var b bytes.Buffer
+	differs, err := writeDiff(&b, traces)
+	assert.Nil(t, err)
+	assert.True(t, differs)
+	assert.Equal(t, `~ AWS_PROFILE=prod (currently dev)
++ CLUSTER=prod
+  TOKEN=<secret>
+~ PATH += /opt/bin (currently entry missing)
+  alias k=kubectl
+! BROKEN: BROKEN references undefined variable MISSING
+? API_KEY=<secret> (not compared)
+1 added, 2 changed, 2 unchanged, 1 unresolved
+`, b.String())
+
+	b.Reset()
+	differs, err = writeDiff(&b, traces[2:3])
+	assert.Nil(t, err)
+	assert.False(t, differs)
+}
+
+func TestDiffProfileApplied(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("GREETING", "a b")
+	t.Setenv("BIN", "/home/me/bin")
+	repository := multiProfileRepositoryStub{"work": {
+		{Key: "GREETING", Value: `"a b"`, DetailType: data.EnvDetail},
+		{Key: "BIN", Value: "$HOME/bin", DetailType: data.EnvDetail},
+	}}
+	var b bytes.Buffer
+	differs, err := DiffProfile(&b, "work", repository, DiffOptions{})
+	assert.Nil(t, err)
+	// maggi diff exits with 0
+	assert.False(t, differs)
+	assert.Equal(t, "  GREETING=a b\n  BIN=/home/me/bin\n0 added, 0 changed, 2 unchanged\n", b.String())
+}
+
+func TestDiffAliases(t *testing.T) {
+	repository := multiProfileRepositoryStub{"work": {
+		{Key: "ll", Value: "'ls -l'", DetailType: data.AliasDetail},
+		{Key: "g", Value: "git", DetailType: data.AliasDetail},
+		{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+	}}
+	aliases, err := ParseAliasTable(strings.NewReader("alias ll='ls -l'\nalias g='git status'\n"))
+	assert.Nil(t, err)
+	traces, err := trace(repository, newEvaluator(Options{}), func(string) (string, bool) { return "", false }, aliases, "work")
+	assert.Nil(t, err)
+	var states []CurrentState
+	for _, t := range traces {
+		states = append(states, t.Current)
+	}
+	assert.Equal(t, []CurrentState{CurrentMatches, CurrentDiffers, CurrentUnset}, states)
+}
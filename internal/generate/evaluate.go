@@ -0,0 +1,185 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+const (
+	DefaultEvalTimeout = 5 * time.Second
+	cacheDirPath       = ".maggi/cache"
+)
+
+// Options tune how dynamic values are resolved during generate.
+type Options struct {
+	// Timeout is applied to each `cmd:` value separately.
+	Timeout time.Duration
+	// CacheTTL caches the output of `cmd:` values on disk. Zero disables caching.
+	CacheTTL time.Duration
+	// Undo generates the commands that remove what the profiles apply.
+	Undo bool
+	// Shell the script is generated for. Detected from $SHELL when empty.
+	Shell Shell
+	// Annotate writes the descriptions of profiles and details as comments above
+	// what they apply.
+	Annotate bool
+	// Evaluate runs cmd: values and reads file: values in which and explain, so they
+	// are compared with the current environment. They are shown as entered otherwise.
+	Evaluate bool
+}
+
+func (o Options) shell() Shell {
+	if o.Shell == "" {
+		return DetectShell()
+	}
+	return o.Shell
+}
+
+type evaluator struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	cacheDir string
+	errOut   io.Writer
+	now      func() time.Time
+	// unevaluated leaves cmd: and file: values as entered instead of running or
+	// reading them
+	unevaluated bool
+}
+
+func newEvaluator(opts Options) *evaluator {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultEvalTimeout
+	}
+	var cacheDir string
+	if opts.CacheTTL > 0 {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			cacheDir = filepath.Join(homeDir, cacheDirPath)
+		}
+	}
+	return &evaluator{
+		timeout:  timeout,
+		cacheTTL: opts.CacheTTL,
+		cacheDir: cacheDir,
+		errOut:   os.Stderr,
+		now:      time.Now,
+	}
+}
+
+// EvaluateValue resolves a single value without going through the cache. Used by the
+// ui to test a dynamic value before relying on it in a shell.
+func EvaluateValue(kind data.ValueKind, value string, timeout time.Duration) (string, error) {
+	e := newEvaluator(Options{Timeout: timeout})
+	return e.evaluate(kind, value)
+}
+
+func (e *evaluator) evaluate(kind data.ValueKind, value string) (string, error) {
+	if e.unevaluated {
+		return data.FormatValue(kind, value), nil
+	}
+	switch kind {
+	case data.CommandValue:
+		if cached, ok := e.readCache(value); ok {
+			return cached, nil
+		}
+		out, err := runCommand(value, e.timeout)
+		if err != nil {
+			return "", err
+		}
+		e.writeCache(value, out)
+		return out, nil
+	case data.FileValue:
+		return readValueFile(value)
+	default:
+		return value, nil
+	}
+}
+
+// the command's own stderr is captured so that nothing but the generated script
+// reaches stdout, which is expected to be passed to eval.
+func runCommand(command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// children of sh can keep the output pipes open after sh is killed
+	cmd.WaitDelay = 100 * time.Millisecond
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("command timed out after %s", timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+func readValueFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(homeDir, rest)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func (e *evaluator) cachePath(command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return filepath.Join(e.cacheDir, hex.EncodeToString(sum[:]))
+}
+
+func (e *evaluator) readCache(command string) (string, bool) {
+	if e.cacheTTL <= 0 || e.cacheDir == "" {
+		return "", false
+	}
+	path := e.cachePath(command)
+	info, err := os.Stat(path)
+	if err != nil || e.now().Sub(info.ModTime()) > e.cacheTTL {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// cache is best effort. a failed write only means the command runs again next time
+func (e *evaluator) writeCache(command string, out string) {
+	if e.cacheTTL <= 0 || e.cacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(e.cacheDir, 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(e.cachePath(command), []byte(out), 0600)
+}
+
+// shellQuote wraps a value in single quotes so evaluated output is never
+// interpreted by the shell running eval.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,100 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	dir := t.TempDir()
+	valueFile := filepath.Join(dir, "value")
+	assert.Nil(t, os.WriteFile(valueFile, []byte("from file\n"), 0600))
+
+	testcases := []struct {
+		name    string
+		kind    data.ValueKind
+		value   string
+		timeout time.Duration
+		res     string
+		errMsg  string
+	}{
+		{
+			name:  "literal is returned as is",
+			kind:  data.LiteralValue,
+			value: "$HOME/bin",
+			res:   "$HOME/bin",
+		},
+		{
+			name:  "cmd output has trailing newline trimmed",
+			kind:  data.CommandValue,
+			value: "printf 'a\\nb\\n'",
+			res:   "a\nb",
+		},
+		{
+			name:   "cmd stderr is part of the error",
+			kind:   data.CommandValue,
+			value:  "echo oops >&2; exit 1",
+			errMsg: "exit status 1: oops",
+		},
+		{
+			name:    "cmd exceeding timeout returns error",
+			kind:    data.CommandValue,
+			value:   "sleep 2",
+			timeout: 50 * time.Millisecond,
+			errMsg:  "command timed out after 50ms",
+		},
+		{
+			name:  "file content is read",
+			kind:  data.FileValue,
+			value: valueFile,
+			res:   "from file",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			res, err := EvaluateValue(testcase.kind, testcase.value, testcase.timeout)
+			if testcase.errMsg != "" {
+				assert.EqualError(t, err, testcase.errMsg)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, testcase.res, res)
+		})
+	}
+}
+
+func TestEvaluateCache(t *testing.T) {
+	now := time.Now()
+	evaluator := newEvaluator(Options{CacheTTL: time.Minute})
+	evaluator.cacheDir = t.TempDir()
+	evaluator.now = func() time.Time { return now }
+
+	command := "date +%s%N"
+	first, err := evaluator.evaluate(data.CommandValue, command)
+	assert.Nil(t, err)
+
+	t.Run("value within ttl comes from cache", func(t *testing.T) {
+		res, err := evaluator.evaluate(data.CommandValue, command)
+		assert.Nil(t, err)
+		assert.Equal(t, first, res)
+	})
+
+	t.Run("value past ttl is evaluated again", func(t *testing.T) {
+		evaluator.now = func() time.Time { return now.Add(2 * time.Minute) }
+		res, err := evaluator.evaluate(data.CommandValue, command)
+		assert.Nil(t, err)
+		assert.NotEqual(t, first, res)
+	})
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "'plain'", shellQuote("plain"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, "'$(rm -rf /)'", shellQuote("$(rm -rf /)"))
+}
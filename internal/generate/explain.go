@@ -0,0 +1,291 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+// CurrentState compares the value applied by maggi with the current environment.
+type CurrentState string
+
+const (
+	CurrentMatches CurrentState = "matches"
+	CurrentDiffers CurrentState = "differs"
+	CurrentUnset   CurrentState = "not set"
+	// functions and snippets live in the shell and cannot be checked from here, and
+	// aliases only when the alias table of the shell is passed in
+	CurrentUnknown CurrentState = "unknown"
+)
+
+// Layer is a value from an earlier profile that was replaced by a later one.
+type Layer struct {
+	Profile string
+	Value   string
+	Secret  bool
+}
+
+// Trace explains where the value applied for a key came from.
+type Trace struct {
+	Key     string
+	Type    data.DetailType
+	Profile string
+	Value   string
+	Secret  bool
+	// Err is set when the value could not be resolved. generate skips such keys.
+	Err          error
+	Overrides    []Layer
+	Current      CurrentState
+	CurrentValue string
+}
+
+// TraceSession resolves the profiles applied by `apply-session` the same way
+// GenerateForSession does, and returns a trace per applied detail along with the names
+// of the applied profiles. cmd: and file: values are only evaluated with opts.Evaluate.
+func TraceSession(defaultProfile string, repository GenerateProfileRepository, opts Options) ([]Trace, []string, error) {
+	profileNames, err := sessionProfileNames(defaultProfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	evaluator := newEvaluator(opts)
+	evaluator.unevaluated = !opts.Evaluate
+	traces, err := trace(repository, evaluator, os.LookupEnv, nil, profileNames...)
+	return traces, profileNames, err
+}
+
+// ExplainSession writes the traces for the given keys, or for every applied key when
+// none are passed. Keys not set by any profile are reported with their value from the
+// environment, if any.
+func ExplainSession(w io.Writer, defaultProfile string, keys []string, repository GenerateProfileRepository, opts Options) error {
+	traces, profileNames, err := TraceSession(defaultProfile, repository, opts)
+	if err != nil {
+		return err
+	}
+	return writeTraces(w, traces, profileNames, defaultProfile, keys, os.LookupEnv)
+}
+
+// aliases holds the alias table of the current shell, when known, to compare aliases
+// against. secrets are not revealed, so only where they come from is traced, and how a
+// secret or a value built from one compares with the environment is unknown. the same
+// goes for cmd: and file: values when the evaluator leaves them unevaluated.
+func trace(repository GenerateProfileRepository, evaluator *evaluator, lookupEnv func(string) (string, bool), aliases map[string]string, profileNames ...string) ([]Trace, error) {
+	details, err := collectDetails(repository, profileNames)
+	if err != nil {
+		return nil, err
+	}
+	details = validDetails(details, evaluator.errOut)
+
+	var envs []data.Detail
+	for _, sourced := range details {
+		if sourced.detail.DetailType == data.EnvDetail {
+			envs = append(envs, maskSecret(sourced.detail))
+		}
+	}
+	unknown := dependentKeys(envs, func(detail data.Detail) bool {
+		return detail.Secret || evaluator.unevaluated && isDynamic(detail.Kind)
+	})
+	resolver := &Resolver{lookupEnv: lookupEnv, evaluate: evaluator.evaluate}
+	resolved, errs := resolver.Resolve(envs)
+	failed := make(map[string]error, len(errs))
+	for _, keyErr := range errs {
+		failed[keyErr.Key] = keyErr.Err
+	}
+
+	// envs set earlier in the script are seen by the values written after them
+	applied := make(map[string]string, len(envs))
+	lookupApplied := func(name string) (string, bool) {
+		if value, ok := applied[name]; ok {
+			return value, true
+		}
+		return lookupEnv(name)
+	}
+	traces := make([]Trace, 0, len(details))
+	for _, sourced := range details {
+		detail := sourced.detail
+		t := Trace{
+			Key:     detail.Key,
+			Type:    detail.DetailType,
+			Profile: sourced.profile,
+			Value:   data.FormatValue(detail.Kind, detail.Value),
+			Secret:  detail.Secret,
+			Current: CurrentUnknown,
+		}
+		for _, overridden := range sourced.overrides {
+			t.Overrides = append(t.Overrides, Layer{
+				Profile: overridden.profile,
+				Value:   data.FormatValue(overridden.detail.Kind, overridden.detail.Value),
+				Secret:  overridden.detail.Secret,
+			})
+		}
+		switch detail.DetailType {
+		case data.EnvDetail:
+			if err, ok := failed[detail.Key]; ok {
+				t.Err = err
+				break
+			}
+			t.Value = resolved[detail.Key]
+			if unknown[detail.Key] {
+				break
+			}
+			if !quotesValue(detail) {
+				value, ok := shellValue(t.Value, lookupApplied)
+				if !ok {
+					// only the shell knows what it makes of the value
+					break
+				}
+				t.Value = value
+			}
+			applied[detail.Key] = t.Value
+			t.Current, t.CurrentValue = currentEnv(detail.Key, t.Value, lookupEnv)
+		case data.PathDetail:
+			if !detail.Secret {
+				t.Current, t.CurrentValue = currentPath(detail, lookupEnv)
+			}
+		case data.AliasDetail:
+			if detail.Secret || evaluator.unevaluated && isDynamic(detail.Kind) {
+				break
+			}
+			// the value as the shell reports it, which is what `alias` prints
+			if isDynamic(detail.Kind) {
+				t.Value, t.Err = evaluator.evaluate(detail.Kind, detail.Value)
+			} else {
+				t.Value = ShellUnquote(detail.Value)
+			}
+			if aliases != nil && t.Err == nil {
+				t.Current, t.CurrentValue = currentEnv(detail.Key, t.Value, func(name string) (string, bool) {
+					value, ok := aliases[name]
+					return value, ok
+				})
+			}
+		}
+		traces = append(traces, t)
+	}
+	return traces, nil
+}
+
+func isDynamic(kind data.ValueKind) bool {
+	return kind == data.CommandValue || kind == data.FileValue
+}
+
+// dependentKeys returns the keys of the envs that match, along with the envs that
+// reference them, directly or through other envs
+func dependentKeys(envs []data.Detail, match func(data.Detail) bool) map[string]bool {
+	keys := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, detail := range envs {
+			if keys[detail.Key] {
+				continue
+			}
+			if match(detail) || slices.ContainsFunc(References(detail.Value), func(ref string) bool { return keys[ref] }) {
+				keys[detail.Key] = true
+				changed = true
+			}
+		}
+	}
+	return keys
+}
+
+func currentEnv(key, value string, lookupEnv func(string) (string, bool)) (CurrentState, string) {
+	current, ok := lookupEnv(key)
+	switch {
+	case !ok:
+		return CurrentUnset, ""
+	case current == value:
+		return CurrentMatches, current
+	default:
+		return CurrentDiffers, current
+	}
+}
+
+// a path entry matches when it is anywhere in the current list
+func currentPath(detail data.Detail, lookupEnv func(string) (string, bool)) (CurrentState, string) {
+	current, ok := lookupEnv(detail.Key)
+	if !ok {
+		return CurrentUnset, ""
+	}
+	separator := detail.Separator
+	if separator == "" {
+		separator = data.DefaultPathSeparator
+	}
+	// the entry as the shell adds it, with ~/ and variables such as $HOME expanded
+	entry, ok := shellValue(`"`+pathEntry(detail.Value)+`"`, lookupEnv)
+	if !ok {
+		return CurrentUnknown, current
+	}
+	if slices.Contains(strings.Split(current, separator), entry) {
+		return CurrentMatches, current
+	}
+	return CurrentDiffers, current
+}
+
+func writeTraces(w io.Writer, traces []Trace, profileNames []string, defaultProfile string, keys []string, lookupEnv func(string) (string, bool)) error {
+	role := func(profile string) string {
+		if profile == defaultProfile {
+			return profile + " (default)"
+		}
+		return profile + " (session)"
+	}
+	mask := func(value string, secret bool) string {
+		if secret {
+			return data.SecretMask
+		}
+		return value
+	}
+
+	var b strings.Builder
+	found := make(map[string]bool)
+	for _, t := range traces {
+		if len(keys) > 0 && !slices.Contains(keys, t.Key) {
+			continue
+		}
+		found[t.Key] = true
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", t.Key, t.Type)
+		if t.Err != nil {
+			fmt.Fprintf(&b, "  value:     unresolved, skipped by generate: %s\n", t.Err)
+		} else {
+			fmt.Fprintf(&b, "  value:     %s\n", mask(t.Value, t.Secret))
+		}
+		fmt.Fprintf(&b, "  profile:   %s\n", role(t.Profile))
+		for _, layer := range t.Overrides {
+			fmt.Fprintf(&b, "  overrides: %s: %s\n", role(layer.Profile), mask(layer.Value, layer.Secret))
+		}
+		switch t.Current {
+		case CurrentMatches, CurrentUnset:
+			fmt.Fprintf(&b, "  current:   %s\n", t.Current)
+		case CurrentDiffers:
+			fmt.Fprintf(&b, "  current:   %s: %s\n", t.Current, mask(t.CurrentValue, t.Secret))
+		case CurrentUnknown:
+			// aliases and functions are never compared here, so only envs say so
+			if t.Err == nil && (t.Type == data.EnvDetail || t.Type == data.PathDetail) {
+				fmt.Fprintf(&b, "  current:   %s\n", t.Current)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		if found[key] {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		applied := "no profile is applied"
+		if len(profileNames) > 0 {
+			applied = "not set by " + strings.Join(profileNames, ", ")
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, applied)
+		if current, ok := lookupEnv(key); ok {
+			fmt.Fprintf(&b, "  current:   %s, set outside maggi\n", current)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
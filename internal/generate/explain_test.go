@@ -0,0 +1,172 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	repository := multiProfileRepositoryStub{
+		"default": {
+			{Key: "AWS_PROFILE", Value: "dev", DetailType: data.EnvDetail},
+			{Key: "CLUSTER", Value: "dev", DetailType: data.EnvDetail},
+			{Key: "TOKEN", Value: "default-token", DetailType: data.EnvDetail, Secret: true},
+			{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+			{Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail},
+		},
+		"session": {
+			{Key: "AWS_PROFILE", Value: "prod", DetailType: data.EnvDetail},
+			{Key: "KUBECONFIG", Value: "/kube/${CLUSTER}", DetailType: data.EnvDetail},
+			{Key: "TOKEN", Value: "session-token", DetailType: data.EnvDetail, Secret: true},
+			{Key: "BROKEN", Value: "${MISSING}", DetailType: data.EnvDetail},
+		},
+	}
+	env := map[string]string{
+		"AWS_PROFILE": "prod",
+		"CLUSTER":     "staging",
+		"TOKEN":       "other-token",
+		"PATH":        "/usr/bin:/opt/bin",
+		"EDITOR":      "vim",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	var errOut bytes.Buffer
+	evaluator := newEvaluator(Options{})
+	evaluator.errOut = &errOut
+	traces, err := trace(repository, evaluator, lookupEnv, nil, "default", "session")
+	assert.Nil(t, err)
+	assert.Equal(t, Trace{
+		Key:          "AWS_PROFILE",
+		Type:         data.EnvDetail,
+		Profile:      "session",
+		Value:        "prod",
+		Overrides:    []Layer{{Profile: "default", Value: "dev"}},
+		Current:      CurrentMatches,
+		CurrentValue: "prod",
+	}, traces[0])
+
+	var b bytes.Buffer
+	err = writeTraces(&b, traces, []string{"default", "session"}, "default", []string{"AWS_PROFILE", "CLUSTER", "KUBECONFIG", "TOKEN", "k", "PATH", "BROKEN", "EDITOR", "PAGER"}, lookupEnv)
+	assert.Nil(t, err)
+	assert.Equal(t, `AWS_PROFILE (env)
+  value:     prod
+  profile:   session (session)
+  overrides: default (default): dev
+  current:   matches
+
+CLUSTER (env)
+  value:     dev
+  profile:   default (default)
+  current:   differs: staging
+
+TOKEN (env)
+  value:     <secret>
+  profile:   session (session)
+  overrides: default (default): <secret>
+  current:   unknown
+
+k (alias)
+  value:     kubectl
+  profile:   default (default)
+
+PATH (path)
+  value:     /opt/bin
+  profile:   default (default)
+  current:   matches
+
+KUBECONFIG (env)
+  value:     /kube/dev
+  profile:   session (session)
+  current:   not set
+
+BROKEN (env)
+  value:     unresolved, skipped by generate: BROKEN references undefined variable MISSING
+  profile:   session (session)
+
+EDITOR: not set by default, session
+  current:   vim, set outside maggi
+
+PAGER: not set by default, session
+`, b.String())
+	assert.Empty(t, errOut.String())
+}
+
+func TestTraceShellValues(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	repository := multiProfileRepositoryStub{"work": {
+		{Key: "GREETING", Value: `"a b"`, DetailType: data.EnvDetail},
+		{Key: "BIN", Value: "$HOME/bin", DetailType: data.EnvDetail},
+		{Key: "CONFIG", Value: `"$BIN"/'it'\''s'`, DetailType: data.EnvDetail},
+		{Key: "CACHE", Value: "~/cache", DetailType: data.EnvDetail},
+		{Key: "TODAY", Value: "$(date)", DetailType: data.EnvDetail},
+	}}
+	env := map[string]string{
+		"HOME":     "/home/me",
+		"GREETING": "a b",
+		"BIN":      "/home/me/bin",
+		"CONFIG":   "/home/me/other",
+		"TODAY":    "Mon",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	traces, err := trace(repository, newEvaluator(Options{}), lookupEnv, nil, "work")
+	assert.Nil(t, err)
+	var values []string
+	var states []CurrentState
+	for _, t := range traces {
+		values = append(values, t.Value)
+		states = append(states, t.Current)
+	}
+	assert.Equal(t, []string{"a b", "/home/me/bin", "/home/me/bin/it's", "/home/me/cache", "$(date)"}, values)
+	assert.Equal(t, []CurrentState{CurrentMatches, CurrentMatches, CurrentDiffers, CurrentUnset, CurrentUnknown}, states)
+}
+
+func TestTraceWithoutRevealing(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	repository := profileRepositoryStub{
+		details: []data.Detail{
+			{Key: "TOKEN", Value: "sealed", DetailType: data.EnvDetail, Secret: true},
+			{Key: "AUTH", Value: "Bearer ${TOKEN}", DetailType: data.EnvDetail},
+			{Key: "ACCESS", Value: "touch " + marker, DetailType: data.EnvDetail, Kind: data.CommandValue},
+			{Key: "PATH", Value: "$HOME/bin", DetailType: data.PathDetail},
+		},
+		// nothing is revealed, so a missing key doesn't matter
+		revealErr: errors.New("no key"),
+	}
+	env := map[string]string{
+		"HOME":  "/home/me",
+		"TOKEN": "abc",
+		"AUTH":  "Bearer abc",
+		"PATH":  "/usr/bin:/home/me/bin",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	var errOut bytes.Buffer
+	evaluator := newEvaluator(Options{})
+	evaluator.errOut = &errOut
+	evaluator.unevaluated = true
+	traces, err := trace(repository, evaluator, lookupEnv, nil, "test")
+	assert.Nil(t, err)
+	assert.Empty(t, errOut.String())
+	var values []string
+	var states []CurrentState
+	for _, t := range traces {
+		values = append(values, t.Value)
+		states = append(states, t.Current)
+	}
+	assert.Equal(t, []string{data.SecretMask, "Bearer " + data.SecretMask, "cmd:touch " + marker, "$HOME/bin"}, values)
+	assert.Equal(t, []CurrentState{CurrentUnknown, CurrentUnknown, CurrentUnknown, CurrentMatches}, states)
+	assert.NoFileExists(t, marker)
+}
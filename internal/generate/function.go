@@ -0,0 +1,125 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Shell decides the syntax of the parts of the generated script that differ between
+// shells. Anything that is not fish is treated as POSIX.
+type Shell string
+
+const (
+	PosixShell Shell = "sh"
+	BashShell  Shell = "bash"
+	ZshShell   Shell = "zsh"
+	FishShell  Shell = "fish"
+)
+
+// ParseShell maps a shell name or path, like `/bin/zsh`, to a Shell.
+func ParseShell(name string) (Shell, error) {
+	switch shell := Shell(filepath.Base(name)); shell {
+	case PosixShell, BashShell, ZshShell, FishShell:
+		return shell, nil
+	case "dash", "ksh":
+		return PosixShell, nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q. use one of sh, bash, zsh or fish", name)
+	}
+}
+
+// DetectShell picks the shell from $SHELL, falling back to POSIX.
+func DetectShell() Shell {
+	shell, err := ParseShell(os.Getenv("SHELL"))
+	if err != nil {
+		return PosixShell
+	}
+	return shell
+}
+
+func posixFunctionDefinition(name, body string) string {
+	return fmt.Sprintf("%s() {\n%s\n}", name, strings.Trim(body, "\n"))
+}
+
+// functionExpr defines the function in the calling shell. the generated script is usually
+// passed to an unquoted `eval $(...)`, which would join the lines of the body and collapse
+// its whitespace, so for POSIX shells the definition is escaped for printf and evaluated
+// separately.
+func functionExpr(name, body string, shell Shell) string {
+	if shell == FishShell {
+		return fmt.Sprintf("function %s\n%s\nend\n", name, strings.Trim(body, "\n"))
+	}
+	return fmt.Sprintf(`eval "$(printf '%%b' '%s')";`, printfEscape(posixFunctionDefinition(name, body)))
+}
+
+func removeFunctionExpr(name string, shell Shell) string {
+	if shell == FishShell {
+		return fmt.Sprintf("functions -e %s;", name)
+	}
+	return fmt.Sprintf("unset -f %s 2>/dev/null;", name)
+}
+
+// aliases are functions in fish
+func unaliasExpr(name string, shell Shell) string {
+	if shell == FishShell {
+		return fmt.Sprintf("functions -e %s;", name)
+	}
+	return fmt.Sprintf("unalias %s 2>/dev/null;", name)
+}
+
+func unsetExpr(name string, shell Shell) string {
+	if shell == FishShell {
+		return fmt.Sprintf("set -e %s;", name)
+	}
+	return fmt.Sprintf("unset %s;", name)
+}
+
+// printfEscape escapes value for a single quoted `printf %b` argument, leaving no
+// whitespace or glob characters in the output.
+func printfEscape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\'', ' ', '*', '?', '[':
+			fmt.Fprintf(&b, `\0%03o`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// CheckFunction runs the function definition through `bash -n` and `zsh -n`, for the
+// shells that are installed. The error holds the shell's own message.
+func CheckFunction(name, body string) error {
+	definition := posixFunctionDefinition(name, body)
+	for _, shell := range []Shell{BashShell, ZshShell} {
+		path, err := exec.LookPath(string(shell))
+		if err != nil {
+			continue
+		}
+		var stderr bytes.Buffer
+		cmd := exec.Command(path, "-n")
+		cmd.Stdin = strings.NewReader(definition)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("%s: %s", shell, msg)
+			}
+			return fmt.Errorf("%s: %w", shell, err)
+		}
+	}
+	return nil
+}
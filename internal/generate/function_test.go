@@ -0,0 +1,60 @@
+package generate
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionExpr(t *testing.T) {
+	body := "\tmsg=\"hello  $1\"\n\tprintf '%s*\\n' \"$msg\" # it's [fine]?"
+	expr := functionExpr("greet", body, PosixShell)
+	for _, shell := range []string{"sh", "bash", "zsh"} {
+		if _, err := exec.LookPath(shell); err != nil {
+			continue
+		}
+		// same as running `eval $(maggi generate ...)` without quotes
+		cmd := exec.Command(shell, "-c", `eval $(printf '%s' "$MAGGI_SCRIPT"); greet world`)
+		cmd.Env = append(os.Environ(), "MAGGI_SCRIPT="+expr)
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, shell)
+		assert.Equal(t, "hello  world*\n", string(out), shell)
+	}
+}
+
+func TestFishFunctionExpr(t *testing.T) {
+	assert.Equal(t, "function greet\n  echo hi $argv\nend\n", functionExpr("greet", "  echo hi $argv\n", FishShell))
+	assert.Equal(t, "functions -e greet;", removeFunctionExpr("greet", FishShell))
+	assert.Equal(t, "unset -f greet 2>/dev/null;", removeFunctionExpr("greet", ZshShell))
+}
+
+func TestCheckFunction(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	assert.Nil(t, CheckFunction("greet", `echo "hi $1"`))
+	assert.NotNil(t, CheckFunction("greet", "if [ -n \"$1\" ]; then\necho hi"))
+}
+
+func TestParseShell(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+		shell Shell
+		err   bool
+	}{
+		{name: "plain name", input: "fish", shell: FishShell},
+		{name: "path from $SHELL", input: "/usr/bin/zsh", shell: ZshShell},
+		{name: "dash is posix", input: "/bin/dash", shell: PosixShell},
+		{name: "unknown shell", input: "tcsh", err: true},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			shell, err := ParseShell(testcase.input)
+			assert.Equal(t, testcase.err, err != nil)
+			assert.Equal(t, testcase.shell, shell)
+		})
+	}
+}
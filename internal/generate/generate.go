@@ -1,7 +1,9 @@
 package generate
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -11,68 +13,410 @@ import (
 
 type GenerateProfileRepository interface {
 	GetDetailsByProfileName(name string) ([]data.Detail, error)
+	RevealValue(detail data.Detail) (string, error)
 }
 
-func GenerateForProfile(profileName string, profileRepository GenerateProfileRepository) error {
+// ApplyRepository also records when profiles were applied, which GenerateForProfile
+// and GenerateForSession do after printing the script, and looks up the profiles for
+// annotated scripts and tags.
+type ApplyRepository interface {
+	GenerateProfileRepository
+	GetProfileByName(name string) (data.Profile, error)
+	GetProfilesByTag(tag string) ([]data.Profile, error)
+	MarkApplied(profileNames ...string) error
+}
+
+func GenerateForProfile(profileName string, profileRepository ApplyRepository, opts Options) error {
 	if profileName == "" {
 		return nil
 	}
+	return applyProfiles(profileRepository, opts, profileName)
+}
 
-	generatedStr, err := generate(profileRepository, profileName)
+// GenerateForTag applies every profile tagged with tag, ordered by name. profileName
+// is applied after them when set, so that it overrides what they set.
+func GenerateForTag(tag, profileName string, profileRepository ApplyRepository, opts Options) error {
+	tagged, err := profileRepository.GetProfilesByTag(tag)
+	if err != nil {
+		return err
+	}
+	var profileNames []string
+	for _, profile := range tagged {
+		if profile.Name != profileName {
+			profileNames = append(profileNames, profile.Name)
+		}
+	}
+	if profileName != "" {
+		profileNames = append(profileNames, profileName)
+	}
+	if len(profileNames) == 0 {
+		fmt.Fprintf(os.Stderr, "no profile is tagged %s\n", tag)
+		return nil
+	}
+	return applyProfiles(profileRepository, opts, profileNames...)
+}
+
+func applyProfiles(profileRepository ApplyRepository, opts Options, profileNames ...string) error {
+	var generatedStr string
+	var err error
+	if opts.Undo {
+		generatedStr, err = generateUndo(profileRepository, opts.shell(), profileNames...)
+	} else {
+		generatedStr, err = annotatedGenerate(profileRepository, opts, profileNames...)
+	}
 	if err != nil {
 		return err
 	}
 	fmt.Println(generatedStr)
 
-	return nil
+	if opts.Undo {
+		return nil
+	}
+	return profileRepository.MarkApplied(profileNames...)
 }
 
-func GenerateForSession(defaultProfile string, profileRepository GenerateProfileRepository) error {
-	var defaultDetails string
-	var profileDetails string
-	var err error
-	if defaultProfile != "" {
-		defaultDetails, err = generate(profileRepository, defaultProfile)
+func GenerateForSession(defaultProfile string, profileRepository ApplyRepository, opts Options) error {
+	profileNames, err := sessionProfileNames(defaultProfile)
+	if err != nil {
+		fmt.Println("")
+		return err
+	}
+
+	var generatedStr string
+	if opts.Undo {
+		generatedStr, err = generateUndo(profileRepository, opts.shell(), profileNames...)
+	} else {
+		generatedStr, err = annotatedGenerate(profileRepository, opts, profileNames...)
+	}
+	if err != nil {
+		fmt.Println("")
+		return err
+	}
+
+	fmt.Print(generatedStr)
+
+	if opts.Undo {
+		return nil
+	}
+	return profileRepository.MarkApplied(profileNames...)
+}
+
+// annotatedGenerate generates the script, led by the descriptions of the profiles when
+// opts.Annotate is set
+func annotatedGenerate(repository ApplyRepository, opts Options, profileNames ...string) (string, error) {
+	generated, err := generate(repository, newEvaluator(opts), opts.shell(), opts.Annotate, profileNames...)
+	if err != nil || !opts.Annotate {
+		return generated, err
+	}
+	var b strings.Builder
+	for _, profileName := range profileNames {
+		profile, err := repository.GetProfileByName(profileName)
+		// a tmux session doesn't need a profile of its own
+		if errors.Is(err, data.ErrProfileNotFound) {
+			continue
+		}
 		if err != nil {
-			fmt.Println("")
-			return err
+			return "", err
+		}
+		if profile.Description != "" {
+			writeComment(&b, profile.Name+": "+profile.Description)
 		}
 	}
+	return b.String() + generated, nil
+}
+
+// writeComment writes text as shell comments, one per line. the script is otherwise
+// written on a single line, so the comment starts on a new one.
+func writeComment(b *strings.Builder, text string) {
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(b, "# %s\n", strings.TrimSpace(line))
+	}
+}
+
+// sessionProfileNames returns the profiles applied for the current shell. The tmux
+// session profile comes last so that it overrides the default profile.
+func sessionProfileNames(defaultProfile string) ([]string, error) {
+	var profileNames []string
+	if defaultProfile != "" {
+		profileNames = append(profileNames, defaultProfile)
+	}
 	if tmuxEnv := os.Getenv("TMUX"); tmuxEnv != "" {
 		out, err := exec.Command("tmux", "display-message", "-p", "'#S'").Output()
 		if err != nil {
-			fmt.Println("")
-			return err
+			return nil, err
 		}
 		profileName := string(out)
 		profileName = strings.TrimSpace(profileName)
 		profileName = strings.Trim(profileName, "'")
 		profileName = strings.Trim(profileName, "\"")
-		profileDetails, err = generate(profileRepository, profileName)
+		profileNames = append(profileNames, profileName)
+	}
+	return profileNames, nil
+}
+
+type sourcedDetail struct {
+	detail  data.Detail
+	profile string
+	// overrides holds the details from earlier profiles this one replaced, oldest first
+	overrides []sourcedDetail
+}
+
+// collectDetails merges the details of the given profiles. A detail in a later profile
+// replaces the detail with the same key and type from an earlier one, keeping its place.
+// Path details are only replaced by the same entry, since a profile can add many entries
+// to one list. Secret values are left sealed, see revealDetails.
+func collectDetails(repository GenerateProfileRepository, profileNames []string) ([]sourcedDetail, error) {
+	var collected []sourcedDetail
+	positions := make(map[string]int)
+	for _, profileName := range profileNames {
+		details, err := repository.GetDetailsByProfileName(profileName)
 		if err != nil {
-			fmt.Println("")
-			return err
+			return nil, err
+		}
+		for _, detail := range details {
+			id := detail.DetailType.String() + ":" + detail.Key
+			if detail.DetailType == data.PathDetail {
+				id += ":" + detail.Value
+			}
+			if pos, ok := positions[id]; ok {
+				replaced := collected[pos]
+				overrides := append(replaced.overrides, sourcedDetail{detail: replaced.detail, profile: replaced.profile})
+				collected[pos] = sourcedDetail{detail: detail, profile: profileName, overrides: overrides}
+				continue
+			}
+			positions[id] = len(collected)
+			collected = append(collected, sourcedDetail{detail: detail, profile: profileName})
 		}
 	}
+	return collected, nil
+}
+
+// revealDetails reveals the secret values of the details. a secret that can't be
+// revealed, e.g. when no passphrase is set, is reported on errOut and skipped like a
+// value that fails to resolve, so the rest of the profiles still apply.
+func revealDetails(repository GenerateProfileRepository, details []sourcedDetail, errOut io.Writer) []sourcedDetail {
+	var revealed []sourcedDetail
+	for _, sourced := range details {
+		value, err := repository.RevealValue(sourced.detail)
+		if err != nil {
+			fmt.Fprintf(errOut, "maggi: unable to reveal %s in profile %s: %s\n", sourced.detail.Key, sourced.profile, err)
+			continue
+		}
+		sourced.detail.Value = value
+		revealed = append(revealed, sourced)
+	}
+	return revealed
+}
 
-	fmt.Printf("%s%s", defaultDetails, profileDetails)
+// maskSecret puts the mask in place of the value of a secret that is not revealed
+func maskSecret(detail data.Detail) data.Detail {
+	if detail.Secret {
+		detail.Value = data.SecretMask
+		detail.Kind = data.LiteralValue
+	}
+	return detail
+}
+
+// details saved before validation was added can still hold names that would break the
+// generated script, so they are reported and skipped.
+func validDetails(details []sourcedDetail, errOut io.Writer) []sourcedDetail {
+	var valid []sourcedDetail
+	for _, sourced := range details {
+		if err := data.CheckDetail(sourced.detail); err != nil {
+			fmt.Fprintf(errOut, "maggi: skipping %s in profile %s: %s\n", sourced.detail.Key, sourced.profile, strings.ReplaceAll(err.Error(), "\n", "; "))
+			continue
+		}
+		valid = append(valid, sourced)
+	}
+	return valid
+}
 
-	return nil
+// ScriptLine is what one detail adds to the generated script, comments included.
+// Joined, the text of the lines is the script. Warning says why a detail adds nothing,
+// or what to look at in a detail that is applied anyway.
+type ScriptLine struct {
+	Text       string
+	Key        string
+	DetailType data.DetailType
+	Profile    string
+	Warning    string
+	// Skipped details fail validation or don't resolve, and add no text
+	Skipped bool
 }
 
-func generate(repository GenerateProfileRepository, profileName string) (string, error) {
-	details, err := repository.GetDetailsByProfileName(profileName)
+// Preview returns the script `maggi generate --profile` prints for the profile, line by
+// line. Nothing is written to stderr and the profile is not marked as applied. Secrets
+// are not revealed but masked, and cmd: and file: values are shown as entered rather
+// than run or read, so those lines differ from what generate prints.
+func Preview(profileName string, repository GenerateProfileRepository, opts Options) ([]ScriptLine, error) {
+	details, err := collectDetails(repository, []string{profileName})
+	if err != nil {
+		return nil, err
+	}
+	for i, sourced := range details {
+		details[i].detail = maskSecret(sourced.detail)
+	}
+	evaluator := newEvaluator(opts)
+	evaluator.errOut = io.Discard
+	evaluator.unevaluated = true
+	return generateLines(details, evaluator, opts.shell(), opts.Annotate)
+}
+
+func generate(repository GenerateProfileRepository, evaluator *evaluator, shell Shell, annotate bool, profileNames ...string) (string, error) {
+	details, err := collectDetails(repository, profileNames)
+	if err != nil {
+		return "", err
+	}
+	lines, err := generateLines(revealDetails(repository, details, evaluator.errOut), evaluator, shell, annotate)
 	if err != nil {
 		return "", err
 	}
 	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line.Text)
+	}
+	return b.String(), nil
+}
 
-	for _, detail := range details {
+// values that fail to resolve are reported on stderr and skipped, so the rest of the
+// profile still applies cleanly when passed to eval. env values that were expanded or
+// evaluated by maggi are quoted, everything else is written as stored. path details
+// come after env, aliases and functions so they extend any list set by the profile,
+// followed by sourced files and then raw snippets, which can rely on all of the above.
+// with annotate, the description of a detail is written as a comment above it.
+func generateLines(details []sourcedDetail, evaluator *evaluator, shell Shell, annotate bool) ([]ScriptLine, error) {
+	var envs []data.Detail
+	origins := make(map[string]string)
+	for _, sourced := range validDetails(details, evaluator.errOut) {
+		if sourced.detail.DetailType == data.EnvDetail {
+			envs = append(envs, sourced.detail)
+			origins[sourced.detail.Key] = sourced.profile
+		}
+	}
+	resolver := &Resolver{lookupEnv: os.LookupEnv, evaluate: evaluator.evaluate}
+	resolved, errs := resolver.Resolve(envs)
+	unresolved := make(map[string]error)
+	for _, keyErr := range errs {
+		fmt.Fprintf(evaluator.errOut, "maggi: unable to resolve %s in profile %s: %s\n", keyErr.Key, origins[keyErr.Key], keyErr.Err)
+		unresolved[keyErr.Key] = keyErr.Err
+	}
+
+	var b strings.Builder
+	var lines []ScriptLine
+	write := func(sourced sourcedDetail) {
+		detail := sourced.detail
+		line := ScriptLine{Key: detail.Key, DetailType: detail.DetailType, Profile: sourced.profile}
+		skip := func(warning string) {
+			line.Warning = warning
+			line.Skipped = true
+			lines = append(lines, line)
+		}
+		if err := data.CheckDetail(detail); err != nil {
+			skip(strings.ReplaceAll(err.Error(), "\n", "; "))
+			return
+		}
+		var warnings []string
+		for _, problem := range data.ValidateDetail(detail) {
+			warnings = append(warnings, problem.Message)
+		}
+		line.Warning = strings.Join(warnings, "; ")
+
+		var expr string
 		switch detail.DetailType {
 		case data.AliasDetail:
-			fmt.Fprintf(&b, "alias %s=%s;", detail.Key, detail.Value)
+			value := detail.Value
+			if detail.Kind == data.CommandValue || detail.Kind == data.FileValue {
+				evaluated, err := evaluator.evaluate(detail.Kind, value)
+				if err != nil {
+					fmt.Fprintf(evaluator.errOut, "maggi: unable to resolve %s in profile %s: %s\n", detail.Key, sourced.profile, err)
+					skip("unable to resolve: " + err.Error())
+					return
+				}
+				value = shellQuote(evaluated)
+			}
+			expr = fmt.Sprintf("alias %s=%s;", detail.Key, value)
 		case data.EnvDetail:
-			fmt.Fprintf(&b, "export %s=%s;", detail.Key, detail.Value)
+			value, ok := resolved[detail.Key]
+			if !ok {
+				if err, ok := unresolved[detail.Key]; ok {
+					skip("unable to resolve: " + err.Error())
+				}
+				return
+			}
+			if quotesValue(detail) {
+				value = shellQuote(value)
+			}
+			expr = fmt.Sprintf("export %s=%s;", detail.Key, value)
+		case data.FunctionDetail:
+			expr = functionExpr(detail.Key, detail.Value, shell)
+		case data.PathDetail:
+			expr = applyPathExpr(detail, shell)
+		case data.SourceDetail:
+			expr = sourceExpr(detail, shell)
+		case data.RawDetail:
+			expr = rawExpr(detail, shell)
+		}
+		if expr == "" {
+			return
+		}
+		written := b.Len()
+		if annotate && detail.Description != "" {
+			writeComment(&b, detail.Description)
+		}
+		b.WriteString(expr)
+		line.Text = b.String()[written:]
+		lines = append(lines, line)
+	}
+	for _, sourced := range details {
+		switch sourced.detail.DetailType {
+		case data.AliasDetail, data.EnvDetail, data.FunctionDetail:
+			write(sourced)
+		}
+	}
+	for _, detailType := range []data.DetailType{data.PathDetail, data.SourceDetail, data.RawDetail} {
+		for _, sourced := range details {
+			if sourced.detail.DetailType == detailType {
+				write(sourced)
+			}
+		}
+	}
+	return lines, nil
+}
+
+// quotesValue reports whether generate quotes the resolved value of an env, so the shell
+// stores it as is. other values are written as entered and the shell unquotes and
+// expands them, e.g. `"a b"` or `$HOME/bin`.
+func quotesValue(detail data.Detail) bool {
+	return detail.Kind == data.CommandValue || detail.Kind == data.FileValue || HasInterpolation(detail.Value)
+}
+
+// generateUndo reverses what generate applies for the profiles, in reverse order. sourced
+// files and raw snippets cannot be reversed and are left alone.
+func generateUndo(repository GenerateProfileRepository, shell Shell, profileNames ...string) (string, error) {
+	details, err := collectDetails(repository, profileNames)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, detailType := range []data.DetailType{data.PathDetail, data.FunctionDetail, data.AliasDetail, data.EnvDetail} {
+		for _, sourced := range details {
+			detail := sourced.detail
+			if detail.DetailType != detailType {
+				continue
+			}
+			switch detail.DetailType {
+			case data.PathDetail:
+				b.WriteString(removePathExpr(detail, shell))
+			case data.FunctionDetail:
+				b.WriteString(removeFunctionExpr(detail.Key, shell))
+			case data.AliasDetail:
+				b.WriteString(unaliasExpr(detail.Key, shell))
+			case data.EnvDetail:
+				b.WriteString(unsetExpr(detail.Key, shell))
+			}
 		}
 	}
 	return b.String(), nil
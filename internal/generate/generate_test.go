@@ -1,6 +1,10 @@
 package generate
 
 import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/bento01dev/maggi/internal/data"
@@ -8,20 +12,34 @@ import (
 )
 
 type profileRepositoryStub struct {
-	details []data.Detail
-	err     error
+	details   []data.Detail
+	err       error
+	revealErr error
 }
 
 func (p profileRepositoryStub) GetDetailsByProfileName(profileName string) ([]data.Detail, error) {
 	return p.details, p.err
 }
 
+func (p profileRepositoryStub) RevealValue(detail data.Detail) (string, error) {
+	if !detail.Secret {
+		return detail.Value, nil
+	}
+	if p.revealErr != nil {
+		return "", p.revealErr
+	}
+	return "revealed_" + detail.Value, nil
+}
+
 func TestGenerate(t *testing.T) {
+	revealErr := errors.New("no key")
 	testcases := []struct {
-		name    string
-		details []data.Detail
-		err     error
-		res     string
+		name      string
+		details   []data.Detail
+		err       error
+		revealErr error
+		res       string
+		errOut    string
 	}{
 		{
 			name: "empty details should return empty string",
@@ -31,13 +49,248 @@ func TestGenerate(t *testing.T) {
 			details: []data.Detail{{Key: "test_env", Value: "test_env_value", DetailType: data.EnvDetail}, {Key: "test_alias", Value: "test_alias_value", DetailType: data.AliasDetail}},
 			res:     "export test_env=test_env_value;alias test_alias=test_alias_value;",
 		},
+		{
+			name:    "path details should be applied after exports and aliases",
+			details: []data.Detail{{Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail, Position: data.AppendPath}, {Key: "test_env", Value: "test_env_value", DetailType: data.EnvDetail}, {Key: "test_alias", Value: "test_alias_value", DetailType: data.AliasDetail}},
+			res:     "export test_env=test_env_value;alias test_alias=test_alias_value;" + `case ":${PATH}:" in *":/opt/bin:"*) ;; *) export PATH="${PATH:+${PATH}:}/opt/bin";; esac;`,
+		},
+		{
+			name:    "invalid names are reported and skipped",
+			details: []data.Detail{{Key: "MY-VAR", Value: "x", DetailType: data.EnvDetail}, {Key: "ok", Value: "y", DetailType: data.EnvDetail}},
+			res:     "export ok=y;",
+			errOut:  "maggi: skipping MY-VAR in profile test: \"MY-VAR\" is not a valid env var name. use letters, digits and _, not starting with a digit\n",
+		},
+		{
+			name:    "secret details should be revealed before export",
+			details: []data.Detail{{Key: "token", Value: "sealed", DetailType: data.EnvDetail, Secret: true}},
+			res:     "export token=revealed_sealed;",
+		},
+		{
+			name: "secrets that can't be revealed are reported on stderr and skipped",
+			details: []data.Detail{
+				{Key: "token", Value: "sealed", DetailType: data.EnvDetail, Secret: true},
+				{Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail},
+			},
+			revealErr: revealErr,
+			res:       "export EDITOR=vim;",
+			errOut:    "maggi: unable to reveal token in profile test: no key\n",
+		},
+		{
+			name:    "cmd values are evaluated and quoted",
+			details: []data.Detail{{Key: "token", Value: "echo \"it's\"", DetailType: data.EnvDetail, Kind: data.CommandValue}},
+			res:     `export token='it'\''s';`,
+		},
+		{
+			name: "failing cmd values are reported on stderr and skipped",
+			details: []data.Detail{
+				{Key: "broken", Value: "exit 3", DetailType: data.EnvDetail, Kind: data.CommandValue},
+				{Key: "test_env", Value: "test_env_value", DetailType: data.EnvDetail},
+			},
+			res:    "export test_env=test_env_value;",
+			errOut: "maggi: unable to resolve broken in profile test: exit status 3\n",
+		},
 	}
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			res, err := generate(profileRepositoryStub{testcase.details, testcase.err}, "")
+			var errOut bytes.Buffer
+			evaluator := newEvaluator(Options{})
+			evaluator.errOut = &errOut
+			res, err := generate(profileRepositoryStub{testcase.details, testcase.err, testcase.revealErr}, evaluator, PosixShell, false, "test")
 			assert.Equal(t, testcase.res, res)
 			assert.Equal(t, testcase.err, err)
+			assert.Equal(t, testcase.errOut, errOut.String())
 		})
 	}
 }
+
+func TestGenerateForFish(t *testing.T) {
+	details := []data.Detail{
+		{Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail, Position: data.AppendPath},
+		{Key: "AWS_PROFILE", Value: "prod", DetailType: data.EnvDetail},
+		{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+		{Key: "greet", Value: "echo hi", DetailType: data.FunctionDetail},
+	}
+	repository := profileRepositoryStub{details: details}
+	res, err := generate(repository, newEvaluator(Options{}), FishShell, false, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "export AWS_PROFILE=prod;alias k=kubectl;function greet\necho hi\nend\n"+
+		`contains -- "/opt/bin" (string split --no-empty -- ":" "$PATH"); or set -gx --path PATH (string split --no-empty -- ":" "$PATH") "/opt/bin";`, res)
+	assert.NotContains(t, res, "esac")
+
+	undo, err := generateUndo(repository, FishShell, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, removePathExpr(details[0], FishShell)+"functions -e greet;functions -e k;set -e AWS_PROFILE;", undo)
+	assert.NotContains(t, undo, "unset")
+}
+
+type multiProfileRepositoryStub map[string][]data.Detail
+
+func (m multiProfileRepositoryStub) GetDetailsByProfileName(profileName string) ([]data.Detail, error) {
+	return m[profileName], nil
+}
+
+func (m multiProfileRepositoryStub) RevealValue(detail data.Detail) (string, error) {
+	return detail.Value, nil
+}
+
+func TestGenerateForMultipleProfiles(t *testing.T) {
+	repository := multiProfileRepositoryStub{
+		"default": {
+			{Key: "CLUSTER", Value: "dev", DetailType: data.EnvDetail},
+			{Key: "KUBECONFIG", Value: "/kube/${CLUSTER}", DetailType: data.EnvDetail},
+			{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+		},
+		"session": {
+			{Key: "CLUSTER", Value: "prod", DetailType: data.EnvDetail},
+		},
+	}
+	var errOut bytes.Buffer
+	evaluator := newEvaluator(Options{})
+	evaluator.errOut = &errOut
+
+	res, err := generate(repository, evaluator, PosixShell, false, "default", "session")
+	assert.Nil(t, err)
+	assert.Equal(t, "export CLUSTER=prod;export KUBECONFIG='/kube/prod';alias k=kubectl;", res)
+	assert.Equal(t, "", errOut.String())
+}
+
+type applyRepositoryStub struct {
+	multiProfileRepositoryStub
+	profiles []data.Profile
+	applied  *[]string
+}
+
+func (a applyRepositoryStub) GetProfileByName(name string) (data.Profile, error) {
+	for _, profile := range a.profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return data.Profile{}, data.ErrProfileNotFound
+}
+
+func (a applyRepositoryStub) GetProfilesByTag(tag string) ([]data.Profile, error) {
+	var tagged []data.Profile
+	for _, profile := range a.profiles {
+		if slices.Contains(profile.Tags, tag) {
+			tagged = append(tagged, profile)
+		}
+	}
+	return tagged, nil
+}
+
+func (a applyRepositoryStub) MarkApplied(profileNames ...string) error {
+	if a.applied != nil {
+		*a.applied = append(*a.applied, profileNames...)
+	}
+	return nil
+}
+
+func TestAnnotatedGenerate(t *testing.T) {
+	repository := applyRepositoryStub{
+		multiProfileRepositoryStub: multiProfileRepositoryStub{
+			"legacy": {
+				{Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail},
+				{Key: "NODE_OPTIONS", Value: "--openssl-legacy-provider", DetailType: data.EnvDetail, Description: "webpack 4 needs md4\nuntil the upgrade"},
+				{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+			},
+		},
+		profiles: []data.Profile{{Name: "legacy", Description: "old node services"}},
+	}
+
+	res, err := annotatedGenerate(repository, Options{Shell: PosixShell, Annotate: true}, "legacy", "session")
+	assert.Nil(t, err)
+	assert.Equal(t, "# legacy: old node services\nexport EDITOR=vim;\n# webpack 4 needs md4\n# until the upgrade\nexport NODE_OPTIONS=--openssl-legacy-provider;alias k=kubectl;", res)
+
+	res, err = annotatedGenerate(repository, Options{Shell: PosixShell}, "legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, "export EDITOR=vim;export NODE_OPTIONS=--openssl-legacy-provider;alias k=kubectl;", res)
+}
+
+func TestGenerateForTag(t *testing.T) {
+	var applied []string
+	repository := applyRepositoryStub{
+		multiProfileRepositoryStub: multiProfileRepositoryStub{
+			"eu":   {{Key: "AWS_REGION", Value: "eu-west-1", DetailType: data.EnvDetail}},
+			"work": {{Key: "AWS_PROFILE", Value: "work", DetailType: data.EnvDetail}},
+			"home": {{Key: "AWS_REGION", Value: "us-east-1", DetailType: data.EnvDetail}},
+		},
+		profiles: []data.Profile{{Name: "eu", Tags: []string{"aws"}}, {Name: "work", Tags: []string{"aws", "k8s"}}, {Name: "home"}},
+		applied:  &applied,
+	}
+
+	assert.Nil(t, GenerateForTag("aws", "", repository, Options{Shell: PosixShell}))
+	assert.Equal(t, []string{"eu", "work"}, applied)
+
+	// the profile passed along comes last, once
+	applied = nil
+	assert.Nil(t, GenerateForTag("aws", "eu", repository, Options{Shell: PosixShell}))
+	assert.Equal(t, []string{"work", "eu"}, applied)
+	applied = nil
+	assert.Nil(t, GenerateForTag("aws", "home", repository, Options{Shell: PosixShell}))
+	assert.Equal(t, []string{"eu", "work", "home"}, applied)
+
+	applied = nil
+	assert.Nil(t, GenerateForTag("gcp", "", repository, Options{Shell: PosixShell}))
+	assert.Nil(t, applied)
+}
+
+func TestPreview(t *testing.T) {
+	repository := profileRepositoryStub{details: []data.Detail{
+		{Key: "MY-VAR", Value: "x", DetailType: data.EnvDetail},
+		{Key: "PATH", Value: "/bin", DetailType: data.EnvDetail},
+		{Key: "URL", Value: "${HOST_NOT_SET_ANYWHERE}/api", DetailType: data.EnvDetail},
+		{Key: "ll", Value: "ls -l", DetailType: data.AliasDetail},
+		{Key: "mkcd", Value: "mkdir -p \"$1\"\ncd \"$1\"", DetailType: data.FunctionDetail},
+	}}
+	lines, err := Preview("test", repository, Options{Shell: BashShell})
+	assert.Nil(t, err)
+	assert.Len(t, lines, 5)
+
+	assert.True(t, lines[0].Skipped)
+	assert.Equal(t, "", lines[0].Text)
+	assert.Contains(t, lines[0].Warning, "not a valid env var name")
+	// warnings don't stop a detail from being applied
+	assert.Equal(t, ScriptLine{Text: "export PATH=/bin;", Key: "PATH", DetailType: data.EnvDetail, Profile: "test", Warning: "setting PATH replaces it entirely. use a path entry to extend it instead"}, lines[1])
+	assert.True(t, lines[2].Skipped)
+	assert.Contains(t, lines[2].Warning, "unable to resolve")
+	assert.Equal(t, "alias ll=ls -l;", lines[3].Text)
+	assert.Equal(t, functionExpr("mkcd", "mkdir -p \"$1\"\ncd \"$1\"", BashShell), lines[4].Text)
+
+	// joined, the lines are what generate prints
+	evaluator := newEvaluator(Options{})
+	evaluator.errOut = &bytes.Buffer{}
+	generated, err := generate(repository, evaluator, BashShell, false, "test")
+	assert.Nil(t, err)
+	var joined string
+	for _, line := range lines {
+		joined += line.Text
+	}
+	assert.Equal(t, generated, joined)
+}
+
+func TestPreviewSecrets(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	repository := profileRepositoryStub{
+		details: []data.Detail{
+			{Key: "TOKEN", Value: "sealed", DetailType: data.EnvDetail, Secret: true},
+			{Key: "AUTH", Value: "Bearer ${TOKEN}", DetailType: data.EnvDetail},
+			{Key: "ACCESS", Value: "touch " + marker, DetailType: data.EnvDetail, Kind: data.CommandValue},
+		},
+		// secrets are never revealed, so a missing key doesn't matter
+		revealErr: errors.New("no key"),
+	}
+	lines, err := Preview("test", repository, Options{Shell: BashShell})
+	assert.Nil(t, err)
+	var texts []string
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+	assert.Equal(t, []string{
+		"export TOKEN=<secret>;",
+		"export AUTH='Bearer <secret>';",
+		"export ACCESS='cmd:touch " + marker + "';",
+	}, texts)
+	assert.NoFileExists(t, marker)
+}
@@ -0,0 +1,111 @@
+package generate
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+// dquote escapes a value for use inside double quotes. `$` is left alone on purpose
+// so entries like `$HOME/bin` are expanded by the shell.
+func dquote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`")
+	return replacer.Replace(value)
+}
+
+func pathEntry(value string) string {
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		return "$HOME/" + dquote(rest)
+	}
+	if value == "~" {
+		return "$HOME"
+	}
+	return dquote(value)
+}
+
+func pathSeparator(detail data.Detail) string {
+	if detail.Separator == "" {
+		return data.DefaultPathSeparator
+	}
+	return dquote(detail.Separator)
+}
+
+// fishQuote escapes a value for use inside double quotes in fish, which only knows the
+// \" and \\ escapes there. `$` is left alone like in dquote.
+func fishQuote(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+// fishPathEntry is pathEntry for fish
+func fishPathEntry(value string) string {
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		return "$HOME/" + fishQuote(rest)
+	}
+	if value == "~" {
+		return "$HOME"
+	}
+	return fishQuote(value)
+}
+
+// fishPathList splits the list held by the key into its entries. fish joins path
+// variables like PATH with colons when quoted, so this works for them too.
+func fishPathList(detail data.Detail) string {
+	return fmt.Sprintf(`(string split --no-empty -- "%s" "$%s")`, fishQuote(cmp.Or(detail.Separator, data.DefaultPathSeparator)), detail.Key)
+}
+
+// fishSetPath sets the key to the entries in list. colon separated lists are set as
+// fish path variables, which keeps PATH a list in fish and exports it joined by colons.
+// other separators are joined into a single value.
+func fishSetPath(detail data.Detail, list string) string {
+	sep := cmp.Or(detail.Separator, data.DefaultPathSeparator)
+	if sep == data.DefaultPathSeparator {
+		return fmt.Sprintf("set -gx --path %s %s;", detail.Key, list)
+	}
+	return fmt.Sprintf(`set -gx %s (string join -- "%s" %s);`, detail.Key, fishQuote(sep), list)
+}
+
+// applyPathExpr adds the entry to the list unless it is already present, so applying a
+// profile again does not grow the list.
+func applyPathExpr(detail data.Detail, shell Shell) string {
+	if shell == FishShell {
+		entry := `"` + fishPathEntry(detail.Value) + `"`
+		list := entry + " " + fishPathList(detail)
+		if detail.Position == data.AppendPath {
+			list = fishPathList(detail) + " " + entry
+		}
+		return fmt.Sprintf("contains -- %s %s; or %s", entry, fishPathList(detail), fishSetPath(detail, list))
+	}
+	key := detail.Key
+	sep := pathSeparator(detail)
+	entry := pathEntry(detail.Value)
+	newValue := fmt.Sprintf(`"%s${%s:+%s${%s}}"`, entry, key, sep, key)
+	if detail.Position == data.AppendPath {
+		newValue = fmt.Sprintf(`"${%s:+${%s}%s}%s"`, key, key, sep, entry)
+	}
+	return fmt.Sprintf(`case "%s${%s}%s" in *"%s%s%s"*) ;; *) export %s=%s;; esac;`, sep, key, sep, sep, entry, sep, key, newValue)
+}
+
+// removePathExpr drops every occurrence of the entry from the list. for POSIX shells it
+// only relies on parameter expansion so it behaves the same in sh, bash and zsh.
+func removePathExpr(detail data.Detail, shell Shell) string {
+	if shell == FishShell {
+		var b strings.Builder
+		fmt.Fprintf(&b, "set -l _maggi_new; for _maggi_entry in %s; ", fishPathList(detail))
+		fmt.Fprintf(&b, `test "$_maggi_entry" = "%s"; or set -a _maggi_new $_maggi_entry; end; `, fishPathEntry(detail.Value))
+		fmt.Fprintf(&b, "%s set -e _maggi_new; set -e _maggi_entry;", fishSetPath(detail, "$_maggi_new"))
+		return b.String()
+	}
+	key := detail.Key
+	sep := pathSeparator(detail)
+	entry := pathEntry(detail.Value)
+	var b strings.Builder
+	fmt.Fprintf(&b, `_maggi_rest="${%s}%s"; _maggi_new=""; `, key, sep)
+	b.WriteString(`while [ -n "$_maggi_rest" ]; do `)
+	fmt.Fprintf(&b, `_maggi_entry="${_maggi_rest%%%%"%s"*}"; _maggi_rest="${_maggi_rest#*"%s"}"; `, sep, sep)
+	fmt.Fprintf(&b, `[ "$_maggi_entry" = "%s" ] || _maggi_new="${_maggi_new:+${_maggi_new}%s}${_maggi_entry}"; `, entry, sep)
+	b.WriteString(`done; `)
+	fmt.Fprintf(&b, `export %s="$_maggi_new"; unset _maggi_rest _maggi_new _maggi_entry;`, key)
+	return b.String()
+}
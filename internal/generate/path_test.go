@@ -0,0 +1,137 @@
+package generate
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+// runs script in every available posix shell and returns the value of key afterwards
+func runInShells(t *testing.T, initial string, script string, key string) map[string]string {
+	res := map[string]string{}
+	for _, shell := range []string{"sh", "bash", "zsh"} {
+		if _, err := exec.LookPath(shell); err != nil {
+			continue
+		}
+		cmd := exec.Command(shell, "-c", script+` printf '%s' "$`+key+`"`)
+		cmd.Env = []string{key + "=" + initial, "HOME=/home/maggi"}
+		out, err := cmd.Output()
+		assert.Nil(t, err, shell)
+		res[shell] = string(out)
+	}
+	return res
+}
+
+func posixApply(detail data.Detail) string {
+	return applyPathExpr(detail, PosixShell)
+}
+
+func posixRemove(detail data.Detail) string {
+	return removePathExpr(detail, PosixShell)
+}
+
+func TestPathExpr(t *testing.T) {
+	testcases := []struct {
+		name    string
+		detail  data.Detail
+		initial string
+		script  func(detail data.Detail) string
+		res     string
+	}{
+		{
+			name:    "prepend adds entry at the front",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "~/work/bin", Position: data.PrependPath},
+			initial: "/usr/bin:/bin",
+			script:  posixApply,
+			res:     "/home/maggi/work/bin:/usr/bin:/bin",
+		},
+		{
+			name:    "append adds entry at the end",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "/opt/bin", Position: data.AppendPath},
+			initial: "/usr/bin",
+			script:  posixApply,
+			res:     "/usr/bin:/opt/bin",
+		},
+		{
+			name:    "prepend to an empty list has no separator",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "/opt/bin", Position: data.PrependPath},
+			initial: "",
+			script:  posixApply,
+			res:     "/opt/bin",
+		},
+		{
+			name:    "applying twice does not duplicate",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "/opt/bin", Position: data.PrependPath},
+			initial: "/usr/bin",
+			script:  func(detail data.Detail) string { return posixApply(detail) + posixApply(detail) },
+			res:     "/opt/bin:/usr/bin",
+		},
+		{
+			name:    "custom separator is used",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "b", Position: data.AppendPath, Separator: ";"},
+			initial: "a",
+			script:  posixApply,
+			res:     "a;b",
+		},
+		{
+			name:    "undo removes every occurrence of the entry",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "~/work/bin"},
+			initial: "/home/maggi/work/bin:/usr/bin:/home/maggi/work/bin:/bin",
+			script:  posixRemove,
+			res:     "/usr/bin:/bin",
+		},
+		{
+			name:    "apply then undo restores the list",
+			detail:  data.Detail{Key: "MAGGI_PATH", Value: "/opt/my bin", Position: data.PrependPath},
+			initial: "/usr/bin:/bin",
+			script:  func(detail data.Detail) string { return posixApply(detail) + posixRemove(detail) },
+			res:     "/usr/bin:/bin",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			res := runInShells(t, testcase.initial, testcase.script(testcase.detail), testcase.detail.Key)
+			for shell, value := range res {
+				assert.Equal(t, testcase.res, value, shell)
+			}
+		})
+	}
+}
+
+func TestFishPathExpr(t *testing.T) {
+	prepend := data.Detail{Key: "PATH", Value: "/opt/bin", Position: data.PrependPath}
+	assert.Equal(t, `contains -- "/opt/bin" (string split --no-empty -- ":" "$PATH"); or set -gx --path PATH "/opt/bin" (string split --no-empty -- ":" "$PATH");`, applyPathExpr(prepend, FishShell))
+
+	// other separators are joined back into a single value
+	appended := data.Detail{Key: "MAGGI_PATH", Value: `b "c"`, Position: data.AppendPath, Separator: ";"}
+	assert.Equal(t, `contains -- "b \"c\"" (string split --no-empty -- ";" "$MAGGI_PATH"); or set -gx MAGGI_PATH (string join -- ";" (string split --no-empty -- ";" "$MAGGI_PATH") "b \"c\"");`, applyPathExpr(appended, FishShell))
+
+	home := data.Detail{Key: "PATH", Value: "~/work/bin"}
+	assert.Equal(t, `set -l _maggi_new; for _maggi_entry in (string split --no-empty -- ":" "$PATH"); test "$_maggi_entry" = "$HOME/work/bin"; or set -a _maggi_new $_maggi_entry; end; set -gx --path PATH $_maggi_new; set -e _maggi_new; set -e _maggi_entry;`, removePathExpr(home, FishShell))
+
+	if _, err := exec.LookPath("fish"); err != nil {
+		return
+	}
+	script := applyPathExpr(prepend, FishShell) + applyPathExpr(prepend, FishShell) + applyPathExpr(home, FishShell) + removePathExpr(home, FishShell)
+	cmd := exec.Command("fish", "--no-config", "-c", script+` printf '%s' "$PATH"`)
+	cmd.Env = []string{"PATH=/usr/bin:/bin", "HOME=/home/maggi"}
+	out, err := cmd.Output()
+	assert.Nil(t, err)
+	assert.Equal(t, "/opt/bin:/usr/bin:/bin", string(out))
+}
+
+func TestGenerateUndo(t *testing.T) {
+	details := []data.Detail{
+		{Key: "AWS_PROFILE", Value: "prod", DetailType: data.EnvDetail},
+		{Key: "k", Value: "kubectl", DetailType: data.AliasDetail},
+		{Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail},
+	}
+	res, err := generateUndo(profileRepositoryStub{details: details}, PosixShell, "test")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(res, `_maggi_rest="${PATH}:";`))
+	assert.True(t, strings.HasSuffix(res, "unalias k 2>/dev/null;unset AWS_PROFILE;"))
+}
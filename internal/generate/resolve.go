@@ -0,0 +1,265 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+// matches either an escaped `$${` or a `${NAME}` reference. the escape is listed
+// first so `$${NAME}` is never read as a reference.
+var referencePattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+type UndefinedReferenceError struct {
+	Key       string
+	Reference string
+}
+
+func (u UndefinedReferenceError) Error() string {
+	return fmt.Sprintf("%s references undefined variable %s", u.Key, u.Reference)
+}
+
+type CycleError struct {
+	Keys []string
+}
+
+func (c CycleError) Error() string {
+	return fmt.Sprintf("reference cycle between %s", strings.Join(c.Keys, ", "))
+}
+
+type DependencyError struct {
+	Key        string
+	Dependency string
+}
+
+func (d DependencyError) Error() string {
+	return fmt.Sprintf("%s depends on %s which could not be resolved", d.Key, d.Dependency)
+}
+
+// KeyError ties a resolution failure to the env key it belongs to.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (k KeyError) Error() string {
+	return k.Err.Error()
+}
+
+func (k KeyError) Unwrap() error {
+	return k.Err
+}
+
+// HasInterpolation reports whether value uses `${NAME}` references or `$${` escapes.
+func HasInterpolation(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// References lists the names referenced by value in order of appearance. Escaped
+// references are skipped.
+func References(value string) []string {
+	var refs []string
+	for _, match := range referencePattern.FindAllStringSubmatch(value, -1) {
+		if match[1] != "" {
+			refs = append(refs, match[1])
+		}
+	}
+	return refs
+}
+
+func expand(value string, lookup func(name string) (string, bool)) (string, error) {
+	var err error
+	res := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		name := match[2 : len(match)-1]
+		expanded, ok := lookup(name)
+		if !ok && err == nil {
+			err = UndefinedReferenceError{Reference: name}
+		}
+		return expanded
+	})
+	return res, err
+}
+
+// Resolver expands references between env details. Values are looked up in the
+// profile details first and then in the process environment.
+type Resolver struct {
+	lookupEnv func(name string) (string, bool)
+	evaluate  func(kind data.ValueKind, value string) (string, error)
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		lookupEnv: os.LookupEnv,
+		evaluate: func(kind data.ValueKind, value string) (string, error) {
+			if kind != data.CommandValue && kind != data.FileValue {
+				return value, nil
+			}
+			return "", fmt.Errorf("%s values are not evaluated here", kind)
+		},
+	}
+}
+
+// Resolve expands the given env details, which are expected to hold plaintext values.
+// Details are resolved in dependency order and the final value of every key that
+// resolved cleanly is returned. Keys that failed are reported in errs in the order of
+// the passed details, and are left out of the result.
+func (r *Resolver) Resolve(details []data.Detail) (map[string]string, []KeyError) {
+	byKey := make(map[string]data.Detail, len(details))
+	var keys []string
+	for _, detail := range details {
+		if _, ok := byKey[detail.Key]; !ok {
+			keys = append(keys, detail.Key)
+		}
+		byKey[detail.Key] = detail
+	}
+
+	deps := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		for _, ref := range References(byKey[key].Value) {
+			if _, ok := byKey[ref]; ok && ref != key {
+				deps[key] = append(deps[key], ref)
+			}
+		}
+	}
+
+	ordered, unsorted := sortByReferences(keys, deps)
+	failed := make(map[string]error, len(keys))
+	var cycle []string
+	for _, key := range unsorted {
+		if reachesSelf(key, deps) {
+			cycle = append(cycle, key)
+		}
+	}
+	for _, key := range cycle {
+		failed[key] = CycleError{Keys: cycle}
+	}
+	for _, key := range unsorted {
+		if _, ok := failed[key]; ok {
+			continue
+		}
+		for _, dep := range deps[key] {
+			if slices.Contains(unsorted, dep) {
+				failed[key] = DependencyError{Key: key, Dependency: dep}
+				break
+			}
+		}
+	}
+
+	resolved := make(map[string]string, len(keys))
+	for _, key := range ordered {
+		detail := byKey[key]
+		if err := firstFailedDependency(key, deps[key], failed); err != nil {
+			failed[key] = err
+			continue
+		}
+		value, err := expand(detail.Value, func(name string) (string, bool) {
+			if name == key {
+				// a key referencing itself extends the value from the environment, like PATH
+				return r.lookupEnv(name)
+			}
+			if value, ok := resolved[name]; ok {
+				return value, true
+			}
+			return r.lookupEnv(name)
+		})
+		if err != nil {
+			if undefined, ok := err.(UndefinedReferenceError); ok {
+				undefined.Key = key
+				err = undefined
+			}
+			failed[key] = err
+			continue
+		}
+		value, err = r.evaluate(detail.Kind, value)
+		if err != nil {
+			failed[key] = err
+			continue
+		}
+		resolved[key] = value
+	}
+
+	var errs []KeyError
+	for _, key := range keys {
+		if err, ok := failed[key]; ok {
+			errs = append(errs, KeyError{Key: key, Err: err})
+		}
+	}
+	return resolved, errs
+}
+
+func reachesSelf(key string, deps map[string][]string) bool {
+	seen := map[string]bool{}
+	stack := append([]string{}, deps[key]...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == key {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		stack = append(stack, deps[current]...)
+	}
+	return false
+}
+
+func firstFailedDependency(key string, deps []string, failed map[string]error) error {
+	for _, dep := range deps {
+		if _, ok := failed[dep]; ok {
+			return DependencyError{Key: key, Dependency: dep}
+		}
+	}
+	return nil
+}
+
+// sortByReferences orders keys so that every key comes after the keys it depends on.
+// Ties keep the original order. Keys that are part of a cycle, or depend on one, are
+// returned separately.
+func sortByReferences(keys []string, deps map[string][]string) ([]string, []string) {
+	pending := make(map[string]int, len(keys))
+	dependents := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		pending[key] = len(deps[key])
+		for _, dep := range deps[key] {
+			dependents[dep] = append(dependents[dep], key)
+		}
+	}
+
+	var ordered []string
+	done := make(map[string]bool, len(keys))
+	for len(ordered) < len(keys) {
+		progressed := false
+		for _, key := range keys {
+			if done[key] || pending[key] > 0 {
+				continue
+			}
+			done[key] = true
+			ordered = append(ordered, key)
+			for _, dependent := range dependents[key] {
+				pending[dependent]--
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	var cyclic []string
+	for _, key := range keys {
+		if !done[key] {
+			cyclic = append(cyclic, key)
+		}
+	}
+	return ordered, cyclic
+}
@@ -0,0 +1,94 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferences(t *testing.T) {
+	assert.Equal(t, []string{"HOME", "CLUSTER"}, References("${HOME}/.kube/${CLUSTER}"))
+	assert.Nil(t, References("$${HOME}/$HOME"))
+	assert.True(t, HasInterpolation("$${HOME}"))
+	assert.False(t, HasInterpolation("$HOME/bin"))
+}
+
+func TestResolve(t *testing.T) {
+	processEnv := map[string]string{"HOME": "/home/maggi", "PATH": "/usr/bin"}
+	resolver := NewResolver()
+	resolver.lookupEnv = func(name string) (string, bool) {
+		value, ok := processEnv[name]
+		return value, ok
+	}
+
+	testcases := []struct {
+		name     string
+		details  []data.Detail
+		resolved map[string]string
+		errs     []KeyError
+	}{
+		{
+			name: "references are resolved regardless of order",
+			details: []data.Detail{
+				{Key: "KUBECONFIG", Value: "${HOME}/.kube/${CLUSTER}"},
+				{Key: "CLUSTER", Value: "${REGION}-prod"},
+				{Key: "REGION", Value: "eu"},
+			},
+			resolved: map[string]string{"KUBECONFIG": "/home/maggi/.kube/eu-prod", "CLUSTER": "eu-prod", "REGION": "eu"},
+		},
+		{
+			name: "profile details take precedence over process env",
+			details: []data.Detail{
+				{Key: "TARGET", Value: "${HOME}/target"},
+				{Key: "HOME", Value: "/srv"},
+			},
+			resolved: map[string]string{"TARGET": "/srv/target", "HOME": "/srv"},
+		},
+		{
+			name:     "self reference reads the process env",
+			details:  []data.Detail{{Key: "PATH", Value: "/opt/bin:${PATH}"}},
+			resolved: map[string]string{"PATH": "/opt/bin:/usr/bin"},
+		},
+		{
+			name:     "escaped references are kept literal",
+			details:  []data.Detail{{Key: "TEMPLATE", Value: "$${HOME}/${HOME}"}},
+			resolved: map[string]string{"TEMPLATE": "${HOME}//home/maggi"},
+		},
+		{
+			name: "undefined references fail the key and its dependents",
+			details: []data.Detail{
+				{Key: "A", Value: "${MISSING}"},
+				{Key: "B", Value: "${A}"},
+				{Key: "C", Value: "ok"},
+			},
+			resolved: map[string]string{"C": "ok"},
+			errs: []KeyError{
+				{Key: "A", Err: UndefinedReferenceError{Key: "A", Reference: "MISSING"}},
+				{Key: "B", Err: DependencyError{Key: "B", Dependency: "A"}},
+			},
+		},
+		{
+			name: "cycles are reported",
+			details: []data.Detail{
+				{Key: "A", Value: "${B}"},
+				{Key: "B", Value: "${A}"},
+				{Key: "C", Value: "${A}"},
+			},
+			resolved: map[string]string{},
+			errs: []KeyError{
+				{Key: "A", Err: CycleError{Keys: []string{"A", "B"}}},
+				{Key: "B", Err: CycleError{Keys: []string{"A", "B"}}},
+				{Key: "C", Err: DependencyError{Key: "C", Dependency: "A"}},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			resolved, errs := resolver.Resolve(testcase.details)
+			assert.Equal(t, testcase.resolved, resolved)
+			assert.Equal(t, testcase.errs, errs)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package generate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+func sourceExpr(detail data.Detail, shell Shell) string {
+	file := `"` + pathEntry(detail.Value) + `"`
+	if shell == FishShell {
+		file = `"` + fishPathEntry(detail.Value) + `"`
+		if detail.Guard {
+			return fmt.Sprintf("if test -f %s; source %s; end;", file, file)
+		}
+		return fmt.Sprintf("source %s;", file)
+	}
+	if detail.Guard {
+		return fmt.Sprintf("if [ -f %s ]; then . %s; fi;", file, file)
+	}
+	return fmt.Sprintf(". %s;", file)
+}
+
+// rawExpr writes the snippet as is when it is tagged for the shell. like functions,
+// snippets for POSIX shells go through printf so their lines survive an unquoted eval.
+func rawExpr(detail data.Detail, shell Shell) string {
+	if len(detail.Shells) > 0 && !slices.Contains(detail.Shells, string(shell)) {
+		return ""
+	}
+	snippet := strings.Trim(detail.Value, "\n")
+	if shell == FishShell {
+		return snippet + "\n"
+	}
+	return fmt.Sprintf(`eval "$(printf '%%b' '%s')";`, printfEscape(snippet))
+}
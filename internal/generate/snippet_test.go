@@ -0,0 +1,83 @@
+package generate
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceExpr(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "activate")
+	err := os.WriteFile(file, []byte("MAGGI_VENV=on\n"), 0600)
+	assert.Nil(t, err)
+
+	testcases := []struct {
+		name   string
+		detail data.Detail
+		res    string
+	}{
+		{
+			name:   "file is sourced",
+			detail: data.Detail{Key: "venv", Value: file, DetailType: data.SourceDetail},
+			res:    "on",
+		},
+		{
+			name:   "guarded file is sourced when it exists",
+			detail: data.Detail{Key: "venv", Value: file, DetailType: data.SourceDetail, Guard: true},
+			res:    "on",
+		},
+		{
+			name:   "guarded file is skipped when missing",
+			detail: data.Detail{Key: "venv", Value: filepath.Join(dir, "missing"), DetailType: data.SourceDetail, Guard: true},
+			res:    "off",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			res := runInShells(t, "off", sourceExpr(testcase.detail, PosixShell), "MAGGI_VENV")
+			for shell, value := range res {
+				assert.Equal(t, testcase.res, value, shell)
+			}
+		})
+	}
+}
+
+func TestRawExpr(t *testing.T) {
+	snippet := "MAGGI_RAW=\"a  b\"\nMAGGI_RAW=\"${MAGGI_RAW}|c\""
+	res := runInShells(t, "", rawExpr(data.Detail{Key: "raw", Value: snippet, DetailType: data.RawDetail}, PosixShell), "MAGGI_RAW")
+	for shell, value := range res {
+		assert.Equal(t, "a  b|c", value, shell)
+	}
+
+	zshOnly := data.Detail{Key: "opts", Value: "setopt autocd", DetailType: data.RawDetail, Shells: []string{"zsh"}}
+	assert.Equal(t, "", rawExpr(zshOnly, BashShell))
+	assert.NotEqual(t, "", rawExpr(zshOnly, ZshShell))
+	assert.Equal(t, "set -g fish_greeting\n", rawExpr(data.Detail{Value: "set -g fish_greeting", Shells: []string{"fish"}}, FishShell))
+}
+
+func TestSnippetsComeLast(t *testing.T) {
+	details := []data.Detail{
+		{Key: "ulimit", Value: "ulimit -n 4096", DetailType: data.RawDetail},
+		{Key: "venv", Value: "/opt/venv/bin/activate", DetailType: data.SourceDetail},
+		{Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail},
+		{Key: "AWS_PROFILE", Value: "prod", DetailType: data.EnvDetail},
+	}
+	res, err := generate(profileRepositoryStub{details: details}, newEvaluator(Options{}), PosixShell, false, "test")
+	assert.Nil(t, err)
+	expected := "export AWS_PROFILE=prod;" +
+		applyPathExpr(details[2], PosixShell) +
+		`. "/opt/venv/bin/activate";` +
+		rawExpr(details[0], PosixShell)
+	assert.Equal(t, expected, res)
+	// sanity check that the whole script still runs
+	if _, err := exec.LookPath("sh"); err == nil {
+		out, err := exec.Command("sh", "-n", "-c", res).CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+}
@@ -0,0 +1,59 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+const timeLayout = "2006-01-02 15:04"
+
+// Change describes what an entry did, e.g. `vim → nvim`. Secret values are masked and
+// values spanning lines are cut to their first line.
+func Change(entry data.HistoryEntry) string {
+	oldValue := display(entry.OldValue, entry.OldSecret)
+	newValue := display(entry.NewValue, entry.NewSecret)
+	switch entry.Action {
+	case data.HistoryAdded:
+		return "added " + newValue
+	case data.HistoryDeleted:
+		return "deleted " + oldValue
+	default:
+		return oldValue + " → " + newValue
+	}
+}
+
+func display(value string, secret bool) string {
+	if secret {
+		return data.SecretMask
+	}
+	if first, _, ok := strings.Cut(value, "\n"); ok {
+		return first + " …"
+	}
+	return value
+}
+
+// Type is the detail type of the entry, or profile for profile changes
+func Type(entry data.HistoryEntry) string {
+	if entry.DetailType == "" {
+		return "profile"
+	}
+	return entry.DetailType.String()
+}
+
+// Write lists the entries, newest first as returned by the repository.
+func Write(w io.Writer, entries []data.HistoryEntry) error {
+	if len(entries) == 0 {
+		_, err := io.WriteString(w, "No history\n")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSOURCE\tPROFILE\tTYPE\tKEY\tCHANGE")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.CreatedAt.Format(timeLayout), entry.Source, entry.ProfileName, Type(entry), entry.Key, Change(entry))
+	}
+	return tw.Flush()
+}
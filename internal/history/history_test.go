@@ -0,0 +1,32 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	entries := []data.HistoryEntry{
+		{ProfileName: "work", Action: data.HistoryUpdated, OldValue: "job", NewValue: "work", Source: data.TUISource, CreatedAt: at},
+		{ProfileName: "work", DetailType: data.EnvDetail, Key: "TOKEN", Action: data.HistoryUpdated, OldValue: "x", NewValue: "y", OldSecret: true, NewSecret: true, Source: data.CLISource, CreatedAt: at},
+		{ProfileName: "work", DetailType: data.FunctionDetail, Key: "mkcd", Action: data.HistoryAdded, NewValue: "mkdir -p \"$1\"\ncd \"$1\"", Source: data.TUISource, CreatedAt: at},
+		{ProfileName: "work", DetailType: data.AliasDetail, Key: "ll", Action: data.HistoryDeleted, OldValue: "ls -l", Source: data.TUISource, CreatedAt: at},
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&buf, entries))
+	expected := "TIME              SOURCE  PROFILE  TYPE      KEY    CHANGE\n" +
+		"2024-05-01 09:30  tui     work     profile          job → work\n" +
+		"2024-05-01 09:30  cli     work     env       TOKEN  <secret> → <secret>\n" +
+		"2024-05-01 09:30  tui     work     function  mkcd   added mkdir -p \"$1\" …\n" +
+		"2024-05-01 09:30  tui     work     alias     ll     deleted ls -l\n"
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, Write(&buf, nil))
+	assert.Equal(t, "No history\n", buf.String())
+}
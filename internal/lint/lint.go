@@ -0,0 +1,369 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/generate"
+)
+
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// Problem is a single finding. Values are never included in the message since they can
+// be secret. Secrets are not revealed, so only their keys, types and conflicts are
+// checked.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Profile  string   `json:"profile"`
+	Type     string   `json:"type,omitempty"`
+	Key      string   `json:"key,omitempty"`
+	Message  string   `json:"message"`
+}
+
+type LintProfileRepository interface {
+	GetAllProfiles() ([]data.Profile, error)
+	GetDetailsByProfileName(name string) ([]data.Detail, error)
+}
+
+// Options pick the profiles to lint.
+type Options struct {
+	// Profile limits the lint to one profile. Every profile is linted when empty.
+	Profile string
+	// Default is the profile the others are layered on, as with `apply-session --default`.
+	// Keys set in both are reported and references are resolved across both.
+	Default string
+}
+
+// words the shell handles itself, so they are never looked up on $PATH
+var shellWords = []string{
+	".", ":", "[", "alias", "autoload", "bg", "bind", "bindkey", "break", "builtin", "case", "cd",
+	"command", "continue", "declare", "dirs", "disown", "do", "done", "echo", "elif", "else",
+	"enable", "esac", "eval", "exec", "exit", "export", "false", "fc", "fg", "fi", "for",
+	"function", "getopts", "hash", "help", "history", "if", "jobs", "kill", "let", "local",
+	"logout", "noglob", "popd", "printf", "pushd", "pwd", "read", "readonly", "return",
+	"select", "set", "setopt", "shift", "source", "suspend", "test", "then", "time", "times",
+	"trap", "true", "type", "typeset", "ulimit", "umask", "unalias", "unset", "unsetopt",
+	"until", "wait", "which", "while",
+}
+
+type linter struct {
+	repository LintProfileRepository
+	lookPath   func(file string) (string, error)
+	stat       func(name string) (os.FileInfo, error)
+	homeDir    func() (string, error)
+}
+
+func newLinter(repository LintProfileRepository) *linter {
+	return &linter{
+		repository: repository,
+		lookPath:   exec.LookPath,
+		stat:       os.Stat,
+		homeDir:    os.UserHomeDir,
+	}
+}
+
+// Lint checks the stored profiles and returns the problems found, in profile order.
+func Lint(repository LintProfileRepository, opts Options) ([]Problem, error) {
+	return newLinter(repository).lint(opts)
+}
+
+// HasErrors reports whether any of the problems is an error.
+func HasErrors(problems []Problem) bool {
+	return slices.ContainsFunc(problems, func(problem Problem) bool {
+		return problem.Severity == Error
+	})
+}
+
+// Report writes the problems either as a json array or one per line followed by a
+// summary.
+func Report(w io.Writer, problems []Problem, asJSON bool) error {
+	if asJSON {
+		if problems == nil {
+			problems = []Problem{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(problems)
+	}
+	counts := make(map[Severity]int)
+	for _, problem := range problems {
+		counts[problem.Severity]++
+		subject := problem.Profile
+		if problem.Key != "" {
+			subject = fmt.Sprintf("%s: %s %s", problem.Profile, problem.Type, problem.Key)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s [%s]\n", problem.Severity, subject, problem.Message, problem.Check); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings, %d info\n", counts[Error], counts[Warning], counts[Info])
+	return err
+}
+
+type profileDetails struct {
+	name    string
+	details []data.Detail
+}
+
+func (l *linter) lint(opts Options) ([]Problem, error) {
+	profiles, err := l.repository.GetAllProfiles()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	for _, name := range []string{opts.Profile, opts.Default} {
+		if name != "" && !slices.Contains(names, name) {
+			return nil, fmt.Errorf("profile %s does not exist", name)
+		}
+	}
+	if opts.Profile != "" {
+		names = []string{opts.Profile}
+	}
+
+	var base *profileDetails
+	if opts.Default != "" {
+		details, err := l.repository.GetDetailsByProfileName(opts.Default)
+		if err != nil {
+			return nil, err
+		}
+		base = &profileDetails{name: opts.Default, details: details}
+	}
+
+	var problems []Problem
+	for _, name := range names {
+		details, err := l.repository.GetDetailsByProfileName(name)
+		if err != nil {
+			return nil, err
+		}
+		current := profileDetails{name: name, details: details}
+		layered := base
+		if base != nil && base.name == name {
+			layered = nil
+		}
+		problems = append(problems, l.lintProfile(current, layered)...)
+	}
+	return problems, nil
+}
+
+func (l *linter) lintProfile(current profileDetails, base *profileDetails) []Problem {
+	if len(current.details) == 0 {
+		return []Problem{{Severity: Warning, Check: "empty-profile", Profile: current.name, Message: "profile has no details"}}
+	}
+
+	var problems []Problem
+	report := func(severity Severity, check string, detail data.Detail, format string, args ...any) {
+		problems = append(problems, Problem{
+			Severity: severity,
+			Check:    check,
+			Profile:  current.name,
+			Type:     detail.DetailType.String(),
+			Key:      detail.Key,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// names an alias can call without them being on $PATH
+	defined := make(map[string]bool)
+	var envs []data.Detail
+	if base != nil {
+		for _, detail := range base.details {
+			if detail.DetailType == data.AliasDetail || detail.DetailType == data.FunctionDetail {
+				defined[detail.Key] = true
+			}
+			if detail.DetailType == data.EnvDetail {
+				envs = append(envs, unevaluated(detail))
+			}
+		}
+	}
+	for _, detail := range current.details {
+		if detail.DetailType == data.AliasDetail || detail.DetailType == data.FunctionDetail {
+			defined[detail.Key] = true
+		}
+		if detail.DetailType == data.EnvDetail {
+			envs = append(envs, unevaluated(detail))
+		}
+	}
+
+	secrets := secretEnvs(envs)
+	resolved, keyErrs := generate.NewResolver().Resolve(envs)
+	unresolved := make(map[string]error)
+	for _, keyErr := range keyErrs {
+		unresolved[keyErr.Key] = keyErr.Err
+	}
+
+	for _, detail := range current.details {
+		if detail.Secret {
+			report(Info, "secret-skipped", detail, "value is secret and was not checked")
+		}
+		for _, problem := range data.ValidateDetail(detail) {
+			if detail.Secret && problem.Field == data.ValueField {
+				continue
+			}
+			severity := Error
+			if problem.Warning {
+				severity = Warning
+			}
+			report(severity, "invalid-"+string(problem.Field), detail, "%s", problem.Message)
+		}
+
+		if base != nil {
+			if baseDetail, ok := findLayered(base.details, detail); ok {
+				if !baseDetail.Secret && !detail.Secret && baseDetail.Value == detail.Value && baseDetail.Kind == detail.Kind {
+					report(Warning, "duplicate-key", detail, "same value is already set by profile %s", base.name)
+				} else {
+					report(Info, "duplicate-key", detail, "overrides the value set by profile %s", base.name)
+				}
+			}
+		}
+
+		if detail.Secret {
+			continue
+		}
+		switch detail.DetailType {
+		case data.EnvDetail:
+			if err, ok := unresolved[detail.Key]; ok {
+				report(Error, "unresolved-reference", detail, "%s", unresolvedMessage(err))
+				continue
+			}
+			value, ok := resolved[detail.Key]
+			if ok && !secrets[detail.Key] && detail.Kind == data.LiteralValue && looksLikePath(value) && !l.exists(value) {
+				report(Warning, "missing-path", detail, "points at %s which does not exist", value)
+			}
+		case data.PathDetail:
+			if looksLikePath(detail.Value) && !l.exists(detail.Value) {
+				report(Warning, "missing-path", detail, "adds %s which does not exist", detail.Value)
+			}
+		case data.SourceDetail:
+			if !detail.Guard && !l.exists(detail.Value) {
+				report(Warning, "missing-path", detail, "sources %s which does not exist. guard it to skip the file when missing", detail.Value)
+			}
+		case data.AliasDetail:
+			if detail.Kind != data.LiteralValue {
+				continue
+			}
+			command := aliasCommand(detail.Value)
+			if detail.Key != command {
+				if slices.Contains(shellWords, detail.Key) {
+					report(Warning, "shadowed-builtin", detail, "shadows the shell builtin %s", detail.Key)
+				} else if path, err := l.lookPath(detail.Key); err == nil {
+					report(Warning, "shadowed-command", detail, "shadows the command %s", path)
+				}
+			}
+			if command == "" || slices.Contains(shellWords, command) || (defined[command] && command != detail.Key) {
+				continue
+			}
+			if strings.Contains(command, "/") {
+				if !l.exists(command) {
+					report(Warning, "missing-executable", detail, "runs %s which does not exist", command)
+				}
+			} else if _, err := l.lookPath(command); err != nil {
+				report(Warning, "missing-executable", detail, "runs %s which was not found on $PATH", command)
+			}
+		}
+	}
+	return problems
+}
+
+// unevaluated keeps cmd: and file: values from running during lint. only their
+// references matter here.
+func unevaluated(detail data.Detail) data.Detail {
+	detail.Kind = data.LiteralValue
+	return detail
+}
+
+// secretEnvs returns the env keys whose value is secret or references a secret, directly
+// or through other envs. their values are not known without revealing the secrets.
+func secretEnvs(envs []data.Detail) map[string]bool {
+	secrets := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, detail := range envs {
+			if secrets[detail.Key] {
+				continue
+			}
+			if detail.Secret || slices.ContainsFunc(generate.References(detail.Value), func(ref string) bool { return secrets[ref] }) {
+				secrets[detail.Key] = true
+				changed = true
+			}
+		}
+	}
+	return secrets
+}
+
+func unresolvedMessage(err error) string {
+	var undefined generate.UndefinedReferenceError
+	if errors.As(err, &undefined) {
+		return fmt.Sprintf("references %s which is not set by the profile or the environment", undefined.Reference)
+	}
+	return err.Error()
+}
+
+// findLayered returns the detail from the base profile that the detail replaces when
+// the profiles are applied together.
+func findLayered(details []data.Detail, detail data.Detail) (data.Detail, bool) {
+	for _, candidate := range details {
+		if candidate.DetailType != detail.DetailType || candidate.Key != detail.Key {
+			continue
+		}
+		if detail.DetailType == data.PathDetail && candidate.Value != detail.Value {
+			continue
+		}
+		return candidate, true
+	}
+	return data.Detail{}, false
+}
+
+// aliasCommand returns the first command word of an alias, skipping leading variable
+// assignments. a quoted value is unquoted first, the way the shell reads it in an
+// alias definition. words that are expanded by the shell are not checked.
+func aliasCommand(value string) string {
+	for _, word := range strings.Fields(generate.ShellUnquote(value)) {
+		if strings.ContainsAny(word, "$`(){};|&<>\"'\\") {
+			return ""
+		}
+		if name, _, ok := strings.Cut(word, "="); ok && name != "" {
+			continue
+		}
+		return word
+	}
+	return ""
+}
+
+func looksLikePath(value string) bool {
+	if !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "~/") {
+		return false
+	}
+	return !strings.ContainsAny(value, " :\t\n$")
+}
+
+func (l *linter) expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if homeDir, err := l.homeDir(); err == nil {
+			return filepath.Join(homeDir, rest)
+		}
+	}
+	return path
+}
+
+func (l *linter) exists(path string) bool {
+	_, err := l.stat(l.expandHome(path))
+	return err == nil
+}
@@ -0,0 +1,214 @@
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+type lintRepositoryStub struct {
+	profiles map[string][]data.Detail
+	order    []string
+}
+
+func (l lintRepositoryStub) GetAllProfiles() ([]data.Profile, error) {
+	var profiles []data.Profile
+	for i, name := range l.order {
+		profiles = append(profiles, data.Profile{ID: i + 1, Name: name})
+	}
+	return profiles, nil
+}
+
+func (l lintRepositoryStub) GetDetailsByProfileName(name string) ([]data.Detail, error) {
+	return append([]data.Detail{}, l.profiles[name]...), nil
+}
+
+func testLinter(profiles map[string][]data.Detail, order ...string) *linter {
+	l := newLinter(lintRepositoryStub{profiles: profiles, order: order})
+	l.lookPath = func(file string) (string, error) {
+		switch file {
+		case "ls", "git":
+			return "/usr/bin/" + file, nil
+		}
+		return "", errors.New("not found")
+	}
+	l.stat = func(name string) (os.FileInfo, error) {
+		switch name {
+		case "/opt/bin", "/home/test/bin":
+			return nil, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	l.homeDir = func() (string, error) { return "/home/test", nil }
+	return l
+}
+
+func checks(problems []Problem) []string {
+	var res []string
+	for _, problem := range problems {
+		res = append(res, string(problem.Severity)+" "+problem.Check+" "+problem.Key)
+	}
+	return res
+}
+
+func TestLint(t *testing.T) {
+	testcases := []struct {
+		name     string
+		profiles map[string][]data.Detail
+		opts     Options
+		res      []string
+		err      bool
+	}{
+		{
+			name:     "empty profiles are reported",
+			profiles: map[string][]data.Detail{"test": nil},
+			res:      []string{"warning empty-profile "},
+		},
+		{
+			name: "aliases wrapping the command they shadow are fine",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "ls", Value: "ls -G", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "gs", Value: "LANG=C git status", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+			}},
+		},
+		{
+			name: "aliases shadowing commands and builtins",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "ls", Value: "git ls-files", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "cd", Value: "git", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+			}},
+			res: []string{"warning shadowed-command ls", "warning shadowed-builtin cd"},
+		},
+		{
+			name: "aliases running missing executables",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "b", Value: "bat -p", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "o", Value: "/opt/tool/run", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "h", Value: "greet world", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "greet", Value: "echo hi", DetailType: data.FunctionDetail},
+				{Key: "e", Value: "$EDITOR .", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+			}},
+			res: []string{"warning missing-executable b", "warning missing-executable o"},
+		},
+		{
+			name: "quoted aliases are checked",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "gs", Value: "'git status'", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "b", Value: "'bat -p'", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "t", Value: `"tig --all"`, DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "l", Value: `"LANG=C ls -l"`, DetailType: data.AliasDetail, Kind: data.LiteralValue},
+				{Key: "e", Value: `"$EDITOR ."`, DetailType: data.AliasDetail, Kind: data.LiteralValue},
+			}},
+			res: []string{"warning missing-executable b", "warning missing-executable t"},
+		},
+		{
+			name: "paths that do not exist",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "GOBIN", Value: "~/bin", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "GOROOT", Value: "/nope", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "TOOL", Value: "${GOROOT}/tool", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail},
+				{Key: "PATH", Value: "/opt/gone", DetailType: data.PathDetail},
+				{Key: "venv", Value: "/venv/activate", DetailType: data.SourceDetail},
+				{Key: "guarded", Value: "/venv/activate", DetailType: data.SourceDetail, Guard: true},
+			}},
+			res: []string{"warning missing-path GOROOT", "warning missing-path TOOL", "warning missing-path PATH", "warning missing-path venv"},
+		},
+		{
+			name: "unresolved references are errors",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "A", Value: "${MAGGI_LINT_UNDEFINED}", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "B", Value: "${C}", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "C", Value: "${B}", DetailType: data.EnvDetail, Kind: data.CommandValue},
+			}},
+			res: []string{"error unresolved-reference A", "error unresolved-reference B", "error unresolved-reference C"},
+		},
+		{
+			name: "invalid stored details",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "MY-VAR", Value: "x", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "PATH", Value: "x", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+			}},
+			res: []string{"error invalid-key MY-VAR", "warning invalid-key PATH"},
+		},
+		{
+			name: "keys duplicated across layered profiles",
+			profiles: map[string][]data.Detail{
+				"base": {
+					{Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+					{Key: "g", Value: "git", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+					{Key: "ROOT", Value: "/opt", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				},
+				"test": {
+					{Key: "EDITOR", Value: "nvim", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+					{Key: "g", Value: "git", DetailType: data.AliasDetail, Kind: data.LiteralValue},
+					{Key: "BIN", Value: "${ROOT}/bin", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				},
+			},
+			opts: Options{Profile: "test", Default: "base"},
+			res:  []string{"info duplicate-key EDITOR", "warning duplicate-key g"},
+		},
+		{
+			name: "secrets are checked without revealing them",
+			profiles: map[string][]data.Detail{"test": {
+				{Key: "TOKEN", Value: "c2VhbGVk", DetailType: data.EnvDetail, Kind: data.LiteralValue, Secret: true},
+				{Key: "TOKEN_FILE", Value: "/nope/${TOKEN}", DetailType: data.EnvDetail, Kind: data.LiteralValue},
+				{Key: "MY-KEY", Value: "c2VhbGVk", DetailType: data.EnvDetail, Kind: data.LiteralValue, Secret: true},
+				{Key: "PATH", Value: "c2VhbGVk", DetailType: data.PathDetail, Secret: true},
+				{Key: "x", Value: "c2VhbGVk", DetailType: data.AliasDetail, Kind: data.LiteralValue, Secret: true},
+			}},
+			res: []string{"info secret-skipped TOKEN", "info secret-skipped MY-KEY", "error invalid-key MY-KEY", "info secret-skipped PATH", "info secret-skipped x"},
+		},
+		{
+			name: "secrets duplicated across layered profiles are overrides",
+			profiles: map[string][]data.Detail{
+				"base": {{Key: "TOKEN", Value: "c2VhbGVk", DetailType: data.EnvDetail, Kind: data.LiteralValue, Secret: true}},
+				"test": {{Key: "TOKEN", Value: "c2VhbGVk", DetailType: data.EnvDetail, Kind: data.LiteralValue, Secret: true}},
+			},
+			opts: Options{Profile: "test", Default: "base"},
+			res:  []string{"info secret-skipped TOKEN", "info duplicate-key TOKEN"},
+		},
+		{
+			name:     "unknown profile",
+			profiles: map[string][]data.Detail{"test": nil},
+			opts:     Options{Profile: "other"},
+			err:      true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var order []string
+			for name := range tc.profiles {
+				order = append(order, name)
+			}
+			problems, err := testLinter(tc.profiles, order...).lint(tc.opts)
+			if tc.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.res, checks(problems))
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	problems := []Problem{
+		{Severity: Error, Check: "unresolved-reference", Profile: "work", Type: "env", Key: "A", Message: "references B"},
+		{Severity: Warning, Check: "empty-profile", Profile: "empty", Message: "profile has no details"},
+	}
+	var b bytes.Buffer
+	assert.Nil(t, Report(&b, problems, false))
+	assert.Equal(t, "error: work: env A: references B [unresolved-reference]\nwarning: empty: profile has no details [empty-profile]\n1 errors, 1 warnings, 0 info\n", b.String())
+	assert.True(t, HasErrors(problems))
+	assert.False(t, HasErrors(problems[1:]))
+
+	b.Reset()
+	assert.Nil(t, Report(&b, nil, true))
+	assert.Equal(t, "[]\n", b.String())
+}
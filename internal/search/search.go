@@ -0,0 +1,108 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+type SearchRepository interface {
+	GetAllProfiles() ([]data.Profile, error)
+	GetAllDetails(profileId int) ([]data.Detail, error)
+}
+
+// Match is a detail found by a search, with the profile it belongs to
+type Match struct {
+	Profile data.Profile
+	Detail  data.Detail
+}
+
+// Load returns every detail of every profile as a match, ordered by profile name, then
+// type and key. Find narrows them down, so a search can be run on each key press
+// without going back to the repository.
+func Load(repository SearchRepository) ([]Match, error) {
+	profiles, err := repository.GetAllProfiles()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	for _, profile := range profiles {
+		details, err := repository.GetAllDetails(profile.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, detail := range details {
+			matches = append(matches, Match{Profile: profile, Detail: detail})
+		}
+	}
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(
+			strings.Compare(a.Profile.Name, b.Profile.Name),
+			strings.Compare(string(a.Detail.DetailType), string(b.Detail.DetailType)),
+			strings.Compare(a.Detail.Key, b.Detail.Key),
+		)
+	})
+	return matches, nil
+}
+
+// Find keeps the matches with query in their key or value, ignoring case. Secret
+// values are encrypted, so secrets only match on their key. An empty query keeps
+// everything.
+func Find(matches []Match, query string) []Match {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return matches
+	}
+	var found []Match
+	for _, match := range matches {
+		if strings.Contains(strings.ToLower(match.Detail.Key), query) ||
+			(!match.Detail.Secret && strings.Contains(strings.ToLower(match.Detail.Value), query)) {
+			found = append(found, match)
+		}
+	}
+	return found
+}
+
+// Search finds the details of every profile with query in their key or value
+func Search(repository SearchRepository, query string) ([]Match, error) {
+	matches, err := Load(repository)
+	if err != nil {
+		return nil, err
+	}
+	return Find(matches, query), nil
+}
+
+// Label describes a match, e.g. `work › env › JAVA_HOME = /usr/lib/jvm/java-21`.
+// Secret values are masked and values spanning lines are cut to their first line.
+func Label(match Match) string {
+	return fmt.Sprintf("%s › %s › %s = %s", match.Profile.Name, match.Detail.DetailType, match.Detail.Key, Value(match.Detail))
+}
+
+// Value is the value of the detail as shown in a label
+func Value(detail data.Detail) string {
+	if detail.Secret {
+		return data.SecretMask
+	}
+	if first, _, ok := strings.Cut(detail.Value, "\n"); ok {
+		return first + " …"
+	}
+	return detail.Value
+}
+
+// Write lists the matches, one per line.
+func Write(w io.Writer, matches []Match) error {
+	if len(matches) == 0 {
+		_, err := io.WriteString(w, "No matches\n")
+		return err
+	}
+	for _, match := range matches {
+		if _, err := fmt.Fprintln(w, Label(match)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
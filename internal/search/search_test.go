@@ -0,0 +1,78 @@
+package search
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type searchStub struct {
+	profiles []data.Profile
+	details  map[int][]data.Detail
+}
+
+func (ss searchStub) GetAllProfiles() ([]data.Profile, error) {
+	return ss.profiles, nil
+}
+
+func (ss searchStub) GetAllDetails(profileId int) ([]data.Detail, error) {
+	return ss.details[profileId], nil
+}
+
+func newSearchStub() searchStub {
+	return searchStub{
+		profiles: []data.Profile{{ID: 1, Name: "work"}, {ID: 2, Name: "java"}},
+		details: map[int][]data.Detail{
+			1: {
+				{ID: 11, Key: "JAVA_HOME", Value: "/usr/lib/jvm/java-21", DetailType: data.EnvDetail},
+				{ID: 12, Key: "TOKEN", Value: "enc:v1:java", DetailType: data.EnvDetail, Secret: true},
+				{ID: 13, Key: "j", Value: "java -jar", DetailType: data.AliasDetail},
+			},
+			2: {
+				{ID: 21, Key: "mkjar", Value: "cd \"$1\"\njar cf app.jar .", DetailType: data.FunctionDetail},
+				{ID: 22, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail},
+			},
+		},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	matches, err := Search(newSearchStub(), "JAVA")
+	require.Nil(t, err)
+	var labels []string
+	for _, match := range matches {
+		labels = append(labels, Label(match))
+	}
+	// secrets don't match on their value
+	assert.Equal(t, []string{
+		"work › alias › j = java -jar",
+		"work › env › JAVA_HOME = /usr/lib/jvm/java-21",
+	}, labels)
+
+	matches, err = Search(newSearchStub(), "jar")
+	require.Nil(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "java › function › mkjar = cd \"$1\" …", Label(matches[0]))
+	assert.Equal(t, 21, matches[0].Detail.ID)
+
+	all, err := Load(newSearchStub())
+	require.Nil(t, err)
+	assert.Len(t, all, 5)
+	assert.Equal(t, all, Find(all, "  "))
+	assert.Equal(t, "<secret>", Value(all[4].Detail))
+}
+
+func TestWrite(t *testing.T) {
+	matches, err := Search(newSearchStub(), "editor")
+	require.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&buf, matches))
+	assert.Equal(t, "java › env › EDITOR = vim\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, Write(&buf, nil))
+	assert.Equal(t, "No matches\n", buf.String())
+}
@@ -0,0 +1,121 @@
+package trash
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+const timeLayout = "2006-01-02 15:04"
+
+type TrashRepository interface {
+	GetTrash() (data.Trash, error)
+	RestoreProfile(profile data.Profile) error
+	RestoreDetail(detail data.Detail) error
+	PurgeProfile(profile data.Profile) error
+	PurgeDetail(detail data.Detail) error
+}
+
+// ProfileID and DetailID are how entries of the trash are passed to restore and purge,
+// e.g. p3 for the profile with id 3.
+func ProfileID(profile data.TrashedProfile) string {
+	return "p" + strconv.Itoa(profile.ID)
+}
+
+func DetailID(detail data.TrashedDetail) string {
+	return "d" + strconv.Itoa(detail.ID)
+}
+
+// ProfileLabel and DetailLabel describe an entry, e.g. `work (3 details)` or
+// `work › env › EDITOR`.
+func ProfileLabel(profile data.TrashedProfile) string {
+	if profile.Details == 0 {
+		return profile.Name
+	}
+	return fmt.Sprintf("%s %s", profile.Name, detailCount(profile.Details))
+}
+
+func detailCount(n int) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return "(1 detail)"
+	default:
+		return fmt.Sprintf("(%d details)", n)
+	}
+}
+
+func DetailLabel(detail data.TrashedDetail) string {
+	label := fmt.Sprintf("%s › %s › %s", detail.ProfileName, detail.DetailType, detail.Key)
+	if detail.DetailType == data.PathDetail {
+		label += " " + detail.Value
+	}
+	return label
+}
+
+// Write lists the trash with the ids taken by Restore and Purge.
+func Write(w io.Writer, trash data.Trash) error {
+	if trash.Empty() {
+		_, err := io.WriteString(w, "Trash is empty\n")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tPROFILE\tKEY\tDELETED")
+	for _, profile := range trash.Profiles {
+		fmt.Fprintf(tw, "%s\tprofile\t%s\t%s\t%s\n", ProfileID(profile), profile.Name, detailCount(profile.Details), profile.DeletedAt.Format(timeLayout))
+	}
+	for _, detail := range trash.Details {
+		key := detail.Key
+		if detail.DetailType == data.PathDetail {
+			key += " " + detail.Value
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", DetailID(detail), detail.DetailType, detail.ProfileName, key, detail.DeletedAt.Format(timeLayout))
+	}
+	return tw.Flush()
+}
+
+// Restore takes the entries with the given ids out of the trash, in order. It stops
+// at the first one that can't be restored.
+func Restore(repository TrashRepository, ids []string) error {
+	return apply(repository, ids, repository.RestoreProfile, repository.RestoreDetail)
+}
+
+// Purge removes the entries with the given ids for good.
+func Purge(repository TrashRepository, ids []string) error {
+	return apply(repository, ids, repository.PurgeProfile, repository.PurgeDetail)
+}
+
+func apply(repository TrashRepository, ids []string, profileFn func(data.Profile) error, detailFn func(data.Detail) error) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("pass the ids shown by `maggi trash list`")
+	}
+	trash, err := repository.GetTrash()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := applyOne(trash, strings.TrimSpace(id), profileFn, detailFn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOne(trash data.Trash, id string, profileFn func(data.Profile) error, detailFn func(data.Detail) error) error {
+	for _, profile := range trash.Profiles {
+		if ProfileID(profile) == id {
+			return profileFn(profile.Profile)
+		}
+	}
+	for _, detail := range trash.Details {
+		if DetailID(detail) == id {
+			return detailFn(detail.Detail)
+		}
+	}
+	return fmt.Errorf("%s is not in the trash", id)
+}
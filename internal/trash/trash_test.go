@@ -0,0 +1,77 @@
+package trash
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+type trashStub struct {
+	trash    data.Trash
+	restored []string
+	purged   []string
+}
+
+func (ts *trashStub) GetTrash() (data.Trash, error) {
+	return ts.trash, nil
+}
+
+func (ts *trashStub) RestoreProfile(profile data.Profile) error {
+	ts.restored = append(ts.restored, profile.Name)
+	return nil
+}
+
+func (ts *trashStub) RestoreDetail(detail data.Detail) error {
+	ts.restored = append(ts.restored, detail.Key)
+	return nil
+}
+
+func (ts *trashStub) PurgeProfile(profile data.Profile) error {
+	ts.purged = append(ts.purged, profile.Name)
+	return nil
+}
+
+func (ts *trashStub) PurgeDetail(detail data.Detail) error {
+	ts.purged = append(ts.purged, detail.Key)
+	return nil
+}
+
+func newTrashStub() *trashStub {
+	deletedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	return &trashStub{trash: data.Trash{
+		Profiles: []data.TrashedProfile{
+			{Profile: data.Profile{ID: 3, Name: "scratch", DeletedAt: deletedAt}, Details: 2},
+		},
+		Details: []data.TrashedDetail{
+			{Detail: data.Detail{ID: 12, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, DeletedAt: deletedAt}, ProfileName: "work"},
+			{Detail: data.Detail{ID: 14, Key: "PATH", Value: "/opt/bin", DetailType: data.PathDetail, DeletedAt: deletedAt}, ProfileName: "work"},
+		},
+	}}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&buf, newTrashStub().trash))
+	expected := "ID   TYPE     PROFILE  KEY            DELETED\n" +
+		"p3   profile  scratch  (2 details)    2024-05-01 09:30\n" +
+		"d12  env      work     EDITOR         2024-05-01 09:30\n" +
+		"d14  path     work     PATH /opt/bin  2024-05-01 09:30\n"
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, Write(&buf, data.Trash{}))
+	assert.Equal(t, "Trash is empty\n", buf.String())
+}
+
+func TestRestoreAndPurge(t *testing.T) {
+	stub := newTrashStub()
+	assert.Nil(t, Restore(stub, []string{"d12", "p3"}))
+	assert.Equal(t, []string{"EDITOR", "scratch"}, stub.restored)
+	assert.Nil(t, Purge(stub, []string{"d14"}))
+	assert.Equal(t, []string{"PATH"}, stub.purged)
+	assert.EqualError(t, Purge(stub, []string{"p12"}), "p12 is not in the trash")
+	assert.NotNil(t, Restore(stub, nil))
+}
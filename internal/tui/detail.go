@@ -2,21 +2,31 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/export"
+	"github.com/bento01dev/maggi/internal/generate"
+	"github.com/bento01dev/maggi/internal/history"
 	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type DetailStartMsg struct {
 	currentProfile data.Profile
+	// focusID is a detail to show once the details are loaded, e.g. a search match
+	focusID int
 }
 
 type DetailDoneMsg struct{}
@@ -27,11 +37,45 @@ func (d DetailDoneMsg) Next() pageType {
 
 type retrieveDetailsMsg struct {
 	details []data.Detail
+	sort    sortMode
 	err     error
 }
 
 type detailEditedMsg struct{}
 
+type detailEvaluatedMsg struct {
+	id    int
+	value string
+	err   error
+}
+
+// detailsTransferredMsg reports details copied or moved to another profile
+type detailsTransferredMsg struct {
+	result data.TransferResult
+	target data.Profile
+	move   bool
+}
+
+// detailsExportedMsg reports the selected details written to an export file
+type detailsExportedMsg struct {
+	path  string
+	count int
+}
+
+// valueRestoredMsg reports a detail set back to a value from its history
+type valueRestoredMsg struct {
+	key string
+	at  time.Time
+}
+
+const historyTimeLayout = "2006-01-02 15:04"
+
+type secretRevealedMsg struct {
+	id    int
+	value string
+	err   error
+}
+
 const (
 	defaultDPWidth       int = 120
 	defaultSideBarWidth  int = 30
@@ -50,6 +94,13 @@ const (
 	newDetail
 	updateDetail
 	deleteDetail
+	evaluateDetail
+	copyDetail
+	moveDetail
+	exportDetail
+	historyDetail
+	previewDetails
+	editorDetails
 )
 
 type detailPagePane int
@@ -57,6 +108,9 @@ type detailPagePane int
 const (
 	envPane detailPagePane = iota
 	aliasPane
+	pathPane
+	functionPane
+	snippetPane
 	detailDisplayPane
 	detailActionPane
 )
@@ -67,8 +121,29 @@ const (
 	detailTypeDefault detailType = iota
 	detailTypeEnv
 	detailTypeAlias
+	detailTypePath
+	detailTypeFunction
+	detailTypeSource
+	detailTypeRaw
 )
 
+// sideBarPanes lists the detail lists in the side bar from top to bottom. tab moves
+// through them in this order.
+var sideBarPanes = []detailPagePane{envPane, aliasPane, pathPane, functionPane, snippetPane}
+
+// separators offered for path details. <ctrl+o> cycles through them
+var pathSeparators = []string{":", ";", ",", " "}
+
+// function bodies taller than this scroll in the display
+const maxBodyHeight = 10
+
+// confirmations of bulk actions list this many details before cutting the list short
+const maxSelectionLines = 8
+
+// shell tags offered for raw snippets. <ctrl+e> cycles through them and nil means
+// the snippet is written for every shell
+var rawShellOptions = [][]string{nil, {"sh", "bash", "zsh"}, {"bash", "zsh"}, {"bash"}, {"zsh"}, {"fish"}}
+
 type detailStage int
 
 const (
@@ -76,11 +151,23 @@ const (
 	chooseDetailAction
 	editDetailKey
 	editDetailValue
+	editDetailDescription
 	editDetailConfirm
 	editDetailCancel
 	deleteDetailView
 	deleteDetailConfirm
 	deleteDetailCancel
+	transferDetailProfile
+	transferDetailPolicy
+	transferDetailConfirm
+	transferDetailCancel
+	exportDetailConfirm
+	exportDetailCancel
+	historyDetailChoose
+	historyDetailConfirm
+	historyDetailCancel
+	editorDetailConfirm
+	editorDetailCancel
 )
 
 type detailActionItem struct {
@@ -97,17 +184,61 @@ func renderDetailActionItem(i list.Item) string {
 	return a.description
 }
 
+// transferItem is an entry in the picker shown when copying or moving details. it is
+// either a target profile or a way to handle keys already taken in the target.
+type transferItem struct {
+	profile     data.Profile
+	policy      data.ConflictPolicy
+	description string
+}
+
+func (t transferItem) FilterValue() string { return "" }
+func renderTransferItem(i list.Item) string {
+	t, ok := i.(transferItem)
+	if !ok {
+		return ""
+	}
+	return t.description
+}
+
+// historyItem is a past value of the current detail, offered to restore
+type historyItem struct {
+	entry       data.HistoryEntry
+	description string
+}
+
+func (h historyItem) FilterValue() string { return "" }
+func renderHistoryItem(i list.Item) string {
+	h, ok := i.(historyItem)
+	if !ok {
+		return ""
+	}
+	return h.description
+}
+
 type detailItem struct {
-	id     int
-	key    string
-	value  string
-	action bool
+	id          int
+	key         string
+	value       string
+	description string
+	secret      bool
+	kind        data.ValueKind
+	detailType  data.DetailType
+	position    data.PathPosition
+	separator   string
+	guard       bool
+	shells      []string
+	action      bool
+	selected    bool
 }
 
 func (d detailItem) FilterValue() string {
 	if d.action {
 		return ""
 	}
+	if d.detailType == data.PathDetail {
+		return d.key + " " + d.value
+	}
 	return d.key
 }
 func renderDetailItem(i list.Item) string {
@@ -115,7 +246,26 @@ func renderDetailItem(i list.Item) string {
 	if !ok {
 		return ""
 	}
-	return p.key
+	if p.action {
+		return p.key
+	}
+	if p.selected {
+		return "* " + detailLabel(p.detailType, p.key, p.value)
+	}
+	return detailLabel(p.detailType, p.key, p.value)
+}
+
+func detailLabel(detailType data.DetailType, key, value string) string {
+	switch detailType {
+	case data.PathDetail:
+		return fmt.Sprintf("%s: %s", key, value)
+	case data.FunctionDetail:
+		return key + "()"
+	case data.SourceDetail, data.RawDetail:
+		return fmt.Sprintf("%s (%s)", key, detailType)
+	default:
+		return key
+	}
 }
 
 type detailHelpKeys struct {
@@ -125,16 +275,32 @@ type detailHelpKeys struct {
 	Down       key.Binding
 	Esc        key.Binding
 	Search     key.Binding
+	SearchAll  key.Binding
+	Select     key.Binding
+	SelectAll  key.Binding
+	Undo       key.Binding
+	Redo       key.Binding
+	Sort       key.Binding
+	Reveal     key.Binding
+	Secret     key.Binding
+	Position   key.Binding
+	Separator  key.Binding
+	Guard      key.Binding
+	Shells     key.Binding
+	Preview    key.Binding
+	Edit       key.Binding
+	EditAll    key.Binding
 }
 
 func (h detailHelpKeys) ShortHelp() []key.Binding {
-	return []key.Binding{h.ToggleView, h.Search, h.Up, h.Down, h.Esc, h.Quit}
+	return []key.Binding{h.ToggleView, h.Search, h.Up, h.Down, h.Reveal, h.Secret, h.Esc, h.Quit}
 }
 
 func (h detailHelpKeys) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{h.ToggleView, h.Search, h.Up, h.Down},
-		{h.Esc, h.Quit},
+		{h.ToggleView, h.Search, h.Select, h.SelectAll, h.Sort, h.Preview, h.Edit, h.EditAll, h.Up, h.Down},
+		{h.Reveal, h.Secret, h.Position, h.Separator, h.Guard, h.Shells},
+		{h.SearchAll, h.Undo, h.Redo, h.Esc, h.Quit},
 	}
 }
 
@@ -147,14 +313,61 @@ type detailModel interface {
 
 type detailPageRepository interface {
 	GetAllDetails(profileId int) ([]data.Detail, error)
-	AddDetail(key string, value string, detailType data.DetailType, profileID int) (*data.Detail, error)
+	AddDetail(detail data.Detail) (*data.Detail, error)
 	UpdateDetail(detail data.Detail, key string, value string) (*data.Detail, error)
 	DeleteDetail(detail data.Detail) error
+	DeleteDetails(details []data.Detail) error
+	RevealValue(detail data.Detail) (string, error)
+	GetDetailsByProfileName(name string) ([]data.Detail, error)
+	GetAllProfiles() ([]data.Profile, error)
+	CopyDetails(details []data.Detail, target data.Profile, policy data.ConflictPolicy) (data.TransferResult, error)
+	MoveDetails(details []data.Detail, target data.Profile, policy data.ConflictPolicy) (data.TransferResult, error)
+	Undo() (data.Operation, error)
+	Redo() (data.Operation, error)
+	GetHistory(filter data.HistoryFilter) ([]data.HistoryEntry, error)
+	RestoreValue(detail data.Detail, entry data.HistoryEntry) (*data.Detail, error)
+	EditDetails(edit data.DetailEdit) error
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
 }
 
+type evaluateFunc func(kind data.ValueKind, value string, timeout time.Duration) (string, error)
+
+type checkFunctionFunc func(name, body string) error
+
 type DetailPage struct {
 	currentDetail     *data.Detail
+	evaluate          evaluateFunc
+	checkFunction     checkFunctionFunc
 	emptyDisplay      bool
+	secretInput       bool
+	positionInput     data.PathPosition
+	separatorInput    string
+	guardInput        bool
+	shellsInput       []string
+	revealed          bool
+	revealedValue     string
+	selected          map[int]bool
+	transferTarget    *data.Profile
+	transferPolicy    data.ConflictPolicy
+	transferConflicts int
+	historyEntry      *data.HistoryEntry
+	focusID           int
+	preview           previewFunc
+	previewShell      generate.Shell
+	previewLines      []generate.ScriptLine
+	previewView       viewport.Model
+	editor            editorFunc
+	// editorDetail is the detail open in the editor, nil for the whole profile.
+	// editorBase holds the details as they were when the editor opened
+	editorDetail *data.Detail
+	editorBase   []data.Detail
+	editorDraft  *editorDraft
+	editorEdit   data.DetailEdit
+	editorView   viewport.Model
+	sort         sortMode
+	// export files are written here. empty means the working directory
+	exportDir         string
 	infoFlag          bool
 	isErrInfo         bool
 	width             int
@@ -175,25 +388,38 @@ type DetailPage struct {
 	actionsStyle      lipgloss.Style
 	aliasStyle        lipgloss.Style
 	envStyle          lipgloss.Style
+	pathStyle         lipgloss.Style
+	functionStyle     lipgloss.Style
+	snippetStyle      lipgloss.Style
 	displayStyle      lipgloss.Style
 	keyDisplayStyle   lipgloss.Style
 	valueDisplayStyle lipgloss.Style
 	keyInputStyle     lipgloss.Style
 	valueInputStyle   lipgloss.Style
-	keyTextArea       textarea.Model
-	valueTextArea     textarea.Model
-	aliasList         list.Model
-	envList           list.Model
-	actionsList       list.Model
-	keyInput          textinput.Model
-	valueInput        textinput.Model
-	highlightedButton lipgloss.Style
-	mutedButton       lipgloss.Style
-	redButton         lipgloss.Style
-	confirmButton     lipgloss.Style
-	cancelButton      lipgloss.Style
-	deleteButton      lipgloss.Style
-	actions           []string
+	// descriptionDisplayStyle is the label of the description input. descriptions
+	// of details and profiles are shown in valueDisplayStyle
+	descriptionDisplayStyle lipgloss.Style
+	keyTextArea             textarea.Model
+	valueTextArea           textarea.Model
+	aliasList               list.Model
+	envList                 list.Model
+	pathList                list.Model
+	functionList            list.Model
+	snippetList             list.Model
+	actionsList             list.Model
+	transferList            list.Model
+	historyList             list.Model
+	keyInput                textinput.Model
+	valueInput              textinput.Model
+	descriptionInput        textinput.Model
+	bodyInput               textarea.Model
+	highlightedButton       lipgloss.Style
+	mutedButton             lipgloss.Style
+	redButton               lipgloss.Style
+	confirmButton           lipgloss.Style
+	cancelButton            lipgloss.Style
+	deleteButton            lipgloss.Style
+	actions                 []string
 }
 
 func NewDetailPage(repository detailPageRepository) *DetailPage {
@@ -220,6 +446,10 @@ func NewDetailPage(repository detailPageRepository) *DetailPage {
 			key.WithKeys("/"),
 			key.WithHelp("/", "search"),
 		),
+		SearchAll: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("<ctrl+f>", "search all profiles"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("<ctrl+c>"),
 			key.WithHelp("<ctrl+c>", "quit"),
@@ -236,12 +466,71 @@ func NewDetailPage(repository detailPageRepository) *DetailPage {
 			key.WithKeys("<esc>"),
 			key.WithHelp("<esc>", "quit view"),
 		),
+		Select: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("<space>", "select"),
+		),
+		SelectAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "select all"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("<ctrl+r>", "redo"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort: name"),
+		),
+		Reveal: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("<ctrl+s>", "show secret"),
+		),
+		Secret: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("<ctrl+t>", "toggle secret"),
+		),
+		Position: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("<ctrl+p>", "prepend/append path"),
+		),
+		Separator: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("<ctrl+o>", "change separator"),
+		),
+		Guard: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("<ctrl+g>", "toggle existence check"),
+		),
+		Shells: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("<ctrl+e>", "change shells"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "preview script"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit in $EDITOR"),
+		),
+		EditAll: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "edit profile in $EDITOR"),
+		),
 	}
 
 	actionsStyle := lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).Width(defaultDisplayWidth).UnsetPadding()
 	displayStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultDisplayWidth).Height(defaultDisplayHeight).UnsetPadding()
 	aliasStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultSideBarWidth).UnsetPadding()
 	envStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultSideBarWidth).UnsetPadding()
+	pathStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultSideBarWidth).UnsetPadding()
+	functionStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultSideBarWidth).UnsetPadding()
+	snippetStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultSideBarWidth).UnsetPadding()
 	issuesStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).UnsetPadding().BorderForeground(red)
 	titleStyle := lipgloss.NewStyle().Foreground(green)
 	headingStyle := lipgloss.NewStyle().Foreground(blue)
@@ -256,27 +545,39 @@ func NewDetailPage(repository detailPageRepository) *DetailPage {
 	deleteButton := baseButton.Copy().Background(red)
 
 	return &DetailPage{
-		repository:        repository,
-		helpMenu:          helpMenu,
-		keys:              keys,
-		actionsStyle:      actionsStyle,
-		titleStyle:        titleStyle,
-		headingStyle:      headingStyle,
-		issuesStyle:       issuesStyle,
-		displayStyle:      displayStyle,
-		aliasStyle:        aliasStyle,
-		envStyle:          envStyle,
-		keyDisplayStyle:   keyDisplayStyle,
-		valueDisplayStyle: valueDisplayStyle,
-		keyInputStyle:     keyInputStyle,
-		valueInputStyle:   valueInputStyle,
-		keyTextArea:       createTextArea(true),
-		valueTextArea:     createTextArea(true),
-		keyInput:          createTextInput(true, "Enter Name..", "", 50, keyInputStyle),
-		valueInput:        createTextInput(true, "Enter Value..", "", 50, valueInputStyle),
-		highlightedButton: confirmButton,
-		mutedButton:       mutedButton,
-		redButton:         deleteButton,
+		repository: repository,
+		evaluate:   generate.EvaluateValue,
+		preview: func(profileName string, shell generate.Shell) ([]generate.ScriptLine, error) {
+			return generate.Preview(profileName, repository, generate.Options{Shell: shell})
+		},
+		checkFunction:           generate.CheckFunction,
+		editor:                  openEditor,
+		helpMenu:                helpMenu,
+		keys:                    keys,
+		actionsStyle:            actionsStyle,
+		titleStyle:              titleStyle,
+		headingStyle:            headingStyle,
+		issuesStyle:             issuesStyle,
+		displayStyle:            displayStyle,
+		aliasStyle:              aliasStyle,
+		envStyle:                envStyle,
+		pathStyle:               pathStyle,
+		functionStyle:           functionStyle,
+		snippetStyle:            snippetStyle,
+		keyDisplayStyle:         keyDisplayStyle,
+		valueDisplayStyle:       valueDisplayStyle,
+		descriptionDisplayStyle: valueDisplayStyle,
+		keyInputStyle:           keyInputStyle,
+		valueInputStyle:         valueInputStyle,
+		keyTextArea:             createTextArea(true),
+		valueTextArea:           createTextArea(true),
+		keyInput:                createTextInput(true, "Enter Name..", "", 50, keyInputStyle),
+		valueInput:              createTextInput(true, "Enter Value..", "", 50, valueInputStyle),
+		descriptionInput:        createDescriptionInput(true, "", valueInputStyle),
+		bodyInput:               createBodyTextArea(),
+		highlightedButton:       confirmButton,
+		mutedButton:             mutedButton,
+		redButton:               deleteButton,
 	}
 }
 
@@ -297,6 +598,23 @@ func createTextArea(enabled bool) textarea.Model {
 	return ta
 }
 
+// createBodyTextArea is the multi-line input used for function bodies. unlike the other
+// inputs it keeps its value across style updates, so it is only created once.
+func createBodyTextArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Enter Body.."
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.SetWidth(50)
+	ta.SetHeight(6)
+	ta.ShowLineNumbers = true
+	ta.Prompt = ""
+	ta.FocusedStyle = textarea.Style{Base: lipgloss.NewStyle().Foreground(green).BorderStyle(lipgloss.RoundedBorder()).BorderForeground(green)}
+	ta.BlurredStyle = textarea.Style{Base: lipgloss.NewStyle().Foreground(muted).BorderStyle(lipgloss.RoundedBorder()).BorderForeground(muted)}
+	ta.Blur()
+	return ta
+}
+
 func createTextInput(enabled bool, placeholder string, value string, width int, baseStyle lipgloss.Style) textinput.Model {
 	input := textinput.New()
 	input.Placeholder = placeholder
@@ -316,31 +634,129 @@ func createTextInput(enabled bool, placeholder string, value string, width int,
 	return input
 }
 
+// createDescriptionInput is the optional description of a detail. it is not limited to
+// the width of the input, longer descriptions scroll.
+func createDescriptionInput(enabled bool, value string, baseStyle lipgloss.Style) textinput.Model {
+	input := createTextInput(enabled, "Why is this set? (optional)..", value, 50, baseStyle)
+	input.CharLimit = 0
+	return input
+}
+
 func (d *DetailPage) getDetails() tea.Msg {
 	res, err := d.repository.GetAllDetails(d.currentProfile.ID)
 	if err != nil {
 		return retrieveDetailsMsg{err: err}
 	}
-	return retrieveDetailsMsg{details: res}
+	sort, err := loadSortMode(d.repository, detailSortSetting, detailSortModes)
+	if err != nil {
+		return retrieveDetailsMsg{err: err}
+	}
+	return retrieveDetailsMsg{details: res, sort: sort}
 }
 
-func (d *DetailPage) addDetail(key, value string) (*data.Detail, error) {
-	var dataDetailType data.DetailType
+func (d *DetailPage) setSort(sort sortMode) {
+	if sort == "" {
+		sort = sortByName
+	}
+	d.sort = sort
+	d.keys.Sort.SetHelp("s", "sort: "+sort.label())
+}
+
+// handleSort switches the detail lists to the next sort and stores it for the next
+// start. the cursor of the active list stays on the detail it was on.
+func (d *DetailPage) handleSort() tea.Cmd {
+	sort := d.sort.next(detailSortModes)
+	if err := d.repository.SetSetting(detailSortSetting, string(sort)); err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	var current detailItem
+	if l := d.sideBarList(d.activePane); l != nil {
+		current, _ = l.SelectedItem().(detailItem)
+	}
+	d.setSort(sort)
+	d.setDetailLists()
+	if l := d.sideBarList(d.activePane); l != nil {
+		for i, item := range l.Items() {
+			if item, ok := item.(detailItem); ok && item.id == current.id && item.key == current.key {
+				l.Select(i)
+				break
+			}
+		}
+	}
+	d.infoFlag = true
+	d.isErrInfo = false
+	d.infoMsg = "Details sorted by " + sort.label()
+	return nil
+}
+
+func (d *DetailPage) dataDetailType() data.DetailType {
 	switch d.detailType {
 	case detailTypeAlias:
-		dataDetailType = data.AliasDetail
+		return data.AliasDetail
 	case detailTypeEnv:
-		dataDetailType = data.EnvDetail
+		return data.EnvDetail
+	case detailTypePath:
+		return data.PathDetail
+	case detailTypeFunction:
+		return data.FunctionDetail
+	case detailTypeSource:
+		return data.SourceDetail
+	case detailTypeRaw:
+		return data.RawDetail
+	}
+	return ""
+}
+
+func (d *DetailPage) addDetail(key, value string) (*data.Detail, error) {
+	dataDetailType := d.dataDetailType()
+	detail := data.Detail{
+		Key:         key,
+		Value:       value,
+		DetailType:  dataDetailType,
+		ProfileID:   d.currentProfile.ID,
+		Secret:      d.secretInput,
+		Kind:        data.LiteralValue,
+		Description: d.editedDescription(),
+	}
+	switch dataDetailType {
+	case data.PathDetail:
+		// path entries are always literal. `~/` is expanded during generate
+		detail.Position = d.positionInput
+		detail.Separator = d.separatorInput
+	case data.SourceDetail:
+		detail.Guard = d.guardInput
+	case data.RawDetail:
+		detail.Shells = d.shellsInput
+	case data.FunctionDetail:
+	default:
+		detail.Kind, detail.Value = data.ParseValue(value)
 	}
-	detail, err := d.repository.AddDetail(key, value, dataDetailType, d.currentProfile.ID)
+	added, err := d.repository.AddDetail(detail)
 	if err != nil {
 		return nil, err
 	}
-	return detail, nil
+	return added, nil
 }
 
 func (d *DetailPage) updateDetail(key, value string) (*data.Detail, error) {
-	detail, err := d.repository.UpdateDetail(*d.currentDetail, key, value)
+	current := *d.currentDetail
+	current.Secret = d.secretInput
+	current.Description = d.editedDescription()
+	switch current.DetailType {
+	case data.PathDetail:
+		current.Position = d.positionInput
+		current.Separator = d.separatorInput
+	case data.SourceDetail:
+		current.Guard = d.guardInput
+	case data.RawDetail:
+		current.Shells = d.shellsInput
+	case data.FunctionDetail:
+	default:
+		current.Kind, value = data.ParseValue(value)
+	}
+	detail, err := d.repository.UpdateDetail(current, key, value)
 	if err != nil {
 		return nil, err
 	}
@@ -348,6 +764,9 @@ func (d *DetailPage) updateDetail(key, value string) (*data.Detail, error) {
 }
 
 func (d *DetailPage) deleteDetail() error {
+	if len(d.selected) > 0 {
+		return d.repository.DeleteDetails(d.selectedDetails())
+	}
 	err := d.repository.DeleteDetail(*d.currentDetail)
 	return err
 }
@@ -362,24 +781,46 @@ func (d *DetailPage) resetDetails() error {
 }
 
 func (d *DetailPage) setDetailLists() {
+	details := slices.Clone(d.details)
+	sortDetails(details, d.sort)
 	aliasList := []list.Item{}
 	envList := []list.Item{}
+	pathList := []list.Item{}
+	functionList := []list.Item{}
+	snippetList := []list.Item{}
 	aliasList = append(aliasList, detailItem{key: "Add alias...", action: true})
 	envList = append(envList, detailItem{key: "Add env var...", action: true})
-	for _, detail := range d.details {
+	pathList = append(pathList, detailItem{key: "Add path entry...", action: true})
+	functionList = append(functionList, detailItem{key: "Add function...", action: true})
+	snippetList = append(snippetList, detailItem{key: "Add source file...", detailType: data.SourceDetail, action: true})
+	snippetList = append(snippetList, detailItem{key: "Add raw snippet...", detailType: data.RawDetail, action: true})
+	for _, detail := range details {
 		switch detail.DetailType {
 		case data.AliasDetail:
-			aliasList = append(aliasList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value})
+			aliasList = append(aliasList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value, description: detail.Description, secret: detail.Secret, kind: detail.Kind, selected: d.selected[detail.ID]})
 		case data.EnvDetail:
-			envList = append(envList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value})
+			envList = append(envList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value, description: detail.Description, secret: detail.Secret, kind: detail.Kind, selected: d.selected[detail.ID]})
+		case data.PathDetail:
+			pathList = append(pathList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value, description: detail.Description, kind: detail.Kind, detailType: detail.DetailType, position: detail.Position, separator: detail.Separator, selected: d.selected[detail.ID]})
+		case data.FunctionDetail:
+			functionList = append(functionList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value, description: detail.Description, kind: detail.Kind, detailType: detail.DetailType, selected: d.selected[detail.ID]})
+		case data.SourceDetail, data.RawDetail:
+			snippetList = append(snippetList, detailItem{id: detail.ID, key: detail.Key, value: detail.Value, description: detail.Description, kind: detail.Kind, detailType: detail.DetailType, guard: detail.Guard, shells: detail.Shells, selected: d.selected[detail.ID]})
 		}
 	}
 	d.aliasList = GenerateList(aliasList, renderDetailItem, defaultSideBarWidth, defaultSideBarHeight, true)
 	d.envList = GenerateList(envList, renderDetailItem, defaultSideBarWidth, defaultSideBarHeight, true)
+	d.pathList = GenerateList(pathList, renderDetailItem, defaultSideBarWidth, defaultSideBarHeight, true)
+	d.functionList = GenerateList(functionList, renderDetailItem, defaultSideBarWidth, defaultSideBarHeight, true)
+	d.snippetList = GenerateList(snippetList, renderDetailItem, defaultSideBarWidth, defaultSideBarHeight, true)
 	d.updatePaneStyles()
 }
 
 func (d *DetailPage) setActionsList() {
+	if len(d.selected) > 0 {
+		d.setBulkActionsList()
+		return
+	}
 	var actionItems []list.Item
 	switch d.detailType {
 	case detailTypeAlias:
@@ -392,6 +833,10 @@ func (d *DetailPage) setActionsList() {
 				description: "Delete Alias",
 				next:        deleteDetail,
 			},
+			detailActionItem{
+				description: "Test Evaluate",
+				next:        evaluateDetail,
+			},
 		}
 	case detailTypeEnv:
 		actionItems = []list.Item{
@@ -403,9 +848,101 @@ func (d *DetailPage) setActionsList() {
 				description: "Delete Env",
 				next:        deleteDetail,
 			},
+			detailActionItem{
+				description: "Test Evaluate",
+				next:        evaluateDetail,
+			},
+		}
+	case detailTypePath:
+		actionItems = []list.Item{
+			detailActionItem{
+				description: "Update Path",
+				next:        updateDetail,
+			},
+			detailActionItem{
+				description: "Delete Path",
+				next:        deleteDetail,
+			},
+		}
+	case detailTypeFunction:
+		actionItems = []list.Item{
+			detailActionItem{
+				description: "Update Function",
+				next:        updateDetail,
+			},
+			detailActionItem{
+				description: "Delete Function",
+				next:        deleteDetail,
+			},
+		}
+	case detailTypeSource:
+		actionItems = []list.Item{
+			detailActionItem{
+				description: "Update Source",
+				next:        updateDetail,
+			},
+			detailActionItem{
+				description: "Delete Source",
+				next:        deleteDetail,
+			},
+		}
+	case detailTypeRaw:
+		actionItems = []list.Item{
+			detailActionItem{
+				description: "Update Snippet",
+				next:        updateDetail,
+			},
+			detailActionItem{
+				description: "Delete Snippet",
+				next:        deleteDetail,
+			},
 		}
 	}
-	d.actionsList = GenerateList(actionItems, renderDetailActionItem, defaultDisplayWidth, 2, false)
+	if len(actionItems) > 0 {
+		actionItems = append(actionItems,
+			detailActionItem{
+				description: "Copy to Profile...",
+				next:        copyDetail,
+			},
+			detailActionItem{
+				description: "Move to Profile...",
+				next:        moveDetail,
+			},
+			detailActionItem{
+				description: "History...",
+				next:        historyDetail,
+			},
+		)
+	}
+	h := len(actionItems)
+	if h < 2 {
+		h = 2
+	}
+	d.actionsList = GenerateList(actionItems, renderDetailActionItem, defaultDisplayWidth, h, false)
+	d.updatePaneStyles()
+}
+
+// setBulkActionsList offers the actions that work on every selected detail at once
+func (d *DetailPage) setBulkActionsList() {
+	actionItems := []list.Item{
+		detailActionItem{
+			description: "Delete Selected",
+			next:        deleteDetail,
+		},
+		detailActionItem{
+			description: "Copy Selected to Profile...",
+			next:        copyDetail,
+		},
+		detailActionItem{
+			description: "Move Selected to Profile...",
+			next:        moveDetail,
+		},
+		detailActionItem{
+			description: "Export Selected",
+			next:        exportDetail,
+		},
+	}
+	d.actionsList = GenerateList(actionItems, renderDetailActionItem, defaultDisplayWidth, len(actionItems), false)
 	d.updatePaneStyles()
 }
 
@@ -414,8 +951,12 @@ func (d *DetailPage) updatePaneStyles() {
 	actionsStyle := d.actionsStyle.Copy().BorderForeground(muted)
 	aliasStyle := d.aliasStyle.Copy().BorderForeground(muted)
 	envStyle := d.envStyle.Copy().BorderForeground(muted)
+	pathStyle := d.pathStyle.Copy().BorderForeground(muted)
+	functionStyle := d.functionStyle.Copy().BorderForeground(muted)
+	snippetStyle := d.snippetStyle.Copy().BorderForeground(muted)
 	keyDisplayStyle := d.keyDisplayStyle.Copy().Foreground(muted)
 	valueDisplayStyle := d.valueDisplayStyle.Copy().Foreground(muted)
+	descriptionDisplayStyle := d.descriptionDisplayStyle.Copy().Foreground(muted)
 	var enabled bool
 	confirmButton := d.mutedButton
 	deleteButton := d.mutedButton
@@ -429,6 +970,12 @@ func (d *DetailPage) updatePaneStyles() {
 		envStyle = envStyle.Copy().BorderForeground(green)
 	case aliasPane:
 		aliasStyle = aliasStyle.Copy().BorderForeground(green)
+	case pathPane:
+		pathStyle = pathStyle.Copy().BorderForeground(green)
+	case functionPane:
+		functionStyle = functionStyle.Copy().BorderForeground(green)
+	case snippetPane:
+		snippetStyle = snippetStyle.Copy().BorderForeground(green)
 	case detailDisplayPane:
 		displayStyle = displayStyle.Copy().BorderForeground(green)
 		enabled = true
@@ -442,6 +989,8 @@ func (d *DetailPage) updatePaneStyles() {
 		keyDisplayStyle = keyDisplayStyle.Copy().Foreground(blue)
 	case editDetailValue:
 		valueDisplayStyle = valueDisplayStyle.Copy().Foreground(blue)
+	case editDetailDescription:
+		descriptionDisplayStyle = descriptionDisplayStyle.Copy().Foreground(blue)
 	case editDetailConfirm:
 		confirmButton = d.highlightedButton
 	case editDetailCancel:
@@ -450,21 +999,85 @@ func (d *DetailPage) updatePaneStyles() {
 		deleteButton = d.redButton
 	case deleteDetailCancel:
 		cancelButton = d.highlightedButton
+	case transferDetailConfirm:
+		confirmButton = d.highlightedButton
+	case transferDetailCancel:
+		cancelButton = d.highlightedButton
+	case exportDetailConfirm:
+		confirmButton = d.highlightedButton
+	case exportDetailCancel:
+		cancelButton = d.highlightedButton
+	case historyDetailConfirm:
+		confirmButton = d.highlightedButton
+	case historyDetailCancel:
+		cancelButton = d.highlightedButton
+	case editorDetailConfirm:
+		confirmButton = d.highlightedButton
+	case editorDetailCancel:
+		cancelButton = d.highlightedButton
 	}
 
 	d.displayStyle = displayStyle
 	d.actionsStyle = actionsStyle
 	d.aliasStyle = aliasStyle
 	d.envStyle = envStyle
+	d.pathStyle = pathStyle
+	d.functionStyle = functionStyle
+	d.snippetStyle = snippetStyle
 	d.keyDisplayStyle = keyDisplayStyle
 	d.valueDisplayStyle = valueDisplayStyle
+	d.descriptionDisplayStyle = descriptionDisplayStyle
 	d.keyTextArea = createTextArea(enabled)
 	d.valueTextArea = createTextArea(enabled)
 	d.keyInput = createTextInput(enabled, d.keyInput.Placeholder, d.keyInput.Value(), 50, d.keyInputStyle)
 	d.valueInput = createTextInput(enabled, d.valueInput.Placeholder, d.valueInput.Value(), 50, d.valueInputStyle)
+	d.descriptionInput = createDescriptionInput(enabled, d.descriptionInput.Value(), d.valueInputStyle)
 	d.confirmButton = confirmButton
 	d.cancelButton = cancelButton
 	d.deleteButton = deleteButton
+	if !d.editingBody() {
+		d.bodyInput.Blur()
+	}
+}
+
+func (d *DetailPage) editingBody() bool {
+	if d.currentUserFlow != newDetail && d.currentUserFlow != updateDetail {
+		return false
+	}
+	return d.usesBody() && d.activePane == detailDisplayPane && d.currentStage == editDetailValue
+}
+
+// function bodies and raw snippets are edited in the multi-line body input
+func (d *DetailPage) usesBody() bool {
+	return d.detailType == detailTypeFunction || d.detailType == detailTypeRaw
+}
+
+// editedValue is the value entered in the edit flow. function bodies keep their
+// indentation, only surrounding blank lines are dropped.
+func (d *DetailPage) editedValue() string {
+	if d.usesBody() {
+		body := d.bodyInput.Value()
+		if strings.TrimSpace(body) == "" {
+			return ""
+		}
+		return strings.TrimRight(strings.Trim(body, "\n"), " \t\n")
+	}
+	return strings.TrimSpace(d.valueInput.Value())
+}
+
+func (d *DetailPage) editedDescription() string {
+	return strings.TrimSpace(d.descriptionInput.Value())
+}
+
+func (d *DetailPage) focusDescription() tea.Cmd {
+	return tea.Batch(d.descriptionInput.Focus(), d.descriptionInput.Cursor.BlinkCmd())
+}
+
+func (d *DetailPage) focusValue() tea.Cmd {
+	if d.usesBody() {
+		return d.bodyInput.Focus()
+	}
+	return tea.Batch(d.valueInput.Focus(), d.valueInput.Cursor.BlinkCmd())
 }
 
 func (d *DetailPage) handleDisplayPaneEvent(msg tea.Msg) tea.Cmd {
@@ -473,7 +1086,17 @@ func (d *DetailPage) handleDisplayPaneEvent(msg tea.Msg) tea.Cmd {
 	case editDetailKey:
 		d.keyInput, cmd = d.keyInput.Update(msg)
 	case editDetailValue:
+		if d.usesBody() {
+			d.bodyInput, cmd = d.bodyInput.Update(msg)
+			return cmd
+		}
 		d.valueInput, cmd = d.valueInput.Update(msg)
+	case editDetailDescription:
+		d.descriptionInput, cmd = d.descriptionInput.Update(msg)
+	case transferDetailProfile, transferDetailPolicy:
+		d.transferList, cmd = d.transferList.Update(msg)
+	case historyDetailChoose:
+		d.historyList, cmd = d.historyList.Update(msg)
 	}
 	return cmd
 }
@@ -509,6 +1132,46 @@ func (d *DetailPage) handleEvent(msg tea.Msg) tea.Cmd {
 			d.setCurrentDetail(item, data.AliasDetail)
 			d.setTextAreaValues()
 		}
+	case pathPane:
+		d.pathList, cmd = d.pathList.Update(msg)
+		item, ok := d.pathList.SelectedItem().(detailItem)
+		if !ok {
+			return nil
+		}
+		if item.action {
+			d.emptyDisplay = true
+		} else {
+			d.emptyDisplay = false
+			d.setCurrentDetail(item, data.PathDetail)
+			d.setTextAreaValues()
+		}
+	case functionPane:
+		d.functionList, cmd = d.functionList.Update(msg)
+		item, ok := d.functionList.SelectedItem().(detailItem)
+		if !ok {
+			return nil
+		}
+		if item.action {
+			d.emptyDisplay = true
+		} else {
+			d.emptyDisplay = false
+			d.setCurrentDetail(item, data.FunctionDetail)
+			d.setTextAreaValues()
+		}
+	case snippetPane:
+		d.snippetList, cmd = d.snippetList.Update(msg)
+		item, ok := d.snippetList.SelectedItem().(detailItem)
+		if !ok {
+			return nil
+		}
+		d.detailType = snippetDetailType(item)
+		if item.action {
+			d.emptyDisplay = true
+		} else {
+			d.emptyDisplay = false
+			d.setCurrentDetail(item, item.detailType)
+			d.setTextAreaValues()
+		}
 	case detailActionPane:
 		d.actionsList, cmd = d.actionsList.Update(msg)
 	}
@@ -516,33 +1179,121 @@ func (d *DetailPage) handleEvent(msg tea.Msg) tea.Cmd {
 }
 
 func (d *DetailPage) setCurrentDetail(item detailItem, detailType data.DetailType) {
+	if d.currentDetail == nil || d.currentDetail.ID != item.id {
+		d.revealed = false
+		d.revealedValue = ""
+		d.resetInfoBag()
+	}
 	d.currentDetail = &data.Detail{
-		ID:         item.id,
-		Key:        item.key,
-		Value:      item.value,
-		DetailType: detailType,
-		ProfileID:  d.currentProfile.ID,
+		ID:          item.id,
+		Key:         item.key,
+		Value:       item.value,
+		DetailType:  detailType,
+		ProfileID:   d.currentProfile.ID,
+		Secret:      item.secret,
+		Kind:        item.kind,
+		Position:    item.position,
+		Separator:   item.separator,
+		Guard:       item.guard,
+		Shells:      item.shells,
+		Description: item.description,
 	}
 }
 
+// the snippets pane lists both source and raw details, so the type follows the
+// selected item
+func snippetDetailType(item detailItem) detailType {
+	if item.detailType == data.RawDetail {
+		return detailTypeRaw
+	}
+	return detailTypeSource
+}
+
 func (d *DetailPage) resetInfoBag() {
 	d.infoFlag = false
 	d.isErrInfo = false
 	d.infoMsg = ""
 }
 
+// validate checks the key and value being edited. only problems in the given fields are
+// reported, so the key stage does not complain about a value that is yet to be entered.
+func (d *DetailPage) validate(key, value string, fields ...data.DetailField) []data.ValidationError {
+	detail := data.Detail{
+		Key:        key,
+		Value:      value,
+		DetailType: d.dataDetailType(),
+		Separator:  d.separatorInput,
+	}
+	var problems []data.ValidationError
+	for _, problem := range data.ValidateDetail(detail) {
+		if slices.Contains(fields, problem.Field) {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// reportProblems shows every problem in the info bag, with the field it belongs to. The
+// first problem that blocks saving is returned, if any.
+func (d *DetailPage) reportProblems(problems []data.ValidationError) (data.ValidationError, bool) {
+	var blocking *data.ValidationError
+	var lines []string
+	for i, problem := range problems {
+		label := "Name"
+		if problem.Field == data.ValueField {
+			label = "Value"
+		}
+		prefix := "Error"
+		if problem.Warning {
+			prefix = "Warning"
+		} else if blocking == nil {
+			blocking = &problems[i]
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s: %s", prefix, label, problem.Message))
+	}
+	if len(lines) == 0 {
+		return data.ValidationError{}, false
+	}
+	d.infoFlag = true
+	d.isErrInfo = blocking != nil
+	d.infoMsg = strings.Join(lines, "\n")
+	if blocking == nil {
+		return data.ValidationError{}, false
+	}
+	return *blocking, true
+}
+
+// focusField moves the edit flow back to the field a problem was found in.
+func (d *DetailPage) focusField(field data.DetailField) tea.Cmd {
+	d.activePane = detailDisplayPane
+	if field == data.KeyField {
+		d.currentStage = editDetailKey
+		d.valueInput.Cursor.SetMode(cursor.CursorHide)
+		d.bodyInput.Blur()
+		d.updatePaneStyles()
+		return tea.Batch(d.keyInput.Focus(), d.keyInput.Cursor.BlinkCmd())
+	}
+	d.currentStage = editDetailValue
+	d.updatePaneStyles()
+	return d.focusValue()
+}
+
+// path details share their key (e.g. PATH) by design, so they are left out of the check
 func (d *DetailPage) checkIfKeyExists(key string) bool {
 	var exists bool
+	if d.detailType == detailTypePath {
+		return false
+	}
 	if d.currentUserFlow == newDetail {
 		for _, detail := range d.details {
-			if detail.Key == key {
+			if detail.Key == key && detail.DetailType != data.PathDetail {
 				return true
 			}
 		}
 	}
 	if d.currentUserFlow == updateDetail {
 		for _, detail := range d.details {
-			if detail.Key == key && detail.ID != d.currentDetail.ID {
+			if detail.Key == key && detail.DetailType != data.PathDetail && detail.ID != d.currentDetail.ID {
 				return true
 			}
 		}
@@ -560,11 +1311,20 @@ func (d *DetailPage) handleEsc() tea.Cmd {
 	d.activePane = envPane
 	d.currentDetail = nil
 	d.emptyDisplay = true
+	d.secretInput = false
+	d.positionInput = ""
+	d.separatorInput = ""
+	d.guardInput = false
+	d.shellsInput = nil
+	d.revealed = false
+	d.revealedValue = ""
 	d.infoMsg = ""
 	d.isErrInfo = false
 	d.infoFlag = false
 	d.keyInput.SetValue("")
 	d.valueInput.SetValue("")
+	d.descriptionInput.SetValue("")
+	d.bodyInput.SetValue("")
 	d.keyTextArea.SetValue("")
 	d.valueTextArea.SetValue("")
 	d.updatePaneStyles()
@@ -576,28 +1336,214 @@ func (d *DetailPage) setTextAreaValues() {
 		return
 	}
 	d.keyTextArea.SetValue(d.currentDetail.Key)
-	d.valueTextArea.SetValue(d.currentDetail.Value)
+	if d.currentDetail.DetailType == data.FunctionDetail || d.currentDetail.DetailType == data.RawDetail {
+		height := strings.Count(d.currentDetail.Value, "\n") + 1
+		if height > maxBodyHeight {
+			height = maxBodyHeight
+		}
+		d.valueTextArea.SetHeight(height)
+		d.valueTextArea.SetValue(d.currentDetail.Value)
+		return
+	}
+	if d.currentDetail.Secret {
+		value := data.SecretMask
+		if d.revealed {
+			value = data.FormatValue(d.currentDetail.Kind, d.revealedValue)
+		}
+		d.valueTextArea.SetValue(value)
+		return
+	}
+	d.valueTextArea.SetValue(data.FormatValue(d.currentDetail.Kind, d.currentDetail.Value))
 }
 
-func (d *DetailPage) getCmdForStage() tea.Cmd {
-	switch d.currentStage {
-	case editDetailKey:
-		return tea.Batch(d.keyInput.Focus(), d.keyInput.Cursor.BlinkCmd())
-	case editDetailValue:
-		return tea.Batch(d.valueInput.Focus(), d.valueInput.Cursor.BlinkCmd())
+func (d *DetailPage) valueLabel() string {
+	if d.currentDetail == nil {
+		return "Value: "
+	}
+	switch d.currentDetail.DetailType {
+	case data.PathDetail:
+		return fmt.Sprintf("Value (%s): ", d.currentDetail.Position)
+	case data.FunctionDetail:
+		return "Body: "
+	case data.SourceDetail:
+		if d.currentDetail.Guard {
+			return "File (if exists): "
+		}
+		return "File: "
+	case data.RawDetail:
+		if len(d.currentDetail.Shells) > 0 {
+			return fmt.Sprintf("Snippet (%s): ", strings.Join(d.currentDetail.Shells, ", "))
+		}
+		return "Snippet: "
+	}
+	switch d.currentDetail.Kind {
+	case data.CommandValue, data.FileValue:
+		return fmt.Sprintf("Value (%s): ", d.currentDetail.Kind)
 	default:
+		return "Value: "
+	}
+}
+
+func (d *DetailPage) handleEvaluate() tea.Cmd {
+	if d.currentDetail == nil {
 		return nil
 	}
+	detail := *d.currentDetail
+	return func() tea.Msg {
+		value, err := d.repository.RevealValue(detail)
+		if err != nil {
+			return detailEvaluatedMsg{id: detail.ID, err: err}
+		}
+		value, err = d.evaluate(detail.Kind, value, generate.DefaultEvalTimeout)
+		return detailEvaluatedMsg{id: detail.ID, value: value, err: err}
+	}
 }
 
-func (d *DetailPage) handleTab(shift bool) tea.Cmd {
-	switch d.currentUserFlow {
-	case listDetails, viewDetail:
-		d.handleListDetailsTab(shift)
-	case newDetail, updateDetail:
-		d.handleEditDetailTab(shift)
-	case deleteDetail:
-		d.handleDeleteDetailTab(shift)
+func (d *DetailPage) handleDetailEvaluated(msg detailEvaluatedMsg) {
+	d.resetInfoBag()
+	if d.currentDetail == nil || d.currentDetail.ID != msg.id {
+		return
+	}
+	d.infoFlag = true
+	if msg.err != nil {
+		d.isErrInfo = true
+		d.infoMsg = fmt.Sprintf("Unable to evaluate %s: %s", d.currentDetail.Key, msg.err.Error())
+		return
+	}
+	d.infoMsg = fmt.Sprintf("%s evaluates to: %s", d.currentDetail.Key, msg.value)
+}
+
+func (d *DetailPage) handleReveal() tea.Cmd {
+	if d.currentUserFlow != listDetails && d.currentUserFlow != viewDetail {
+		return nil
+	}
+	if d.currentDetail == nil || !d.currentDetail.Secret || d.emptyDisplay {
+		return nil
+	}
+	if d.revealed {
+		d.revealed = false
+		d.setTextAreaValues()
+		return nil
+	}
+	detail := *d.currentDetail
+	return func() tea.Msg {
+		value, err := d.repository.RevealValue(detail)
+		return secretRevealedMsg{id: detail.ID, value: value, err: err}
+	}
+}
+
+func (d *DetailPage) handleSecretRevealed(msg secretRevealedMsg) {
+	d.resetInfoBag()
+	if d.currentDetail == nil || d.currentDetail.ID != msg.id {
+		return
+	}
+	if msg.err != nil {
+		d.infoFlag = true
+		d.isErrInfo = true
+		d.infoMsg = fmt.Sprintf("Unable to reveal %s: %s", d.currentDetail.Key, msg.err.Error())
+		return
+	}
+	d.revealed = true
+	d.revealedValue = msg.value
+	d.setTextAreaValues()
+}
+
+func (d *DetailPage) handleToggleSecret() {
+	if d.currentUserFlow != newDetail && d.currentUserFlow != updateDetail {
+		return
+	}
+	if d.detailType != detailTypeEnv {
+		return
+	}
+	d.secretInput = !d.secretInput
+}
+
+func (d *DetailPage) handleTogglePosition() {
+	if d.currentUserFlow != newDetail && d.currentUserFlow != updateDetail {
+		return
+	}
+	if d.detailType != detailTypePath {
+		return
+	}
+	if d.positionInput == data.AppendPath {
+		d.positionInput = data.PrependPath
+		return
+	}
+	d.positionInput = data.AppendPath
+}
+
+func (d *DetailPage) handleCycleSeparator() {
+	if d.currentUserFlow != newDetail && d.currentUserFlow != updateDetail {
+		return
+	}
+	if d.detailType != detailTypePath {
+		return
+	}
+	for i, sep := range pathSeparators {
+		if sep == d.separatorInput {
+			d.separatorInput = pathSeparators[(i+1)%len(pathSeparators)]
+			return
+		}
+	}
+	d.separatorInput = pathSeparators[0]
+}
+
+func (d *DetailPage) handleToggleGuard() {
+	if d.currentUserFlow != newDetail && d.currentUserFlow != updateDetail {
+		return
+	}
+	if d.detailType != detailTypeSource {
+		return
+	}
+	d.guardInput = !d.guardInput
+}
+
+func (d *DetailPage) handleCycleShells() {
+	if d.currentUserFlow != newDetail && d.currentUserFlow != updateDetail {
+		return
+	}
+	if d.detailType != detailTypeRaw {
+		return
+	}
+	current := strings.Join(d.shellsInput, ",")
+	for i, shells := range rawShellOptions {
+		if strings.Join(shells, ",") == current {
+			d.shellsInput = rawShellOptions[(i+1)%len(rawShellOptions)]
+			return
+		}
+	}
+	d.shellsInput = rawShellOptions[0]
+}
+
+func (d *DetailPage) getCmdForStage() tea.Cmd {
+	switch d.currentStage {
+	case editDetailKey:
+		return tea.Batch(d.keyInput.Focus(), d.keyInput.Cursor.BlinkCmd())
+	case editDetailValue:
+		return d.focusValue()
+	case editDetailDescription:
+		return d.focusDescription()
+	default:
+		return nil
+	}
+}
+
+func (d *DetailPage) handleTab(shift bool) tea.Cmd {
+	switch d.currentUserFlow {
+	case listDetails, viewDetail:
+		d.handleListDetailsTab(shift)
+	case newDetail, updateDetail:
+		d.handleEditDetailTab(shift)
+	case deleteDetail:
+		d.handleDeleteDetailTab(shift)
+	case copyDetail, moveDetail:
+		d.handleTransferDetailTab(shift)
+	case exportDetail:
+		d.handleExportDetailTab()
+	case historyDetail:
+		d.handleHistoryDetailTab(shift)
+	case editorDetails:
+		d.handleEditorDetailTab()
 	}
 	d.setActionsList()
 	d.updatePaneStyles()
@@ -605,16 +1551,72 @@ func (d *DetailPage) handleTab(shift bool) tea.Cmd {
 	return d.getCmdForStage()
 }
 
+func isSideBarPane(pane detailPagePane) bool {
+	for _, p := range sideBarPanes {
+		if p == pane {
+			return true
+		}
+	}
+	return false
+}
+
+// nextSideBarPane wraps around, so the last list leads back to envs
+func nextSideBarPane(pane detailPagePane) detailPagePane {
+	for i, p := range sideBarPanes {
+		if p == pane {
+			return sideBarPanes[(i+1)%len(sideBarPanes)]
+		}
+	}
+	return envPane
+}
+
+func prevSideBarPane(pane detailPagePane) detailPagePane {
+	for i, p := range sideBarPanes {
+		if p == pane {
+			return sideBarPanes[(i+len(sideBarPanes)-1)%len(sideBarPanes)]
+		}
+	}
+	return envPane
+}
+
+func paneDetailType(pane detailPagePane) detailType {
+	switch pane {
+	case aliasPane:
+		return detailTypeAlias
+	case pathPane:
+		return detailTypePath
+	case functionPane:
+		return detailTypeFunction
+	case snippetPane:
+		return detailTypeSource
+	default:
+		return detailTypeEnv
+	}
+}
+
+func detailTypePane(t detailType) detailPagePane {
+	switch t {
+	case detailTypeAlias:
+		return aliasPane
+	case detailTypePath:
+		return pathPane
+	case detailTypeFunction:
+		return functionPane
+	case detailTypeSource, detailTypeRaw:
+		return snippetPane
+	default:
+		return envPane
+	}
+}
+
 func (d *DetailPage) handleListDetailsTab(shift bool) {
 	if shift {
 		switch d.activePane {
 		case envPane:
-			d.activePane = aliasPane
-			d.detailType = detailTypeAlias
+			d.activePane = prevSideBarPane(envPane)
 		case aliasPane:
-			if d.emptyDisplay {
+			if d.emptyDisplay && len(d.selected) == 0 {
 				d.activePane = envPane
-				d.detailType = detailTypeEnv
 			} else {
 				d.activePane = detailActionPane
 			}
@@ -622,27 +1624,39 @@ func (d *DetailPage) handleListDetailsTab(shift bool) {
 			d.activePane = detailDisplayPane
 		case detailDisplayPane:
 			d.activePane = envPane
-			d.detailType = detailTypeEnv
+		default:
+			d.activePane = prevSideBarPane(d.activePane)
 		}
+		d.setSideBarDetailType()
 		return
 	}
 
 	switch d.activePane {
 	case envPane:
-		if d.emptyDisplay {
+		if d.emptyDisplay && len(d.selected) == 0 {
 			d.activePane = aliasPane
-			d.detailType = detailTypeAlias
 		} else {
 			d.activePane = detailDisplayPane
 		}
-	case aliasPane:
-		d.activePane = envPane
-		d.detailType = detailTypeEnv
 	case detailActionPane:
 		d.activePane = aliasPane
-		d.detailType = detailTypeAlias
 	case detailDisplayPane:
 		d.activePane = detailActionPane
+	default:
+		d.activePane = nextSideBarPane(d.activePane)
+	}
+	d.setSideBarDetailType()
+}
+
+func (d *DetailPage) setSideBarDetailType() {
+	if !isSideBarPane(d.activePane) {
+		return
+	}
+	d.detailType = paneDetailType(d.activePane)
+	if d.activePane == snippetPane {
+		if item, ok := d.snippetList.SelectedItem().(detailItem); ok {
+			d.detailType = snippetDetailType(item)
+		}
 	}
 }
 
@@ -650,15 +1664,17 @@ func (d *DetailPage) handleEditDetailTab(shift bool) {
 	if shift {
 		switch d.activePane {
 		case envPane:
-			d.activePane = aliasPane
+			d.activePane = prevSideBarPane(envPane)
 		case aliasPane:
 			d.activePane = detailActionPane
 			d.currentStage = editDetailCancel
+		case pathPane, functionPane, snippetPane:
+			d.activePane = prevSideBarPane(d.activePane)
 		case detailActionPane:
 			switch d.currentStage {
 			case editDetailConfirm:
 				d.activePane = detailDisplayPane
-				d.currentStage = editDetailValue
+				d.currentStage = editDetailDescription
 			case editDetailCancel:
 				d.currentStage = editDetailConfirm
 			}
@@ -669,6 +1685,8 @@ func (d *DetailPage) handleEditDetailTab(shift bool) {
 				d.currentStage = chooseDetailAction
 			case editDetailValue:
 				d.currentStage = editDetailKey
+			case editDetailDescription:
+				d.currentStage = editDetailValue
 			}
 		}
 		return
@@ -678,13 +1696,15 @@ func (d *DetailPage) handleEditDetailTab(shift bool) {
 	case envPane:
 		d.activePane = detailDisplayPane
 		d.currentStage = editDetailKey
-	case aliasPane:
-		d.activePane = envPane
+	case aliasPane, pathPane, functionPane, snippetPane:
+		d.activePane = nextSideBarPane(d.activePane)
 	case detailDisplayPane:
 		switch d.currentStage {
 		case editDetailKey:
 			d.currentStage = editDetailValue
 		case editDetailValue:
+			d.currentStage = editDetailDescription
+		case editDetailDescription:
 			d.currentStage = editDetailConfirm
 			d.activePane = detailActionPane
 		}
@@ -703,10 +1723,12 @@ func (d *DetailPage) handleDeleteDetailTab(shift bool) {
 	if shift {
 		switch d.activePane {
 		case envPane:
-			d.activePane = aliasPane
+			d.activePane = prevSideBarPane(envPane)
 		case aliasPane:
 			d.activePane = detailActionPane
 			d.currentStage = deleteDetailConfirm
+		case pathPane, functionPane, snippetPane:
+			d.activePane = prevSideBarPane(d.activePane)
 		case detailActionPane:
 			switch d.currentStage {
 			case deleteDetailConfirm:
@@ -726,8 +1748,8 @@ func (d *DetailPage) handleDeleteDetailTab(shift bool) {
 	case envPane:
 		d.activePane = detailDisplayPane
 		d.currentStage = deleteDetailView
-	case aliasPane:
-		d.activePane = envPane
+	case aliasPane, pathPane, functionPane, snippetPane:
+		d.activePane = nextSideBarPane(d.activePane)
 	case detailDisplayPane:
 		d.activePane = detailActionPane
 		d.currentStage = deleteDetailConfirm
@@ -751,6 +1773,14 @@ func (d *DetailPage) handleEnter() tea.Cmd {
 		cmd = d.handleEditDetailEnter()
 	case deleteDetail:
 		cmd = d.handleDeleteDetailEnter()
+	case copyDetail, moveDetail:
+		cmd = d.handleTransferDetailEnter()
+	case exportDetail:
+		cmd = d.handleExportDetailEnter()
+	case historyDetail:
+		cmd = d.handleHistoryDetailEnter()
+	case editorDetails:
+		cmd = d.handleEditorDetailEnter()
 	default:
 		return nil
 	}
@@ -760,17 +1790,17 @@ func (d *DetailPage) handleEnter() tea.Cmd {
 
 func (d *DetailPage) handleCancel() {
 	d.currentStage = chooseDetailAction
-	switch d.detailType {
-	case detailTypeAlias:
-		d.activePane = aliasPane
-	case detailTypeEnv:
-		d.activePane = envPane
-	default:
-		d.activePane = envPane
-	}
+	d.activePane = detailTypePane(d.detailType)
 	d.emptyDisplay = true
+	d.secretInput = false
+	d.positionInput = ""
+	d.separatorInput = ""
+	d.guardInput = false
+	d.shellsInput = nil
 	d.keyInput.SetValue("")
 	d.valueInput.SetValue("")
+	d.descriptionInput.SetValue("")
+	d.bodyInput.SetValue("")
 	d.keyTextArea.SetValue("")
 	d.valueTextArea.SetValue("")
 	d.currentUserFlow = listDetails
@@ -790,9 +1820,24 @@ func (d *DetailPage) handleListDetailsEnter() tea.Cmd {
 		if !ok {
 			return tea.Quit
 		}
+		if item.next == evaluateDetail {
+			return d.handleEvaluate()
+		}
+		if item.next == copyDetail || item.next == moveDetail {
+			return d.startTransfer(item.next)
+		}
+		if item.next == historyDetail {
+			return d.startHistory()
+		}
 		d.currentUserFlow = item.next
 		d.activePane = detailDisplayPane
 		switch d.currentUserFlow {
+		case exportDetail:
+			d.resetInfoBag()
+			d.currentStage = exportDetailConfirm
+			d.activePane = detailActionPane
+			d.updatePaneStyles()
+			return nil
 		case deleteDetail:
 			d.currentStage = deleteDetailView
 			d.setActionsList()
@@ -801,8 +1846,24 @@ func (d *DetailPage) handleListDetailsEnter() tea.Cmd {
 			return nil
 		case updateDetail:
 			d.currentStage = editDetailKey
+			d.secretInput = d.currentDetail.Secret
+			d.positionInput = d.currentDetail.Position
+			d.separatorInput = d.currentDetail.Separator
+			d.guardInput = d.currentDetail.Guard
+			d.shellsInput = d.currentDetail.Shells
 			d.keyInput.SetValue(d.currentDetail.Key)
-			d.valueInput.SetValue(d.currentDetail.Value)
+			value, err := d.repository.RevealValue(*d.currentDetail)
+			if err != nil {
+				d.infoFlag = true
+				d.isErrInfo = true
+				d.infoMsg = fmt.Sprintf("Unable to reveal %s. Enter a new value to replace it: %s", d.currentDetail.Key, err.Error())
+				value = ""
+			} else {
+				value = data.FormatValue(d.currentDetail.Kind, value)
+			}
+			d.valueInput.SetValue(value)
+			d.bodyInput.SetValue(value)
+			d.descriptionInput.SetValue(d.currentDetail.Description)
 		}
 	case aliasPane:
 		d.detailType = detailTypeAlias
@@ -821,6 +1882,7 @@ func (d *DetailPage) handleListDetailsEnter() tea.Cmd {
 		}
 		d.currentUserFlow = newDetail
 		d.currentStage = editDetailKey
+		d.secretInput = false
 	case envPane:
 		d.detailType = detailTypeEnv
 		item, ok := d.envList.SelectedItem().(detailItem)
@@ -838,6 +1900,68 @@ func (d *DetailPage) handleListDetailsEnter() tea.Cmd {
 		}
 		d.currentUserFlow = newDetail
 		d.currentStage = editDetailKey
+		d.secretInput = false
+	case pathPane:
+		d.detailType = detailTypePath
+		item, ok := d.pathList.SelectedItem().(detailItem)
+		if !ok {
+			return tea.Quit
+		}
+		d.activePane = detailDisplayPane
+		if !item.action {
+			d.setCurrentDetail(item, data.PathDetail)
+			d.currentUserFlow = viewDetail
+			d.setActionsList()
+			d.updatePaneStyles()
+			d.setTextAreaValues()
+			return nil
+		}
+		d.currentUserFlow = newDetail
+		d.currentStage = editDetailKey
+		d.secretInput = false
+		d.positionInput = data.PrependPath
+		d.separatorInput = data.DefaultPathSeparator
+		d.keyInput.SetValue("PATH")
+	case functionPane:
+		d.detailType = detailTypeFunction
+		item, ok := d.functionList.SelectedItem().(detailItem)
+		if !ok {
+			return tea.Quit
+		}
+		d.activePane = detailDisplayPane
+		if !item.action {
+			d.setCurrentDetail(item, data.FunctionDetail)
+			d.currentUserFlow = viewDetail
+			d.setActionsList()
+			d.updatePaneStyles()
+			d.setTextAreaValues()
+			return nil
+		}
+		d.currentUserFlow = newDetail
+		d.currentStage = editDetailKey
+		d.secretInput = false
+		d.bodyInput.SetValue("")
+	case snippetPane:
+		item, ok := d.snippetList.SelectedItem().(detailItem)
+		if !ok {
+			return tea.Quit
+		}
+		d.detailType = snippetDetailType(item)
+		d.activePane = detailDisplayPane
+		if !item.action {
+			d.setCurrentDetail(item, item.detailType)
+			d.currentUserFlow = viewDetail
+			d.setActionsList()
+			d.updatePaneStyles()
+			d.setTextAreaValues()
+			return nil
+		}
+		d.currentUserFlow = newDetail
+		d.currentStage = editDetailKey
+		d.secretInput = false
+		d.guardInput = true
+		d.shellsInput = nil
+		d.bodyInput.SetValue("")
 	}
 	d.setActionsList()
 	d.updatePaneStyles()
@@ -847,7 +1971,7 @@ func (d *DetailPage) handleListDetailsEnter() tea.Cmd {
 
 func (d *DetailPage) handleEditDetailEnter() tea.Cmd {
 	switch d.activePane {
-	case aliasPane, envPane:
+	case aliasPane, envPane, pathPane, functionPane, snippetPane:
 		return d.handleListDetailsEnter()
 	}
 
@@ -868,9 +1992,13 @@ func (d *DetailPage) handleEditDetailEnter() tea.Cmd {
 			d.infoMsg = fmt.Sprintf("Key %s already exists in profile. You can <esc> to edit or delete the existing entry before creating a new one!", key)
 			return tea.Batch(d.keyInput.Focus(), d.keyInput.Cursor.BlinkCmd())
 		}
+
+		if problem, blocked := d.reportProblems(d.validate(key, "", data.KeyField)); blocked {
+			return d.focusField(problem.Field)
+		}
 		d.currentStage = editDetailValue
 		d.updatePaneStyles()
-		return tea.Batch(d.valueInput.Focus(), d.valueInput.Cursor.BlinkCmd())
+		return d.focusValue()
 	case editDetailValue:
 		key := strings.TrimSpace(d.keyInput.Value())
 		if key == "" {
@@ -891,20 +2019,28 @@ func (d *DetailPage) handleEditDetailEnter() tea.Cmd {
 			return tea.Batch(d.keyInput.Focus(), d.keyInput.Cursor.BlinkCmd())
 		}
 
-		value := strings.TrimSpace(d.valueInput.Value())
+		value := d.editedValue()
 		if value == "" {
 			d.infoFlag = true
 			d.isErrInfo = true
 			d.infoMsg = "Please pass a valid value. You can exit flow by pressing <esc> if needed"
-			return tea.Batch(d.valueInput.Focus(), d.valueInput.Cursor.BlinkCmd())
+			return d.focusValue()
+		}
+
+		if problem, blocked := d.reportProblems(d.validate(key, value, data.KeyField, data.ValueField)); blocked {
+			return d.focusField(problem.Field)
 		}
+		d.currentStage = editDetailDescription
+		d.updatePaneStyles()
+		return d.focusDescription()
+	case editDetailDescription:
 		d.currentStage = editDetailConfirm
 		d.activePane = detailActionPane
 		d.updatePaneStyles()
 		return nil
 	case editDetailConfirm:
 		key := strings.TrimSpace(d.keyInput.Value())
-		value := strings.TrimSpace(d.valueInput.Value())
+		value := d.editedValue()
 		if key == "" {
 			d.infoFlag = true
 			d.isErrInfo = true
@@ -931,7 +2067,22 @@ func (d *DetailPage) handleEditDetailEnter() tea.Cmd {
 			d.infoMsg = "Please pass a valid value. You can exit flow by pressing <esc> if needed"
 			d.currentStage = editDetailValue
 			d.activePane = detailDisplayPane
-			return tea.Batch(d.valueInput.Focus(), d.valueInput.Cursor.BlinkCmd())
+			return d.focusValue()
+		}
+
+		if problem, blocked := d.reportProblems(d.validate(key, value, data.KeyField, data.ValueField)); blocked {
+			return d.focusField(problem.Field)
+		}
+
+		if d.detailType == detailTypeFunction {
+			if err := d.checkFunction(key, value); err != nil {
+				d.infoFlag = true
+				d.isErrInfo = true
+				d.infoMsg = fmt.Sprintf("Function %s does not parse: %s", key, err.Error())
+				d.currentStage = editDetailValue
+				d.activePane = detailDisplayPane
+				return d.focusValue()
+			}
 		}
 
 		return func() tea.Msg {
@@ -956,7 +2107,7 @@ func (d *DetailPage) handleEditDetailEnter() tea.Cmd {
 
 func (d *DetailPage) handleDeleteDetailEnter() tea.Cmd {
 	switch d.activePane {
-	case aliasPane, envPane:
+	case aliasPane, envPane, pathPane, functionPane, snippetPane:
 		return d.handleListDetailsEnter()
 	}
 
@@ -982,112 +2133,654 @@ func (d *DetailPage) handleDeleteDetailEnter() tea.Cmd {
 	return nil
 }
 
-func (d *DetailPage) generateTitle() string {
-	first := strings.Repeat("-", 3)
-	var second string
-	switch d.currentUserFlow {
-	case listDetails:
-		second = " Details "
-	case viewDetail:
-		second = fmt.Sprintf(" %s | View | %s ", d.currentProfile.Name, d.currentDetail.Key)
-	case newDetail:
-		switch d.detailType {
-		case detailTypeEnv:
-			second = " New Env "
-		case detailTypeAlias:
-			second = " New Alias "
-		}
-	case updateDetail:
-		switch d.detailType {
-		case detailTypeEnv:
-			second = fmt.Sprintf(" %s | Update Env | %s ", d.currentProfile.Name, d.currentDetail.Key)
-		case detailTypeAlias:
-			second = fmt.Sprintf(" %s | Update Alias | %s ", d.currentProfile.Name, d.currentDetail.Key)
+// focusDetail highlights the detail in its list and shows it, as if moved to by hand
+func (d *DetailPage) focusDetail(id int) {
+	for _, detail := range d.details {
+		if detail.ID != id {
+			continue
 		}
-	case deleteDetail:
-		switch d.detailType {
-		case detailTypeEnv:
-			second = fmt.Sprintf(" %s | Delete Env | %s ", d.currentProfile.Name, d.currentDetail.Key)
-		case detailTypeAlias:
-			second = fmt.Sprintf(" %s | Delete Alias | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		for _, pane := range sideBarPanes {
+			l := d.sideBarList(pane)
+			for i, listItem := range l.Items() {
+				item, ok := listItem.(detailItem)
+				if !ok || item.action || item.id != id {
+					continue
+				}
+				l.Select(i)
+				d.activePane = pane
+				d.setSideBarDetailType()
+				d.emptyDisplay = false
+				d.setCurrentDetail(item, detail.DetailType)
+				d.setTextAreaValues()
+				d.updatePaneStyles()
+				return
+			}
 		}
 	}
-	third := strings.Repeat("-", (defaultDPWidth - (len(second) + 3)))
-	return first + second + third
 }
 
-func (d *DetailPage) generateHeading(name string) string {
-	first := strings.Repeat("-", 3)
-	second := fmt.Sprintf(" %s ", name)
-	third := strings.Repeat("-", (defaultSideBarWidth - (len(second) + 3)))
-	return first + second + third
+// sideBarList returns the detail list shown in pane
+func (d *DetailPage) sideBarList(pane detailPagePane) *list.Model {
+	switch pane {
+	case envPane:
+		return &d.envList
+	case aliasPane:
+		return &d.aliasList
+	case pathPane:
+		return &d.pathList
+	case functionPane:
+		return &d.functionList
+	case snippetPane:
+		return &d.snippetList
+	}
+	return nil
 }
 
-func (d *DetailPage) viewListDetails() string {
-	if d.emptyDisplay {
-		return lipgloss.Place(
-			d.width,
-			d.height,
-			lipgloss.Center,
-			lipgloss.Center,
-			lipgloss.JoinVertical(
-				lipgloss.Center,
-				d.titleStyle.Render(d.generateTitle()),
-				lipgloss.JoinHorizontal(
-					lipgloss.Left,
-					lipgloss.JoinVertical(
-						lipgloss.Center,
-						d.headingStyle.Render(d.generateHeading("Envs")),
-						d.envStyle.Render(d.envList.View()),
-						d.headingStyle.Render(d.generateHeading("Aliases")),
-						d.aliasStyle.Render(d.aliasList.View()),
-					),
-					lipgloss.JoinVertical(
-						lipgloss.Center,
-						"",
-						d.displayStyle.Render(""),
-					),
-				),
-				d.helpMenu.View(d.keys),
-			),
-		)
+// handleToggleSelect marks the highlighted detail in the side bar for copy and move.
+// space is passed on as usual while typing.
+func (d *DetailPage) handleToggleSelect(msg tea.KeyMsg) tea.Cmd {
+	if !d.selecting() {
+		return d.handleEvent(msg)
 	}
-
-	return lipgloss.Place(
-		d.width,
-		d.height,
-		lipgloss.Center,
+	l := d.sideBarList(d.activePane)
+	item, ok := l.SelectedItem().(detailItem)
+	if !ok || item.action {
+		return nil
+	}
+	if d.selected == nil {
+		d.selected = make(map[int]bool)
+	}
+	item.selected = !d.selected[item.id]
+	if item.selected {
+		d.selected[item.id] = true
+	} else {
+		delete(d.selected, item.id)
+	}
+	d.setActionsList()
+	// the index of the highlighted item is relative to the filtered items
+	for i, listItem := range l.Items() {
+		if other, ok := listItem.(detailItem); ok && !other.action && other.id == item.id {
+			return l.SetItem(i, item)
+		}
+	}
+	return nil
+}
+
+// selecting reports whether space and a mark details, rather than being typed into an
+// input or the search of a list
+func (d *DetailPage) selecting() bool {
+	if d.currentUserFlow != listDetails && d.currentUserFlow != viewDetail {
+		return false
+	}
+	l := d.sideBarList(d.activePane)
+	return l != nil && !l.SettingFilter()
+}
+
+// handleSelectAll marks every detail shown in the active list, which is only the
+// matches while searching. if they are all marked already, they are unmarked instead.
+func (d *DetailPage) handleSelectAll() tea.Cmd {
+	l := d.sideBarList(d.activePane)
+	var ids []int
+	allSelected := true
+	for _, listItem := range l.VisibleItems() {
+		if item, ok := listItem.(detailItem); ok && !item.action {
+			ids = append(ids, item.id)
+			allSelected = allSelected && d.selected[item.id]
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if d.selected == nil {
+		d.selected = make(map[int]bool)
+	}
+	for _, id := range ids {
+		if allSelected {
+			delete(d.selected, id)
+		} else {
+			d.selected[id] = true
+		}
+	}
+	items := l.Items()
+	for i, listItem := range items {
+		if item, ok := listItem.(detailItem); ok && !item.action {
+			item.selected = d.selected[item.id]
+			items[i] = item
+		}
+	}
+	d.setActionsList()
+	return l.SetItems(items)
+}
+
+// selectedDetails returns the details marked with space, or the one on display when
+// none are marked
+func (d *DetailPage) selectedDetails() []data.Detail {
+	var details []data.Detail
+	for _, detail := range d.details {
+		if d.selected[detail.ID] {
+			details = append(details, detail)
+		}
+	}
+	if len(details) > 0 || d.currentDetail == nil {
+		return details
+	}
+	for _, detail := range d.details {
+		if detail.ID == d.currentDetail.ID {
+			details = append(details, detail)
+		}
+	}
+	return details
+}
+
+func (d *DetailPage) startTransfer(flow detailsUserFlow) tea.Cmd {
+	d.resetInfoBag()
+	profiles, err := d.repository.GetAllProfiles()
+	if err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	var items []list.Item
+	for _, profile := range profiles {
+		if profile.ID == d.currentProfile.ID {
+			continue
+		}
+		items = append(items, transferItem{profile: profile, description: profile.Name})
+	}
+	if len(items) == 0 {
+		d.infoFlag = true
+		d.isErrInfo = true
+		d.infoMsg = "There is no other profile yet. Add one in the profile page first"
+		return nil
+	}
+	d.setTransferList(items)
+	d.currentUserFlow = flow
+	d.currentStage = transferDetailProfile
+	d.activePane = detailDisplayPane
+	d.transferTarget = nil
+	d.transferPolicy = data.SkipConflicts
+	d.transferConflicts = 0
+	d.updatePaneStyles()
+	return nil
+}
+
+func (d *DetailPage) setTransferList(items []list.Item) {
+	h := len(items)
+	if h < 2 {
+		h = 2
+	}
+	d.transferList = GenerateList(items, renderTransferItem, defaultDisplayWidth-10, h, false)
+}
+
+func (d *DetailPage) setPolicyList() {
+	d.setTransferList([]list.Item{
+		transferItem{policy: data.SkipConflicts, description: "Skip them, keeping the values in " + d.transferTarget.Name},
+		transferItem{policy: data.OverwriteConflicts, description: "Overwrite them with the values from " + d.currentProfile.Name},
+		transferItem{policy: data.RenameConflicts, description: "Rename them with a _copy suffix"},
+	})
+}
+
+func (d *DetailPage) handleTransferDetailTab(shift bool) {
+	if shift {
+		switch d.activePane {
+		case detailDisplayPane:
+			if d.transferTarget != nil {
+				d.activePane = detailActionPane
+				d.currentStage = transferDetailCancel
+			}
+		case detailActionPane:
+			switch d.currentStage {
+			case transferDetailCancel:
+				d.currentStage = transferDetailConfirm
+			case transferDetailConfirm:
+				d.activePane = detailDisplayPane
+				d.currentStage = transferDetailProfile
+				if d.transferConflicts > 0 {
+					d.currentStage = transferDetailPolicy
+				}
+			}
+		}
+		return
+	}
+
+	switch d.activePane {
+	case detailDisplayPane:
+		if d.transferTarget != nil {
+			d.activePane = detailActionPane
+			d.currentStage = transferDetailConfirm
+		}
+	case detailActionPane:
+		switch d.currentStage {
+		case transferDetailConfirm:
+			d.currentStage = transferDetailCancel
+		case transferDetailCancel:
+			d.activePane = detailDisplayPane
+			d.currentStage = transferDetailProfile
+		}
+	}
+}
+
+func (d *DetailPage) handleTransferDetailEnter() tea.Cmd {
+	d.resetInfoBag()
+	switch d.currentStage {
+	case transferDetailProfile:
+		item, ok := d.transferList.SelectedItem().(transferItem)
+		if !ok {
+			return nil
+		}
+		existing, err := d.repository.GetAllDetails(item.profile.ID)
+		if err != nil {
+			return func() tea.Msg {
+				return IssueMsg{Inner: err}
+			}
+		}
+		target := item.profile
+		d.transferTarget = &target
+		d.transferPolicy = data.SkipConflicts
+		d.transferConflicts = 0
+		for _, detail := range d.selectedDetails() {
+			if _, ok := data.FindConflict(existing, detail); ok {
+				d.transferConflicts++
+			}
+		}
+		if d.transferConflicts > 0 {
+			d.currentStage = transferDetailPolicy
+			d.setPolicyList()
+		} else {
+			d.currentStage = transferDetailConfirm
+			d.activePane = detailActionPane
+		}
+		d.updatePaneStyles()
+		return nil
+	case transferDetailPolicy:
+		item, ok := d.transferList.SelectedItem().(transferItem)
+		if !ok {
+			return nil
+		}
+		d.transferPolicy = item.policy
+		d.currentStage = transferDetailConfirm
+		d.activePane = detailActionPane
+		d.updatePaneStyles()
+		return nil
+	case transferDetailCancel:
+		d.handleCancel()
+		return nil
+	case transferDetailConfirm:
+		if d.transferTarget == nil {
+			return nil
+		}
+		details := d.selectedDetails()
+		target := *d.transferTarget
+		policy := d.transferPolicy
+		move := d.currentUserFlow == moveDetail
+		return func() tea.Msg {
+			var result data.TransferResult
+			var err error
+			if move {
+				result, err = d.repository.MoveDetails(details, target, policy)
+			} else {
+				result, err = d.repository.CopyDetails(details, target, policy)
+			}
+			if err != nil {
+				return IssueMsg{Inner: err}
+			}
+			return detailsTransferredMsg{result: result, target: target, move: move}
+		}
+	}
+	return nil
+}
+
+func (d *DetailPage) reportTransfer(msg detailsTransferredMsg) {
+	verb := "Copied"
+	if msg.move {
+		verb = "Moved"
+	}
+	info := fmt.Sprintf("%s %s to %s", verb, countDetails(len(msg.result.Transferred)), msg.target.Name)
+	var notes []string
+	if n := len(msg.result.Skipped); n > 0 {
+		notes = append(notes, fmt.Sprintf("%d skipped", n))
+	}
+	if msg.result.Overwritten > 0 {
+		notes = append(notes, fmt.Sprintf("%d overwritten", msg.result.Overwritten))
+	}
+	if msg.result.Renamed > 0 {
+		notes = append(notes, fmt.Sprintf("%d renamed", msg.result.Renamed))
+	}
+	if len(notes) > 0 {
+		info += " (" + strings.Join(notes, ", ") + ")"
+	}
+	d.infoFlag = true
+	d.isErrInfo = false
+	d.infoMsg = info
+}
+
+func (d *DetailPage) startHistory() tea.Cmd {
+	d.resetInfoBag()
+	entries, err := d.repository.GetHistory(data.HistoryFilter{DetailID: d.currentDetail.ID})
+	if err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	if len(entries) == 0 {
+		d.infoFlag = true
+		d.isErrInfo = true
+		d.infoMsg = "There is no history for " + d.currentDetail.Key
+		return nil
+	}
+	items := make([]list.Item, 0, len(entries))
+	for _, entry := range entries {
+		description := fmt.Sprintf("%s  %-3s  %s", entry.CreatedAt.Format(historyTimeLayout), entry.Source, history.Change(entry))
+		items = append(items, historyItem{entry: entry, description: description})
+	}
+	h := len(items)
+	if h < 2 {
+		h = 2
+	}
+	if h > defaultDisplayHeight {
+		h = defaultDisplayHeight
+	}
+	d.historyList = GenerateList(items, renderHistoryItem, defaultDisplayWidth-10, h, false)
+	d.historyEntry = nil
+	d.currentUserFlow = historyDetail
+	d.currentStage = historyDetailChoose
+	d.activePane = detailDisplayPane
+	d.updatePaneStyles()
+	return nil
+}
+
+func (d *DetailPage) handleHistoryDetailTab(shift bool) {
+	if d.historyEntry == nil {
+		return
+	}
+	stages := []detailStage{historyDetailChoose, historyDetailConfirm, historyDetailCancel}
+	i := slices.Index(stages, d.currentStage)
+	if shift {
+		i = (i + len(stages) - 1) % len(stages)
+	} else {
+		i = (i + 1) % len(stages)
+	}
+	d.currentStage = stages[i]
+	d.activePane = detailActionPane
+	if d.currentStage == historyDetailChoose {
+		d.activePane = detailDisplayPane
+	}
+}
+
+func (d *DetailPage) handleHistoryDetailEnter() tea.Cmd {
+	d.resetInfoBag()
+	switch d.currentStage {
+	case historyDetailChoose:
+		item, ok := d.historyList.SelectedItem().(historyItem)
+		if !ok {
+			return nil
+		}
+		if item.entry.Action == data.HistoryDeleted {
+			d.infoFlag = true
+			d.isErrInfo = true
+			d.infoMsg = "A delete has no value to restore. Pick another entry"
+			return nil
+		}
+		entry := item.entry
+		d.historyEntry = &entry
+		d.currentStage = historyDetailConfirm
+		d.activePane = detailActionPane
+		d.updatePaneStyles()
+		return nil
+	case historyDetailCancel:
+		d.historyEntry = nil
+		d.handleCancel()
+		return nil
+	case historyDetailConfirm:
+		if d.historyEntry == nil {
+			return nil
+		}
+		detail := *d.currentDetail
+		entry := *d.historyEntry
+		return func() tea.Msg {
+			if _, err := d.repository.RestoreValue(detail, entry); err != nil {
+				return IssueMsg{Inner: err}
+			}
+			return valueRestoredMsg{key: detail.Key, at: entry.CreatedAt}
+		}
+	}
+	return nil
+}
+
+func (d *DetailPage) handleExportDetailTab() {
+	switch d.currentStage {
+	case exportDetailConfirm:
+		d.currentStage = exportDetailCancel
+	case exportDetailCancel:
+		d.currentStage = exportDetailConfirm
+	}
+}
+
+func (d *DetailPage) handleExportDetailEnter() tea.Cmd {
+	switch d.currentStage {
+	case exportDetailCancel:
+		d.handleCancel()
+		return nil
+	case exportDetailConfirm:
+		details := d.selectedDetails()
+		path := d.exportPath()
+		profile := d.currentProfile
+		return func() tea.Msg {
+			count, err := d.exportDetails(path, profile, details)
+			if err != nil {
+				return IssueMsg{Inner: err}
+			}
+			return detailsExportedMsg{path: path, count: count}
+		}
+	}
+	return nil
+}
+
+// exportPath is the file the selected details are exported to. it is replaced if it
+// exists already.
+func (d *DetailPage) exportPath() string {
+	name := strings.ReplaceAll(d.currentProfile.Name, string(filepath.Separator), "-")
+	return filepath.Join(d.exportDir, fmt.Sprintf("maggi-%s.json", name))
+}
+
+// exportDetails writes details in the format of `maggi export`. secrets are left out,
+// the same as the command does by default. it returns how many details were written.
+func (d *DetailPage) exportDetails(path string, profile data.Profile, details []data.Detail) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	err = export.ExportDetails(file, profile, details, false, d.repository)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for _, detail := range details {
+		if !detail.Secret {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// selectionLabel names the details a bulk action works on in the title
+func (d *DetailPage) selectionLabel() string {
+	details := d.selectedDetails()
+	if len(details) == 1 {
+		return details[0].Key
+	}
+	return countDetails(len(details))
+}
+
+func countDetails(n int) string {
+	if n == 1 {
+		return "1 detail"
+	}
+	return fmt.Sprintf("%d details", n)
+}
+
+func (d *DetailPage) generateTitle() string {
+	first := strings.Repeat("-", 3)
+	var second string
+	switch d.currentUserFlow {
+	case listDetails:
+		second = " Details "
+	case viewDetail:
+		second = fmt.Sprintf(" %s | View | %s ", d.currentProfile.Name, d.currentDetail.Key)
+	case newDetail:
+		switch d.detailType {
+		case detailTypeEnv:
+			second = " New Env "
+		case detailTypeAlias:
+			second = " New Alias "
+		case detailTypePath:
+			second = " New Path "
+		case detailTypeFunction:
+			second = " New Function "
+		case detailTypeSource:
+			second = " New Source "
+		case detailTypeRaw:
+			second = " New Snippet "
+		}
+	case updateDetail:
+		switch d.detailType {
+		case detailTypeEnv:
+			second = fmt.Sprintf(" %s | Update Env | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeAlias:
+			second = fmt.Sprintf(" %s | Update Alias | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypePath:
+			second = fmt.Sprintf(" %s | Update Path | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeFunction:
+			second = fmt.Sprintf(" %s | Update Function | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeSource:
+			second = fmt.Sprintf(" %s | Update Source | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeRaw:
+			second = fmt.Sprintf(" %s | Update Snippet | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		}
+	case deleteDetail:
+		if len(d.selected) > 0 {
+			second = fmt.Sprintf(" %s | Delete Selected | %s ", d.currentProfile.Name, d.selectionLabel())
+			break
+		}
+		switch d.detailType {
+		case detailTypeEnv:
+			second = fmt.Sprintf(" %s | Delete Env | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeAlias:
+			second = fmt.Sprintf(" %s | Delete Alias | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypePath:
+			second = fmt.Sprintf(" %s | Delete Path | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeFunction:
+			second = fmt.Sprintf(" %s | Delete Function | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeSource:
+			second = fmt.Sprintf(" %s | Delete Source | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		case detailTypeRaw:
+			second = fmt.Sprintf(" %s | Delete Snippet | %s ", d.currentProfile.Name, d.currentDetail.Key)
+		}
+	case copyDetail:
+		second = fmt.Sprintf(" %s | Copy to Profile | %s ", d.currentProfile.Name, d.selectionLabel())
+	case moveDetail:
+		second = fmt.Sprintf(" %s | Move to Profile | %s ", d.currentProfile.Name, d.selectionLabel())
+	case exportDetail:
+		second = fmt.Sprintf(" %s | Export Selected | %s ", d.currentProfile.Name, d.selectionLabel())
+	case historyDetail:
+		second = fmt.Sprintf(" %s | History | %s ", d.currentProfile.Name, d.currentDetail.Key)
+	case previewDetails:
+		second = fmt.Sprintf(" %s | Preview | %s ", d.currentProfile.Name, d.previewShell)
+	case editorDetails:
+		name := "Profile"
+		if d.editorDetail != nil {
+			name = d.editorDetail.Key
+		}
+		second = fmt.Sprintf(" %s | Edit in $EDITOR | %s ", d.currentProfile.Name, name)
+	}
+	third := strings.Repeat("-", (defaultDPWidth - (len(second) + 3)))
+	return first + second + third
+}
+
+func (d *DetailPage) generateHeading(name string) string {
+	first := strings.Repeat("-", 3)
+	second := fmt.Sprintf(" %s ", name)
+	third := strings.Repeat("-", (defaultSideBarWidth - (len(second) + 3)))
+	return first + second + third
+}
+
+func (d *DetailPage) viewSideBar() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Center,
+		d.headingStyle.Render(d.generateHeading("Envs")),
+		d.envStyle.Render(d.envList.View()),
+		d.headingStyle.Render(d.generateHeading("Aliases")),
+		d.aliasStyle.Render(d.aliasList.View()),
+		d.headingStyle.Render(d.generateHeading("Paths")),
+		d.pathStyle.Render(d.pathList.View()),
+		d.headingStyle.Render(d.generateHeading("Functions")),
+		d.functionStyle.Render(d.functionList.View()),
+		d.headingStyle.Render(d.generateHeading("Snippets")),
+		d.snippetStyle.Render(d.snippetList.View()),
+	)
+}
+
+func (d *DetailPage) viewListDetails() string {
+	if d.emptyDisplay && len(d.selected) == 0 {
+		return lipgloss.Place(
+			d.width,
+			d.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				d.titleStyle.Render(d.generateTitle()),
+				lipgloss.JoinHorizontal(
+					lipgloss.Left,
+					d.viewSideBar(),
+					lipgloss.JoinVertical(
+						lipgloss.Center,
+						"",
+						d.viewInfo(),
+						d.displayStyle.Render(d.viewProfileDescription()),
+					),
+				),
+				d.helpMenu.View(d.keys),
+			),
+		)
+	}
+
+	display := lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			d.keyDisplayStyle.Render("Name: "),
+			d.keyTextArea.View(),
+		),
+		lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			d.valueDisplayStyle.Render(d.valueLabel()),
+			d.valueTextArea.View(),
+		),
+		d.viewDescription(),
+	)
+	if d.emptyDisplay {
+		display = d.viewSelection(fmt.Sprintf("%s selected:", countDetails(len(d.selected))))
+	}
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
 		lipgloss.Center,
 		lipgloss.JoinVertical(
 			lipgloss.Center,
 			d.titleStyle.Render(d.generateTitle()),
 			lipgloss.JoinHorizontal(
 				lipgloss.Left,
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					d.headingStyle.Render(d.generateHeading("Envs")),
-					d.envStyle.Render(d.envList.View()),
-					d.headingStyle.Render(d.generateHeading("Aliases")),
-					d.aliasStyle.Render(d.aliasList.View()),
-				),
+				d.viewSideBar(),
 				lipgloss.JoinVertical(
 					lipgloss.Center,
 					"",
-					d.displayStyle.Render(
-						lipgloss.JoinVertical(
-							lipgloss.Left,
-							lipgloss.JoinHorizontal(
-								lipgloss.Left,
-								d.keyDisplayStyle.Render("Name: "),
-								d.keyTextArea.View(),
-							),
-							lipgloss.JoinHorizontal(
-								lipgloss.Left,
-								d.valueDisplayStyle.Render("Value: "),
-								d.valueTextArea.View(),
-							),
-						),
-					),
+					d.viewInfo(),
+					d.displayStyle.Render(display),
 					d.actionsStyle.Render(d.actionsList.View()),
 				),
 			),
@@ -1108,16 +2801,11 @@ func (d *DetailPage) viewViewDetail() string {
 			d.titleStyle.Render(d.generateTitle()),
 			lipgloss.JoinHorizontal(
 				lipgloss.Left,
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					d.headingStyle.Render(d.generateHeading("Envs")),
-					d.envStyle.Render(d.envList.View()),
-					d.headingStyle.Render(d.generateHeading("Aliases")),
-					d.aliasStyle.Render(d.aliasList.View()),
-				),
+				d.viewSideBar(),
 				lipgloss.JoinVertical(
 					lipgloss.Center,
 					"",
+					d.viewInfo(),
 					d.displayStyle.Render(
 						lipgloss.JoinVertical(
 							lipgloss.Left,
@@ -1128,9 +2816,10 @@ func (d *DetailPage) viewViewDetail() string {
 							),
 							lipgloss.JoinHorizontal(
 								lipgloss.Left,
-								d.valueDisplayStyle.Render("Value: "),
+								d.valueDisplayStyle.Render(d.valueLabel()),
 								d.valueTextArea.View(),
 							),
+							d.viewDescription(),
 						),
 					),
 					d.actionsStyle.Render(d.actionsList.View()),
@@ -1141,6 +2830,83 @@ func (d *DetailPage) viewViewDetail() string {
 	)
 }
 
+// viewDescription shows the description of the current detail under its value, if it
+// has one
+func (d *DetailPage) viewDescription() string {
+	if d.currentDetail == nil || d.currentDetail.Description == "" {
+		return ""
+	}
+	return d.valueDisplayStyle.Copy().Width(defaultDisplayWidth).Render("Description: " + d.currentDetail.Description)
+}
+
+// viewProfileDescription fills the display while no detail is chosen
+func (d *DetailPage) viewProfileDescription() string {
+	if d.currentProfile.Description == "" {
+		return ""
+	}
+	return d.valueDisplayStyle.Copy().Width(defaultDisplayWidth).Render(fmt.Sprintf("%s: %s", d.currentProfile.Name, d.currentProfile.Description))
+}
+
+func (d *DetailPage) viewInfo() string {
+	if !d.infoFlag {
+		return ""
+	}
+	infoStyle := d.issuesStyle.Copy().BorderForeground(green)
+	if d.isErrInfo {
+		infoStyle = d.issuesStyle.Copy().BorderForeground(red)
+	}
+	return infoStyle.Render(d.infoMsg)
+}
+
+func (d *DetailPage) viewDetailOptions() string {
+	switch d.detailType {
+	case detailTypeEnv:
+		flag := "[ ]"
+		if d.secretInput {
+			flag = "[x]"
+		}
+		return d.valueDisplayStyle.Render(fmt.Sprintf("Secret: %s <ctrl+t> to toggle", flag))
+	case detailTypePath:
+		return d.valueDisplayStyle.Render(fmt.Sprintf("Position: %s <ctrl+p> | Separator: %q <ctrl+o>", d.positionInput, d.separatorInput))
+	case detailTypeFunction:
+		return d.valueDisplayStyle.Render("<enter> adds a line, <tab> when done")
+	case detailTypeSource:
+		flag := "[ ]"
+		if d.guardInput {
+			flag = "[x]"
+		}
+		return d.valueDisplayStyle.Render(fmt.Sprintf("Only if file exists: %s <ctrl+g> to toggle", flag))
+	case detailTypeRaw:
+		shells := "all"
+		if len(d.shellsInput) > 0 {
+			shells = strings.Join(d.shellsInput, ", ")
+		}
+		return d.valueDisplayStyle.Render(fmt.Sprintf("Shells: %s <ctrl+e> | <enter> adds a line, <tab> when done", shells))
+	default:
+		return ""
+	}
+}
+
+func (d *DetailPage) editValueLabel() string {
+	switch d.detailType {
+	case detailTypeFunction:
+		return "Body: "
+	case detailTypeSource:
+		return "File: "
+	case detailTypeRaw:
+		return "Snippet: "
+	default:
+		return "Value: "
+	}
+}
+
+func (d *DetailPage) viewValueInput() string {
+	if d.usesBody() {
+		return d.bodyInput.View()
+	}
+	return d.valueInput.View()
+}
+
 func (d *DetailPage) viewEditDetail() string {
 	var confirmButtonStr string
 	switch d.currentUserFlow {
@@ -1167,13 +2933,7 @@ func (d *DetailPage) viewEditDetail() string {
 				d.titleStyle.Render(d.generateTitle()),
 				lipgloss.JoinHorizontal(
 					lipgloss.Left,
-					lipgloss.JoinVertical(
-						lipgloss.Center,
-						d.headingStyle.Render(d.generateHeading("Envs")),
-						d.envStyle.Render(d.envList.View()),
-						d.headingStyle.Render(d.generateHeading("Aliases")),
-						d.aliasStyle.Render(d.aliasList.View()),
-					),
+					d.viewSideBar(),
 					lipgloss.JoinVertical(
 						lipgloss.Center,
 						"",
@@ -1188,9 +2948,15 @@ func (d *DetailPage) viewEditDetail() string {
 								),
 								lipgloss.JoinHorizontal(
 									lipgloss.Left,
-									d.valueDisplayStyle.Render("Value: "),
-									d.valueInput.View(),
+									d.valueDisplayStyle.Render(d.editValueLabel()),
+									d.viewValueInput(),
 								),
+								lipgloss.JoinHorizontal(
+									lipgloss.Left,
+									d.descriptionDisplayStyle.Render("Description: "),
+									d.descriptionInput.View(),
+								),
+								d.viewDetailOptions(),
 							),
 						),
 						d.actionsStyle.Render(
@@ -1216,13 +2982,7 @@ func (d *DetailPage) viewEditDetail() string {
 			d.titleStyle.Render(d.generateTitle()),
 			lipgloss.JoinHorizontal(
 				lipgloss.Left,
-				lipgloss.JoinVertical(
-					lipgloss.Center,
-					d.headingStyle.Render(d.generateHeading("Envs")),
-					d.envStyle.Render(d.envList.View()),
-					d.headingStyle.Render(d.generateHeading("Aliases")),
-					d.aliasStyle.Render(d.aliasList.View()),
-				),
+				d.viewSideBar(),
 				lipgloss.JoinVertical(
 					lipgloss.Center,
 					"",
@@ -1236,9 +2996,15 @@ func (d *DetailPage) viewEditDetail() string {
 							),
 							lipgloss.JoinHorizontal(
 								lipgloss.Left,
-								d.valueDisplayStyle.Render("Value: "),
-								d.valueInput.View(),
+								d.valueDisplayStyle.Render(d.editValueLabel()),
+								d.viewValueInput(),
+							),
+							lipgloss.JoinHorizontal(
+								lipgloss.Left,
+								d.descriptionDisplayStyle.Render("Description: "),
+								d.descriptionInput.View(),
 							),
+							d.viewDetailOptions(),
 						),
 					),
 					d.actionsStyle.Render(
@@ -1256,6 +3022,23 @@ func (d *DetailPage) viewEditDetail() string {
 }
 
 func (d *DetailPage) viewDeleteDetail() string {
+	display := lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			d.keyDisplayStyle.Render("Name: "),
+			d.keyTextArea.View(),
+		),
+		lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			d.valueDisplayStyle.Render(d.valueLabel()),
+			d.valueTextArea.View(),
+		),
+		d.viewDescription(),
+	)
+	if len(d.selected) > 0 {
+		display = d.viewSelection(fmt.Sprintf("Delete %s?", countDetails(len(d.selected))))
+	}
 	return lipgloss.Place(
 		d.width,
 		d.height,
@@ -1266,35 +3049,177 @@ func (d *DetailPage) viewDeleteDetail() string {
 			d.titleStyle.Render(d.generateTitle()),
 			lipgloss.JoinHorizontal(
 				lipgloss.Left,
+				d.viewSideBar(),
 				lipgloss.JoinVertical(
 					lipgloss.Center,
-					d.headingStyle.Render(d.generateHeading("Envs")),
-					d.envStyle.Render(d.envList.View()),
-					d.headingStyle.Render(d.generateHeading("Aliases")),
-					d.aliasStyle.Render(d.aliasList.View()),
+					"",
+					d.displayStyle.Render(display),
+					d.actionsStyle.Render(
+						lipgloss.JoinHorizontal(
+							lipgloss.Left,
+							d.deleteButton.Render("Delete"),
+							d.cancelButton.Render("Cancel"),
+						),
+					),
 				),
+			),
+			d.helpMenu.View(d.keys),
+		),
+	)
+}
+
+// viewSelection lists the details a bulk action works on, so they can be checked
+// before confirming
+func (d *DetailPage) viewSelection(heading string) string {
+	lines := []string{heading}
+	details := d.selectedDetails()
+	for i, detail := range details {
+		if i == maxSelectionLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(details)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %-8s %s", detail.DetailType, detailLabel(detail.DetailType, detail.Key, detail.Value)))
+	}
+	return d.valueDisplayStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (d *DetailPage) viewTransferDetail() string {
+	verb := "Copy"
+	if d.currentUserFlow == moveDetail {
+		verb = "Move"
+	}
+	summary := countDetails(len(d.selectedDetails()))
+	var heading, content string
+	switch d.currentStage {
+	case transferDetailProfile:
+		heading = fmt.Sprintf("%s %s to:", verb, summary)
+		content = d.transferList.View()
+	case transferDetailPolicy:
+		heading = fmt.Sprintf("%s %s to %s:", verb, summary, d.transferTarget.Name)
+		content = fmt.Sprintf("%d of them already in %s:\n%s", d.transferConflicts, d.transferTarget.Name, d.transferList.View())
+	default:
+		heading = fmt.Sprintf("%s %s to %s:", verb, summary, d.transferTarget.Name)
+		if d.transferConflicts > 0 {
+			content = fmt.Sprintf("%d already in %s will be handled with: %s", d.transferConflicts, d.transferTarget.Name, d.transferPolicy)
+		}
+	}
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			d.titleStyle.Render(d.generateTitle()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				d.viewSideBar(),
 				lipgloss.JoinVertical(
 					lipgloss.Center,
 					"",
+					d.viewInfo(),
 					d.displayStyle.Render(
 						lipgloss.JoinVertical(
 							lipgloss.Left,
-							lipgloss.JoinHorizontal(
-								lipgloss.Left,
-								d.keyDisplayStyle.Render("Name: "),
-								d.keyTextArea.View(),
-							),
-							lipgloss.JoinHorizontal(
-								lipgloss.Left,
-								d.valueDisplayStyle.Render("Value: "),
-								d.valueTextArea.View(),
-							),
+							d.viewSelection(heading),
+							d.valueDisplayStyle.Render(content),
 						),
 					),
 					d.actionsStyle.Render(
 						lipgloss.JoinHorizontal(
 							lipgloss.Left,
-							d.deleteButton.Render("Delete"),
+							d.confirmButton.Render(verb),
+							d.cancelButton.Render("Cancel"),
+						),
+					),
+				),
+			),
+			d.helpMenu.View(d.keys),
+		),
+	)
+}
+
+func (d *DetailPage) viewHistoryDetail() string {
+	heading := fmt.Sprintf("History of %s %s:", d.currentDetail.DetailType, d.currentDetail.Key)
+	content := d.historyList.View()
+	if d.historyEntry != nil {
+		heading = fmt.Sprintf("Restore %s to its value from %s:", d.currentDetail.Key, d.historyEntry.CreatedAt.Format(historyTimeLayout))
+		content = d.historyEntry.NewValue
+		if d.historyEntry.NewSecret {
+			content = data.SecretMask
+		}
+		if d.currentStage == historyDetailChoose {
+			content = d.historyList.View()
+		}
+	}
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			d.titleStyle.Render(d.generateTitle()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				d.viewSideBar(),
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					"",
+					d.viewInfo(),
+					d.displayStyle.Render(
+						lipgloss.JoinVertical(
+							lipgloss.Left,
+							d.keyDisplayStyle.Render(heading),
+							d.valueDisplayStyle.Render(content),
+						),
+					),
+					d.actionsStyle.Render(
+						lipgloss.JoinHorizontal(
+							lipgloss.Left,
+							d.confirmButton.Render("Restore"),
+							d.cancelButton.Render("Cancel"),
+						),
+					),
+				),
+			),
+			d.helpMenu.View(d.keys),
+		),
+	)
+}
+
+func (d *DetailPage) viewExportDetail() string {
+	heading := fmt.Sprintf("Export to %s:", d.exportPath())
+	var note string
+	if slices.ContainsFunc(d.selectedDetails(), func(detail data.Detail) bool { return detail.Secret }) {
+		note = "Secret values are left out. Use `maggi export --include-secrets` to include them"
+	}
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			d.titleStyle.Render(d.generateTitle()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				d.viewSideBar(),
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					"",
+					d.viewInfo(),
+					d.displayStyle.Render(
+						lipgloss.JoinVertical(
+							lipgloss.Left,
+							d.viewSelection(heading),
+							d.valueDisplayStyle.Render(note),
+						),
+					),
+					d.actionsStyle.Render(
+						lipgloss.JoinHorizontal(
+							lipgloss.Left,
+							d.confirmButton.Render("Export"),
 							d.cancelButton.Render("Cancel"),
 						),
 					),
@@ -1313,21 +3238,90 @@ func (d *DetailPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case DetailStartMsg:
 		d.currentProfile = msg.currentProfile
+		d.focusID = msg.focusID
 		d.currentUserFlow = retrieveDetails
 		return d, func() tea.Msg {
 			return d.getDetails()
 		}
 	case tea.KeyMsg:
+		if d.currentUserFlow == previewDetails {
+			return d, d.handlePreviewKey(msg)
+		}
+		if d.currentUserFlow == editorDetails && (msg.Type == tea.KeyUp || msg.Type == tea.KeyDown) {
+			var cmd tea.Cmd
+			d.editorView, cmd = d.editorView.Update(msg)
+			return d, cmd
+		}
 		switch msg.Type {
 		case tea.KeyTab:
 			return d, d.handleTab(false)
 		case tea.KeyShiftTab:
 			return d, d.handleTab(true)
 		case tea.KeyEnter:
+			if d.editingBody() {
+				return d, d.handleEvent(msg)
+			}
 			return d, d.handleEnter()
 		case tea.KeyEsc:
 			return d, d.handleEsc()
+		case tea.KeySpace:
+			return d, d.handleToggleSelect(msg)
+		case tea.KeyRunes:
+			if msg.String() == "a" && d.selecting() {
+				return d, d.handleSelectAll()
+			}
+			if msg.String() == "u" && d.selecting() {
+				return d, replayJournal(d.repository, false)
+			}
+			if msg.String() == "s" && d.selecting() {
+				return d, d.handleSort()
+			}
+			if msg.String() == "p" && d.selecting() {
+				return d, d.handlePreview()
+			}
+			if (msg.String() == "e" || msg.String() == "E") && d.selecting() {
+				return d, d.handleOpenEditor(msg.String() == "E")
+			}
+		case tea.KeyCtrlR:
+			if d.selecting() {
+				return d, replayJournal(d.repository, true)
+			}
+		case tea.KeyCtrlS:
+			return d, d.handleReveal()
+		case tea.KeyCtrlT:
+			d.handleToggleSecret()
+			return d, nil
+		case tea.KeyCtrlP:
+			d.handleTogglePosition()
+			return d, nil
+		case tea.KeyCtrlO:
+			d.handleCycleSeparator()
+			return d, nil
+		case tea.KeyCtrlG:
+			d.handleToggleGuard()
+			return d, nil
+		case tea.KeyCtrlE:
+			d.handleCycleShells()
+			return d, nil
+		}
+	case secretRevealedMsg:
+		d.handleSecretRevealed(msg)
+		return d, nil
+	case scriptPreviewedMsg:
+		d.handlePreviewGenerated(msg)
+		return d, nil
+	case editorClosedMsg:
+		return d, d.handleEditorClosed(msg)
+	case editorAppliedMsg:
+		if cmd := d.handleDetailEdited(); cmd != nil {
+			return d, cmd
 		}
+		d.infoFlag = true
+		d.infoMsg = fmt.Sprintf("Applied %s from the editor", countChanges(msg.count))
+		return d, nil
+	case detailEvaluatedMsg:
+		d.handleDetailEvaluated(msg)
+		return d, nil
 	case retrieveDetailsMsg:
 		if msg.err != nil {
 			return d, func() tea.Msg {
@@ -1336,42 +3330,110 @@ func (d *DetailPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		d.currentUserFlow = listDetails
 		d.details = msg.details
+		d.setSort(msg.sort)
+		d.selected = nil
 		d.activePane = envPane
 		d.detailType = detailTypeEnv
 		d.emptyDisplay = true
 		d.setDetailLists()
+		if d.focusID != 0 {
+			d.focusDetail(d.focusID)
+			d.focusID = 0
+		}
 		d.setActionsList()
 	case detailEditedMsg:
-		err := d.resetDetails()
-		if err != nil {
-			return d, func() tea.Msg {
-				return IssueMsg{Inner: err}
-			}
+		return d, d.handleDetailEdited()
+	case detailsTransferredMsg:
+		if cmd := d.handleDetailEdited(); cmd != nil {
+			return d, cmd
 		}
-		switch d.detailType {
-		case detailTypeAlias:
-			d.activePane = aliasPane
-		case detailTypeEnv:
-			d.activePane = envPane
-		default:
-			d.activePane = envPane
-			d.detailType = detailTypeEnv
+		d.reportTransfer(msg)
+		return d, nil
+	case journalMsg:
+		return d, d.handleJournal(msg)
+	case detailsExportedMsg:
+		if cmd := d.handleDetailEdited(); cmd != nil {
+			return d, cmd
 		}
-		d.currentUserFlow = listDetails
-		d.currentStage = chooseDetailAction
-		d.emptyDisplay = true
-
-		d.keyInput.SetValue("")
-		d.valueInput.SetValue("")
-
-		d.setDetailLists()
-		d.updatePaneStyles()
+		d.infoFlag = true
+		d.infoMsg = fmt.Sprintf("Exported %s to %s", countDetails(msg.count), msg.path)
+		return d, nil
+	case valueRestoredMsg:
+		if cmd := d.handleDetailEdited(); cmd != nil {
+			return d, cmd
+		}
+		d.infoFlag = true
+		d.infoMsg = fmt.Sprintf("Restored %s to its value from %s", msg.key, msg.at.Format(historyTimeLayout))
 		return d, nil
 	}
 	cmd := d.handleEvent(msg)
 	return d, cmd
 }
 
+// handleDetailEdited reloads the details after a change and goes back to the lists.
+// the selection is cleared, as a bulk action is done with it.
+func (d *DetailPage) handleDetailEdited() tea.Cmd {
+	d.selected = nil
+	err := d.resetDetails()
+	if err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	if d.detailType == detailTypeDefault {
+		d.detailType = detailTypeEnv
+	}
+	d.activePane = detailTypePane(d.detailType)
+	d.currentUserFlow = listDetails
+	d.currentStage = chooseDetailAction
+	d.emptyDisplay = true
+	d.secretInput = false
+	d.positionInput = ""
+	d.separatorInput = ""
+	d.guardInput = false
+	d.shellsInput = nil
+	d.revealed = false
+	d.revealedValue = ""
+
+	d.keyInput.SetValue("")
+	d.valueInput.SetValue("")
+	d.bodyInput.SetValue("")
+
+	d.setDetailLists()
+	d.updatePaneStyles()
+	return nil
+}
+
+// handleJournal reloads the details after an undo or redo. the current profile may
+// have been renamed or removed by it, in which case the profile page is shown.
+func (d *DetailPage) handleJournal(msg journalMsg) tea.Cmd {
+	if msg.err != nil && !msg.nothingToReplay() {
+		return func() tea.Msg {
+			return IssueMsg{Inner: msg.err}
+		}
+	}
+	profiles, err := d.repository.GetAllProfiles()
+	if err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	i := slices.IndexFunc(profiles, func(profile data.Profile) bool { return profile.ID == d.currentProfile.ID })
+	if i < 0 {
+		return func() tea.Msg {
+			return GenericTurner(profile)
+		}
+	}
+	d.currentProfile = profiles[i]
+	if cmd := d.handleDetailEdited(); cmd != nil {
+		return cmd
+	}
+	d.infoFlag = true
+	d.isErrInfo = msg.err != nil
+	d.infoMsg = msg.info()
+	return nil
+}
+
 func (d *DetailPage) View() string {
 	switch d.currentUserFlow {
 	case listDetails:
@@ -1382,6 +3444,16 @@ func (d *DetailPage) View() string {
 		return d.viewEditDetail()
 	case deleteDetail:
 		return d.viewDeleteDetail()
+	case copyDetail, moveDetail:
+		return d.viewTransferDetail()
+	case exportDetail:
+		return d.viewExportDetail()
+	case historyDetail:
+		return d.viewHistoryDetail()
+	case previewDetails:
+		return d.viewPreviewDetails()
+	case editorDetails:
+		return d.viewEditorDetails()
 	}
 	return "detail page.."
 }
@@ -2,27 +2,56 @@ package tui
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/bento01dev/maggi/internal/data"
 	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/stretchr/testify/assert"
 )
 
 type detailModelStub struct {
 	getAll func(profileID int) ([]data.Detail, error)
-	add    func(key string, value string, detailType data.DetailType, profileID int) (*data.Detail, error)
+	add    func(detail data.Detail) (*data.Detail, error)
 	update func(detail data.Detail, key string, value string) (*data.Detail, error)
 	delete func(detail data.Detail) error
+	// deleteAll backs the bulk delete
+	deleteAll func(details []data.Detail) error
+	reveal    func(detail data.Detail) (string, error)
+	// profiles and transfer back the copy and move actions
+	profiles []data.Profile
+	transfer func(details []data.Detail, target data.Profile, policy data.ConflictPolicy, move bool) (data.TransferResult, error)
+	replay   func(redo bool) (data.Operation, error)
+	// history and restore back the history pane
+	history []data.HistoryEntry
+	restore func(detail data.Detail, entry data.HistoryEntry) (*data.Detail, error)
+	// edit backs the changes made in the editor
+	edit     func(edit data.DetailEdit) error
+	settings map[string]string
+}
+
+func (ds detailModelStub) GetSetting(key string) (string, error) {
+	return ds.settings[key], nil
+}
+
+func (ds detailModelStub) SetSetting(key, value string) error {
+	if ds.settings != nil {
+		ds.settings[key] = value
+	}
+	return nil
 }
 
 func (ds detailModelStub) GetAllDetails(profileID int) ([]data.Detail, error) {
 	return ds.getAll(profileID)
 }
 
-func (ds detailModelStub) AddDetail(key string, value string, detailType data.DetailType, profileID int) (*data.Detail, error) {
-	return ds.add(key, value, detailType, profileID)
+func (ds detailModelStub) AddDetail(detail data.Detail) (*data.Detail, error) {
+	return ds.add(detail)
 }
 
 func (ds detailModelStub) UpdateDetail(detail data.Detail, key, value string) (*data.Detail, error) {
@@ -33,6 +62,53 @@ func (ds detailModelStub) DeleteDetail(detail data.Detail) error {
 	return ds.delete(detail)
 }
 
+func (ds detailModelStub) DeleteDetails(details []data.Detail) error {
+	return ds.deleteAll(details)
+}
+
+func (ds detailModelStub) GetDetailsByProfileName(name string) ([]data.Detail, error) {
+	return ds.getAll(0)
+}
+
+func (ds detailModelStub) RevealValue(detail data.Detail) (string, error) {
+	if ds.reveal == nil {
+		return detail.Value, nil
+	}
+	return ds.reveal(detail)
+}
+
+func (ds detailModelStub) GetAllProfiles() ([]data.Profile, error) {
+	return ds.profiles, nil
+}
+
+func (ds detailModelStub) Undo() (data.Operation, error) {
+	return ds.replay(false)
+}
+
+func (ds detailModelStub) Redo() (data.Operation, error) {
+	return ds.replay(true)
+}
+
+func (ds detailModelStub) CopyDetails(details []data.Detail, target data.Profile, policy data.ConflictPolicy) (data.TransferResult, error) {
+	return ds.transfer(details, target, policy, false)
+}
+
+func (ds detailModelStub) MoveDetails(details []data.Detail, target data.Profile, policy data.ConflictPolicy) (data.TransferResult, error) {
+	return ds.transfer(details, target, policy, true)
+}
+
+func (ds detailModelStub) GetHistory(filter data.HistoryFilter) ([]data.HistoryEntry, error) {
+	return ds.history, nil
+}
+
+func (ds detailModelStub) RestoreValue(detail data.Detail, entry data.HistoryEntry) (*data.Detail, error) {
+	return ds.restore(detail, entry)
+}
+
+func (ds detailModelStub) EditDetails(edit data.DetailEdit) error {
+	return ds.edit(edit)
+}
+
 func TestCreateTextArea(t *testing.T) {
 	t.Run("text area is muted when enabled is false", func(t *testing.T) {
 		res := createTextArea(false)
@@ -108,7 +184,7 @@ func TestAddDetail(t *testing.T) {
 			ProfileID:  1,
 			DetailType: data.AliasDetail,
 		}
-		detailPage := NewDetailPage(detailModelStub{add: func(key, value string, detailType data.DetailType, profileID int) (*data.Detail, error) {
+		detailPage := NewDetailPage(detailModelStub{add: func(_ data.Detail) (*data.Detail, error) {
 			return &detail, nil
 		}})
 		res, err := detailPage.addDetail("key", "value")
@@ -124,7 +200,7 @@ func TestAddDetail(t *testing.T) {
 			ProfileID:  1,
 			DetailType: data.EnvDetail,
 		}
-		detailPage := NewDetailPage(detailModelStub{add: func(key, value string, detailType data.DetailType, profileID int) (*data.Detail, error) {
+		detailPage := NewDetailPage(detailModelStub{add: func(_ data.Detail) (*data.Detail, error) {
 			return &detail, nil
 		}})
 		res, err := detailPage.addDetail("key", "value")
@@ -133,7 +209,7 @@ func TestAddDetail(t *testing.T) {
 	})
 
 	t.Run("Return error if error in add", func(t *testing.T) {
-		detailPage := NewDetailPage(detailModelStub{add: func(key, value string, detailType data.DetailType, profileID int) (*data.Detail, error) {
+		detailPage := NewDetailPage(detailModelStub{add: func(_ data.Detail) (*data.Detail, error) {
 			return nil, errors.New("error in add")
 		}})
 		res, err := detailPage.addDetail("key", "value")
@@ -505,11 +581,35 @@ func TestHandleListDetailsTab(t *testing.T) {
 		detailType        detailType
 	}{
 		{
-			name:              "shift tab on env pane should move to alias pane and alias type",
+			name:              "shift tab on env pane should move to snippet pane and source type",
 			shift:             true,
 			currentActivePane: envPane,
-			activePane:        aliasPane,
+			activePane:        snippetPane,
 			currentDetailType: detailTypeEnv,
+			detailType:        detailTypeSource,
+		},
+		{
+			name:              "shift tab on snippet pane should move to function pane and function type",
+			shift:             true,
+			currentActivePane: snippetPane,
+			activePane:        functionPane,
+			currentDetailType: detailTypeRaw,
+			detailType:        detailTypeFunction,
+		},
+		{
+			name:              "shift tab on function pane should move to path pane and path type",
+			shift:             true,
+			currentActivePane: functionPane,
+			activePane:        pathPane,
+			currentDetailType: detailTypeFunction,
+			detailType:        detailTypePath,
+		},
+		{
+			name:              "shift tab on path pane should move to alias pane and alias type",
+			shift:             true,
+			currentActivePane: pathPane,
+			activePane:        aliasPane,
+			currentDetailType: detailTypePath,
 			detailType:        detailTypeAlias,
 		},
 		{
@@ -561,10 +661,31 @@ func TestHandleListDetailsTab(t *testing.T) {
 			detailType:        detailTypeEnv,
 		},
 		{
-			name:              "tab on alias pane should switch to env pane and detail type",
+			name:              "tab on alias pane should switch to path pane and detail type",
 			currentActivePane: aliasPane,
-			activePane:        envPane,
+			activePane:        pathPane,
 			currentDetailType: detailTypeAlias,
+			detailType:        detailTypePath,
+		},
+		{
+			name:              "tab on path pane should switch to function pane and detail type",
+			currentActivePane: pathPane,
+			activePane:        functionPane,
+			currentDetailType: detailTypePath,
+			detailType:        detailTypeFunction,
+		},
+		{
+			name:              "tab on function pane should switch to snippet pane and source type",
+			currentActivePane: functionPane,
+			activePane:        snippetPane,
+			currentDetailType: detailTypeFunction,
+			detailType:        detailTypeSource,
+		},
+		{
+			name:              "tab on snippet pane should switch to env pane and detail type",
+			currentActivePane: snippetPane,
+			activePane:        envPane,
+			currentDetailType: detailTypeRaw,
 			detailType:        detailTypeEnv,
 		},
 		{
@@ -608,9 +729,17 @@ func TestHandleEditDetailTab(t *testing.T) {
 		newStage      detailStage
 	}{
 		{
-			name:          "shift tab on env pane should change to alias pane",
+			name:          "shift tab on env pane should change to snippet pane",
 			shift:         true,
 			oldActivePane: envPane,
+			newActivePane: snippetPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "shift tab on path pane should change to alias pane",
+			shift:         true,
+			oldActivePane: pathPane,
 			newActivePane: aliasPane,
 			oldStage:      chooseDetailAction,
 			newStage:      chooseDetailAction,
@@ -624,11 +753,19 @@ func TestHandleEditDetailTab(t *testing.T) {
 			newStage:      editDetailCancel,
 		},
 		{
-			name:          "shift tab on action pane should change to display pane and value edit if confirm stage",
+			name:          "shift tab on action pane should change to display pane and description edit if confirm stage",
 			shift:         true,
 			oldActivePane: detailActionPane,
 			newActivePane: detailDisplayPane,
 			oldStage:      editDetailConfirm,
+			newStage:      editDetailDescription,
+		},
+		{
+			name:          "shift tab on display pane should switch to detail value if current stage is detail description",
+			shift:         true,
+			oldActivePane: detailDisplayPane,
+			newActivePane: detailDisplayPane,
+			oldStage:      editDetailDescription,
 			newStage:      editDetailValue,
 		},
 		{
@@ -664,9 +801,33 @@ func TestHandleEditDetailTab(t *testing.T) {
 			newStage:      editDetailKey,
 		},
 		{
-			name:          "tab on alias pane should switch to env pane",
+			name:          "tab on alias pane should switch to path pane",
 			shift:         false,
 			oldActivePane: aliasPane,
+			newActivePane: pathPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "tab on path pane should switch to function pane",
+			shift:         false,
+			oldActivePane: pathPane,
+			newActivePane: functionPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "tab on function pane should switch to snippet pane",
+			shift:         false,
+			oldActivePane: functionPane,
+			newActivePane: snippetPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "tab on snippet pane should switch to env pane",
+			shift:         false,
+			oldActivePane: snippetPane,
 			newActivePane: envPane,
 			oldStage:      chooseDetailAction,
 			newStage:      chooseDetailAction,
@@ -680,11 +841,19 @@ func TestHandleEditDetailTab(t *testing.T) {
 			newStage:      editDetailValue,
 		},
 		{
-			name:          "tab on value detail should switch stage to confir mand pane to action pane",
+			name:          "tab on value detail should switch stage to description detail",
 			shift:         false,
 			oldActivePane: detailDisplayPane,
-			newActivePane: detailActionPane,
+			newActivePane: detailDisplayPane,
 			oldStage:      editDetailValue,
+			newStage:      editDetailDescription,
+		},
+		{
+			name:          "tab on description detail should switch stage to confirm and pane to action pane",
+			shift:         false,
+			oldActivePane: detailDisplayPane,
+			newActivePane: detailActionPane,
+			oldStage:      editDetailDescription,
 			newStage:      editDetailConfirm,
 		},
 		{
@@ -729,10 +898,10 @@ func TestHandleDeleteDetailTab(t *testing.T) {
 		newStage      detailStage
 	}{
 		{
-			name:          "shift tab should switch env pane to alias pane",
+			name:          "shift tab should switch env pane to snippet pane",
 			shift:         true,
 			oldActivePane: envPane,
-			newActivePane: aliasPane,
+			newActivePane: snippetPane,
 			oldStage:      chooseDetailAction,
 			newStage:      chooseDetailAction,
 		},
@@ -777,9 +946,33 @@ func TestHandleDeleteDetailTab(t *testing.T) {
 			newStage:      deleteDetailView,
 		},
 		{
-			name:          "tab on alias pane should switch to env pane",
+			name:          "tab on alias pane should switch to path pane",
 			shift:         false,
 			oldActivePane: aliasPane,
+			newActivePane: pathPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "tab on path pane should switch to function pane",
+			shift:         false,
+			oldActivePane: pathPane,
+			newActivePane: functionPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "tab on function pane should switch to snippet pane",
+			shift:         false,
+			oldActivePane: functionPane,
+			newActivePane: snippetPane,
+			oldStage:      chooseDetailAction,
+			newStage:      chooseDetailAction,
+		},
+		{
+			name:          "tab on snippet pane should switch to env pane",
+			shift:         false,
+			oldActivePane: snippetPane,
 			newActivePane: envPane,
 			oldStage:      chooseDetailAction,
 			newStage:      chooseDetailAction,
@@ -1225,9 +1418,9 @@ func TestHandleEDitDetailEnter(t *testing.T) {
 		{
 			name:          "correct key should move stage to next stage for new detail",
 			oldActivePane: detailDisplayPane,
-			newActivePane: detailActionPane,
+			newActivePane: detailDisplayPane,
 			oldStage:      editDetailValue,
-			newStage:      editDetailConfirm,
+			newStage:      editDetailDescription,
 			oldUserFlow:   newDetail,
 			details:       []data.Detail{{ID: 1, Key: "test", Value: "test", ProfileID: 1}},
 			key:           "test1",
@@ -1262,9 +1455,9 @@ func TestHandleEDitDetailEnter(t *testing.T) {
 		{
 			name:          "key in duplicate key check in edit detail value should go through if editing the same detail in update detail",
 			oldActivePane: detailDisplayPane,
-			newActivePane: detailActionPane,
+			newActivePane: detailDisplayPane,
 			oldStage:      editDetailValue,
-			newStage:      editDetailConfirm,
+			newStage:      editDetailDescription,
 			oldUserFlow:   updateDetail,
 			details:       []data.Detail{{ID: 1, Key: "test", Value: "test", ProfileID: 1}},
 			key:           "test",
@@ -1274,14 +1467,24 @@ func TestHandleEDitDetailEnter(t *testing.T) {
 		{
 			name:          "correct key should move stage to next stage for update detail",
 			oldActivePane: detailDisplayPane,
-			newActivePane: detailActionPane,
+			newActivePane: detailDisplayPane,
 			oldStage:      editDetailValue,
-			newStage:      editDetailConfirm,
+			newStage:      editDetailDescription,
 			oldUserFlow:   updateDetail,
 			details:       []data.Detail{{ID: 1, Key: "test", Value: "test", ProfileID: 1}},
 			key:           "test1",
 			value:         "test",
 		},
+		{
+			name:          "enter on description should move stage to confirm",
+			oldActivePane: detailDisplayPane,
+			newActivePane: detailActionPane,
+			oldStage:      editDetailDescription,
+			newStage:      editDetailConfirm,
+			oldUserFlow:   newDetail,
+			key:           "test1",
+			value:         "test",
+		},
 		{
 			name:          "empty value should set info bag in edit detail value",
 			oldActivePane: detailDisplayPane,
@@ -1364,3 +1567,612 @@ func TestHandleEDitDetailEnter(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretDisplay(t *testing.T) {
+	t.Run("secret value is masked until revealed", func(t *testing.T) {
+		detailPage := NewDetailPage(detailModelStub{})
+		detailPage.currentUserFlow = viewDetail
+		detailPage.setCurrentDetail(detailItem{id: 1, key: "token", value: "sealed", secret: true}, data.EnvDetail)
+		detailPage.setTextAreaValues()
+		assert.Equal(t, data.SecretMask, detailPage.valueTextArea.Value())
+
+		detailPage.handleSecretRevealed(secretRevealedMsg{id: 1, value: "plain"})
+		assert.True(t, detailPage.revealed)
+		assert.Equal(t, "plain", detailPage.valueTextArea.Value())
+	})
+
+	t.Run("reveal error is shown in info bag", func(t *testing.T) {
+		detailPage := NewDetailPage(detailModelStub{})
+		detailPage.currentUserFlow = viewDetail
+		detailPage.setCurrentDetail(detailItem{id: 1, key: "token", value: "sealed", secret: true}, data.EnvDetail)
+		detailPage.handleSecretRevealed(secretRevealedMsg{id: 1, err: errors.New("no key")})
+		assert.False(t, detailPage.revealed)
+		assert.True(t, detailPage.infoFlag)
+		assert.True(t, detailPage.isErrInfo)
+	})
+
+	t.Run("moving to another detail masks again", func(t *testing.T) {
+		detailPage := NewDetailPage(detailModelStub{})
+		detailPage.currentUserFlow = viewDetail
+		detailPage.setCurrentDetail(detailItem{id: 1, key: "token", value: "sealed", secret: true}, data.EnvDetail)
+		detailPage.handleSecretRevealed(secretRevealedMsg{id: 1, value: "plain"})
+		detailPage.setCurrentDetail(detailItem{id: 2, key: "other", value: "sealed", secret: true}, data.EnvDetail)
+		detailPage.setTextAreaValues()
+		assert.False(t, detailPage.revealed)
+		assert.Equal(t, data.SecretMask, detailPage.valueTextArea.Value())
+	})
+}
+
+func TestHandleToggleSecret(t *testing.T) {
+	testcases := []struct {
+		name       string
+		userFlow   detailsUserFlow
+		detailType detailType
+		res        bool
+	}{
+		{
+			name:       "toggle works for new env",
+			userFlow:   newDetail,
+			detailType: detailTypeEnv,
+			res:        true,
+		},
+		{
+			name:       "toggle works for env update",
+			userFlow:   updateDetail,
+			detailType: detailTypeEnv,
+			res:        true,
+		},
+		{
+			name:       "toggle is ignored for aliases",
+			userFlow:   newDetail,
+			detailType: detailTypeAlias,
+		},
+		{
+			name:       "toggle is ignored outside of edit flows",
+			userFlow:   viewDetail,
+			detailType: detailTypeEnv,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			detailPage := NewDetailPage(detailModelStub{})
+			detailPage.currentUserFlow = testcase.userFlow
+			detailPage.detailType = testcase.detailType
+			detailPage.handleToggleSecret()
+			assert.Equal(t, testcase.res, detailPage.secretInput)
+		})
+	}
+}
+
+func TestAddDetailParsesValueKind(t *testing.T) {
+	var added data.Detail
+	detailPage := NewDetailPage(detailModelStub{add: func(detail data.Detail) (*data.Detail, error) {
+		added = detail
+		return &detail, nil
+	}})
+	detailPage.detailType = detailTypeEnv
+	_, err := detailPage.addDetail("token", "cmd:pass show token")
+	assert.Nil(t, err)
+	assert.Equal(t, data.CommandValue, added.Kind)
+	assert.Equal(t, "pass show token", added.Value)
+}
+
+func TestHandleDetailEvaluated(t *testing.T) {
+	testcases := []struct {
+		name      string
+		msg       detailEvaluatedMsg
+		isErrInfo bool
+		infoMsg   string
+	}{
+		{
+			name:    "evaluated value is shown in info bag",
+			msg:     detailEvaluatedMsg{id: 1, value: "abc"},
+			infoMsg: "token evaluates to: abc",
+		},
+		{
+			name:      "evaluation error is shown as error info",
+			msg:       detailEvaluatedMsg{id: 1, err: errors.New("exit status 1")},
+			isErrInfo: true,
+			infoMsg:   "Unable to evaluate token: exit status 1",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			detailPage := NewDetailPage(detailModelStub{})
+			detailPage.setCurrentDetail(detailItem{id: 1, key: "token", value: "echo abc", kind: data.CommandValue}, data.EnvDetail)
+			detailPage.handleDetailEvaluated(testcase.msg)
+			assert.True(t, detailPage.infoFlag)
+			assert.Equal(t, testcase.isErrInfo, detailPage.isErrInfo)
+			assert.Equal(t, testcase.infoMsg, detailPage.infoMsg)
+		})
+	}
+}
+
+func TestEvaluateActions(t *testing.T) {
+	testcases := []struct {
+		name       string
+		detailType detailType
+		dataType   data.DetailType
+		res        bool
+	}{
+		{name: "envs can be evaluated", detailType: detailTypeEnv, dataType: data.EnvDetail, res: true},
+		{name: "aliases can be evaluated", detailType: detailTypeAlias, dataType: data.AliasDetail, res: true},
+		{name: "path entries are always literal", detailType: detailTypePath, dataType: data.PathDetail, res: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			detailPage := NewDetailPage(detailModelStub{})
+			detailPage.detailType = testcase.detailType
+			detailPage.setCurrentDetail(detailItem{id: 1, key: "k", value: "echo kubectl", kind: data.CommandValue}, testcase.dataType)
+			detailPage.setActionsList()
+			var actions []string
+			for _, item := range detailPage.actionsList.Items() {
+				actions = append(actions, renderDetailActionItem(item))
+			}
+			assert.Equal(t, testcase.res, slices.Contains(actions, "Test Evaluate"))
+		})
+	}
+}
+
+func TestAddPathDetail(t *testing.T) {
+	var added data.Detail
+	detailPage := NewDetailPage(detailModelStub{add: func(detail data.Detail) (*data.Detail, error) {
+		added = detail
+		return &detail, nil
+	}})
+	detailPage.detailType = detailTypePath
+	detailPage.positionInput = data.AppendPath
+	detailPage.separatorInput = ";"
+	_, err := detailPage.addDetail("PATH", "cmd:~/bin")
+	assert.Nil(t, err)
+	assert.Equal(t, data.PathDetail, added.DetailType)
+	assert.Equal(t, data.LiteralValue, added.Kind)
+	assert.Equal(t, "cmd:~/bin", added.Value)
+	assert.Equal(t, data.AppendPath, added.Position)
+	assert.Equal(t, ";", added.Separator)
+}
+
+func TestHandlePathOptions(t *testing.T) {
+	detailPage := NewDetailPage(detailModelStub{})
+	detailPage.currentUserFlow = newDetail
+	detailPage.detailType = detailTypePath
+	detailPage.positionInput = data.PrependPath
+	detailPage.separatorInput = data.DefaultPathSeparator
+
+	detailPage.handleTogglePosition()
+	assert.Equal(t, data.AppendPath, detailPage.positionInput)
+	detailPage.handleTogglePosition()
+	assert.Equal(t, data.PrependPath, detailPage.positionInput)
+
+	var seen []string
+	for range pathSeparators {
+		detailPage.handleCycleSeparator()
+		seen = append(seen, detailPage.separatorInput)
+	}
+	assert.Equal(t, []string{";", ",", " ", ":"}, seen)
+
+	detailPage.detailType = detailTypeEnv
+	detailPage.handleTogglePosition()
+	assert.Equal(t, data.PrependPath, detailPage.positionInput)
+}
+
+func TestFunctionBodyEdit(t *testing.T) {
+	var added data.Detail
+	detailPage := NewDetailPage(detailModelStub{add: func(detail data.Detail) (*data.Detail, error) {
+		added = detail
+		return &detail, nil
+	}})
+	detailPage.checkFunction = func(name, body string) error { return nil }
+	detailPage.currentUserFlow = newDetail
+	detailPage.detailType = detailTypeFunction
+	detailPage.activePane = detailDisplayPane
+	detailPage.currentStage = editDetailValue
+	detailPage.keyInput.SetValue("greet")
+	detailPage.bodyInput.Focus()
+	detailPage.bodyInput.SetValue("echo hi")
+
+	// enter adds a line to the body instead of moving on to confirm
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	detailPage.bodyInput.InsertString("  echo \"$1\"")
+	assert.Equal(t, editDetailValue, detailPage.currentStage)
+	assert.Equal(t, "echo hi\n  echo \"$1\"", detailPage.editedValue())
+
+	detailPage.activePane = detailActionPane
+	detailPage.currentStage = editDetailConfirm
+	cmd := detailPage.handleEditDetailEnter()
+	assert.IsType(t, detailEditedMsg{}, cmd())
+	assert.Equal(t, data.FunctionDetail, added.DetailType)
+	assert.Equal(t, data.LiteralValue, added.Kind)
+	assert.Equal(t, "echo hi\n  echo \"$1\"", added.Value)
+}
+
+func TestEditDetailDescription(t *testing.T) {
+	var updated data.Detail
+	detailPage := NewDetailPage(detailModelStub{update: func(detail data.Detail, key string, value string) (*data.Detail, error) {
+		updated = detail
+		return &detail, nil
+	}})
+	detailPage.currentUserFlow = updateDetail
+	detailPage.detailType = detailTypeEnv
+	detailPage.currentDetail = &data.Detail{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1}
+	detailPage.details = []data.Detail{*detailPage.currentDetail}
+	detailPage.activePane = detailDisplayPane
+	detailPage.currentStage = editDetailValue
+	detailPage.keyInput.SetValue("EDITOR")
+	detailPage.valueInput.SetValue("nvim")
+
+	detailPage.handleEditDetailEnter()
+	assert.Equal(t, editDetailDescription, detailPage.currentStage)
+	detailPage.descriptionInput.SetValue(" git commits open here ")
+	detailPage.handleEditDetailEnter()
+	assert.Equal(t, editDetailConfirm, detailPage.currentStage)
+	assert.Equal(t, detailActionPane, detailPage.activePane)
+
+	cmd := detailPage.handleEditDetailEnter()
+	assert.IsType(t, detailEditedMsg{}, cmd())
+	assert.Equal(t, "git commits open here", updated.Description)
+}
+
+func TestFunctionSyntaxCheck(t *testing.T) {
+	detailPage := NewDetailPage(detailModelStub{})
+	detailPage.checkFunction = func(name, body string) error { return errors.New("syntax error: unexpected end of file") }
+	detailPage.currentUserFlow = newDetail
+	detailPage.detailType = detailTypeFunction
+	detailPage.activePane = detailActionPane
+	detailPage.currentStage = editDetailConfirm
+	detailPage.keyInput.SetValue("greet")
+	detailPage.bodyInput.SetValue("if true; then")
+
+	detailPage.handleEditDetailEnter()
+	assert.True(t, detailPage.isErrInfo)
+	assert.Equal(t, "Function greet does not parse: syntax error: unexpected end of file", detailPage.infoMsg)
+	assert.Equal(t, editDetailValue, detailPage.currentStage)
+	assert.Equal(t, detailDisplayPane, detailPage.activePane)
+}
+
+func TestSnippetPaneAdd(t *testing.T) {
+	testcases := []struct {
+		name       string
+		index      int
+		detailType detailType
+		guard      bool
+		shells     []string
+	}{
+		{
+			name:       "add source file defaults to an existence check",
+			index:      0,
+			detailType: detailTypeSource,
+			guard:      true,
+		},
+		{
+			name:       "add raw snippet is tagged with the chosen shells",
+			index:      1,
+			detailType: detailTypeRaw,
+			shells:     []string{"bash", "zsh"},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			var added data.Detail
+			detailPage := NewDetailPage(detailModelStub{add: func(detail data.Detail) (*data.Detail, error) {
+				added = detail
+				return &detail, nil
+			}})
+			detailPage.setDetailLists()
+			detailPage.activePane = snippetPane
+			detailPage.snippetList.Select(testcase.index)
+
+			detailPage.handleListDetailsEnter()
+			assert.Equal(t, newDetail, detailPage.currentUserFlow)
+			assert.Equal(t, testcase.detailType, detailPage.detailType)
+
+			detailPage.handleCycleShells()
+			detailPage.handleCycleShells()
+			_, err := detailPage.addDetail("snippet", "value")
+			assert.Nil(t, err)
+			assert.Equal(t, testcase.guard, added.Guard)
+			assert.Equal(t, testcase.shells, added.Shells)
+		})
+	}
+}
+
+func TestEditDetailValidation(t *testing.T) {
+	detailPage := NewDetailPage(detailModelStub{})
+	detailPage.currentUserFlow = newDetail
+	detailPage.detailType = detailTypeEnv
+	detailPage.activePane = detailDisplayPane
+	detailPage.currentStage = editDetailKey
+
+	detailPage.keyInput.SetValue("MY-VAR")
+	detailPage.handleEditDetailEnter()
+	assert.Equal(t, editDetailKey, detailPage.currentStage)
+	assert.True(t, detailPage.isErrInfo)
+	assert.Contains(t, detailPage.infoMsg, "Error: Name:")
+
+	// a warning is shown but does not stop the flow
+	detailPage.keyInput.SetValue("PATH")
+	detailPage.handleEditDetailEnter()
+	assert.Equal(t, editDetailValue, detailPage.currentStage)
+	assert.True(t, detailPage.infoFlag)
+	assert.False(t, detailPage.isErrInfo)
+	assert.Contains(t, detailPage.infoMsg, "Warning: Name:")
+
+	detailPage.detailType = detailTypePath
+	detailPage.separatorInput = data.DefaultPathSeparator
+	detailPage.valueInput.SetValue("/usr/bin:/bin")
+	detailPage.handleEditDetailEnter()
+	assert.Equal(t, editDetailValue, detailPage.currentStage)
+	assert.True(t, detailPage.isErrInfo)
+	assert.Contains(t, detailPage.infoMsg, "Error: Value:")
+
+	detailPage.valueInput.SetValue("/usr/local/bin")
+	detailPage.handleEditDetailEnter()
+	assert.Equal(t, editDetailDescription, detailPage.currentStage)
+	assert.False(t, detailPage.infoFlag)
+}
+
+func TestTransferDetails(t *testing.T) {
+	details := []data.Detail{
+		{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 2, Key: "PAGER", Value: "less", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 3, Key: "LANG", Value: "C", DetailType: data.EnvDetail, ProfileID: 1},
+	}
+	targetDetails := []data.Detail{{ID: 4, Key: "PAGER", Value: "more", DetailType: data.EnvDetail, ProfileID: 2}}
+	var moved []data.Detail
+	var movedTo data.Profile
+	var movedWith data.ConflictPolicy
+	detailPage := NewDetailPage(detailModelStub{
+		getAll: func(profileID int) ([]data.Detail, error) {
+			if profileID == 2 {
+				return targetDetails, nil
+			}
+			return details, nil
+		},
+		profiles: []data.Profile{{ID: 1, Name: "work"}, {ID: 2, Name: "home"}},
+		transfer: func(transferred []data.Detail, target data.Profile, policy data.ConflictPolicy, move bool) (data.TransferResult, error) {
+			assert.True(t, move)
+			moved, movedTo, movedWith = transferred, target, policy
+			return data.TransferResult{Transferred: transferred, Overwritten: 1}, nil
+		},
+	})
+	detailPage.currentProfile = data.Profile{ID: 1, Name: "work"}
+	detailPage.Update(retrieveDetailsMsg{details: details})
+
+	// select EDITOR and PAGER, skipping the add item at the top. the list is sorted
+	// by name, so LANG comes in between
+	space := tea.KeyMsg{Type: tea.KeySpace}
+	detailPage.envList.Select(1)
+	detailPage.Update(space)
+	detailPage.envList.Select(3)
+	detailPage.Update(space)
+	detailPage.envList.Select(2)
+	detailPage.Update(space)
+	detailPage.Update(space)
+	assert.Equal(t, map[int]bool{1: true, 2: true}, detailPage.selected)
+	assert.Equal(t, "* PAGER", renderDetailItem(detailPage.envList.Items()[3]))
+
+	detailPage.handleListDetailsEnter()
+	assert.Equal(t, viewDetail, detailPage.currentUserFlow)
+	detailPage.activePane = detailActionPane
+	detailPage.actionsList.Select(2)
+	assert.Equal(t, "Move Selected to Profile...", renderDetailActionItem(detailPage.actionsList.SelectedItem()))
+	detailPage.handleEnter()
+	assert.Equal(t, moveDetail, detailPage.currentUserFlow)
+	assert.Equal(t, transferDetailProfile, detailPage.currentStage)
+	assert.Len(t, detailPage.transferList.Items(), 1)
+
+	detailPage.handleEnter()
+	assert.Equal(t, transferDetailPolicy, detailPage.currentStage)
+	assert.Equal(t, 1, detailPage.transferConflicts)
+	detailPage.transferList.Select(1)
+	detailPage.handleEnter()
+	assert.Equal(t, transferDetailConfirm, detailPage.currentStage)
+	assert.Equal(t, detailActionPane, detailPage.activePane)
+
+	msg := detailPage.handleEnter()()
+	assert.Equal(t, details[:2], moved)
+	assert.Equal(t, "home", movedTo.Name)
+	assert.Equal(t, data.OverwriteConflicts, movedWith)
+
+	detailPage.Update(msg)
+	assert.Equal(t, listDetails, detailPage.currentUserFlow)
+	assert.Empty(t, detailPage.selected)
+	assert.Equal(t, "Moved 2 details to home (1 overwritten)", detailPage.infoMsg)
+}
+
+func TestTransferDetailsWithoutOtherProfiles(t *testing.T) {
+	detailPage := NewDetailPage(detailModelStub{profiles: []data.Profile{{ID: 1, Name: "work"}}})
+	detailPage.currentProfile = data.Profile{ID: 1, Name: "work"}
+	detailPage.currentUserFlow = viewDetail
+	detailPage.currentDetail = &data.Detail{ID: 1, Key: "EDITOR", DetailType: data.EnvDetail}
+	detailPage.startTransfer(copyDetail)
+	assert.Equal(t, viewDetail, detailPage.currentUserFlow)
+	assert.True(t, detailPage.isErrInfo)
+}
+
+func TestDetailBulkActions(t *testing.T) {
+	details := []data.Detail{
+		{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 2, Key: "TOKEN", Value: "sealed", DetailType: data.EnvDetail, ProfileID: 1, Secret: true},
+		{ID: 3, Key: "LANG", Value: "C", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 4, Key: "k", Value: "kubectl", DetailType: data.AliasDetail, ProfileID: 1},
+	}
+	var deleted []data.Detail
+	detailPage := NewDetailPage(detailModelStub{
+		getAll: func(profileID int) ([]data.Detail, error) { return details, nil },
+		deleteAll: func(details []data.Detail) error {
+			deleted = details
+			return nil
+		},
+	})
+	detailPage.exportDir = t.TempDir()
+	detailPage.currentProfile = data.Profile{ID: 1, Name: "work"}
+	detailPage.Update(retrieveDetailsMsg{details: details})
+
+	// the lists are sorted by name: EDITOR, LANG, TOKEN
+	// a selects everything matching in the active list only
+	selectAll := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}
+	detailPage.Update(selectAll)
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true}, detailPage.selected)
+	assert.Equal(t, "Delete Selected", renderDetailActionItem(detailPage.actionsList.Items()[0]))
+	detailPage.envList.Select(2)
+	detailPage.Update(tea.KeyMsg{Type: tea.KeySpace})
+	assert.Equal(t, map[int]bool{1: true, 2: true}, detailPage.selected)
+
+	// with a selection the display lists it and tab leads to the bulk actions
+	detailPage.envList.Select(0)
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyDown})
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.True(t, detailPage.emptyDisplay)
+	detailPage.handleTab(false)
+	assert.Equal(t, detailDisplayPane, detailPage.activePane)
+	assert.Contains(t, detailPage.View(), "2 details selected")
+	detailPage.handleTab(false)
+	assert.Equal(t, detailActionPane, detailPage.activePane)
+
+	// export leaves the secret out
+	detailPage.actionsList.Select(3)
+	detailPage.handleEnter()
+	assert.Equal(t, exportDetail, detailPage.currentUserFlow)
+	assert.Contains(t, detailPage.View(), "Secret values are left out")
+	msg := detailPage.handleEnter()()
+	path := filepath.Join(detailPage.exportDir, "maggi-work.json")
+	assert.Equal(t, detailsExportedMsg{path: path, count: 1}, msg)
+	exported, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(exported), "EDITOR")
+	assert.NotContains(t, string(exported), "TOKEN")
+	detailPage.Update(msg)
+	assert.Empty(t, detailPage.selected)
+	assert.Equal(t, "Exported 1 detail to "+path, detailPage.infoMsg)
+
+	// delete removes the whole selection at once
+	detailPage.activePane = aliasPane
+	detailPage.Update(selectAll)
+	detailPage.activePane = envPane
+	detailPage.envList.Select(2)
+	detailPage.Update(tea.KeyMsg{Type: tea.KeySpace})
+	detailPage.activePane = detailActionPane
+	detailPage.actionsList.Select(0)
+	detailPage.handleEnter()
+	assert.Equal(t, deleteDetail, detailPage.currentUserFlow)
+	assert.Contains(t, detailPage.generateTitle(), "Delete Selected | 2 details")
+	assert.Contains(t, detailPage.View(), "Delete 2 details?")
+	detailPage.currentStage = deleteDetailConfirm
+	assert.Equal(t, detailEditedMsg{}, detailPage.handleEnter()())
+	assert.Equal(t, []data.Detail{details[2], details[3]}, deleted)
+}
+
+func TestDetailUndo(t *testing.T) {
+	details := []data.Detail{{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1}}
+	stub := detailModelStub{
+		getAll:   func(profileID int) ([]data.Detail, error) { return details, nil },
+		profiles: []data.Profile{{ID: 1, Name: "work-renamed"}},
+		replay: func(redo bool) (data.Operation, error) {
+			if redo {
+				return data.Operation{}, data.ErrNothingToRedo
+			}
+			return data.Operation{Label: "delete env PAGER"}, nil
+		},
+	}
+	detailPage := NewDetailPage(stub)
+	detailPage.currentProfile = data.Profile{ID: 1, Name: "work"}
+	detailPage.Update(retrieveDetailsMsg{details: details})
+
+	_, cmd := detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	msg := cmd()
+	assert.Equal(t, journalMsg{op: data.Operation{Label: "delete env PAGER"}}, msg)
+	_, cmd = detailPage.Update(msg)
+	assert.Nil(t, cmd)
+	assert.Equal(t, "Undid delete env PAGER", detailPage.infoMsg)
+	assert.False(t, detailPage.isErrInfo)
+	assert.Equal(t, "work-renamed", detailPage.currentProfile.Name)
+
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	_, cmd = detailPage.Update(cmd())
+	assert.Nil(t, cmd)
+	assert.Equal(t, "Nothing to redo", detailPage.infoMsg)
+	assert.True(t, detailPage.isErrInfo)
+
+	// undoing the profile itself leaves the page
+	stub.profiles = nil
+	detailPage.repository = stub
+	_, cmd = detailPage.Update(journalMsg{op: data.Operation{Label: "add profile work"}})
+	assert.Equal(t, GenericTurner(profile), cmd())
+
+	// u is typed as usual while searching
+	detailPage.currentProfile = data.Profile{ID: 1, Name: "work"}
+	detailPage.Update(retrieveDetailsMsg{details: details})
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if cmd != nil {
+		_, ok := cmd().(journalMsg)
+		assert.False(t, ok)
+	}
+}
+
+func TestDetailHistory(t *testing.T) {
+	details := []data.Detail{{ID: 1, Key: "EDITOR", Value: "nvim", DetailType: data.EnvDetail, ProfileID: 1}}
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	var restored data.HistoryEntry
+	detailPage := NewDetailPage(detailModelStub{
+		getAll: func(profileID int) ([]data.Detail, error) { return details, nil },
+		history: []data.HistoryEntry{
+			{DetailID: 1, DetailType: data.EnvDetail, Key: "EDITOR", Action: data.HistoryUpdated, OldValue: "vim", NewValue: "nvim", Source: data.TUISource, CreatedAt: at.Add(time.Hour)},
+			{DetailID: 1, DetailType: data.EnvDetail, Key: "EDITOR", Action: data.HistoryDeleted, OldValue: "vi", Source: data.CLISource, CreatedAt: at},
+			{DetailID: 1, DetailType: data.EnvDetail, Key: "EDITOR", Action: data.HistoryAdded, NewValue: "vim", Source: data.CLISource, CreatedAt: at},
+		},
+		restore: func(detail data.Detail, entry data.HistoryEntry) (*data.Detail, error) {
+			restored = entry
+			return &detail, nil
+		},
+	})
+	detailPage.currentProfile = data.Profile{ID: 1, Name: "work"}
+	detailPage.Update(retrieveDetailsMsg{details: details})
+	detailPage.currentUserFlow = viewDetail
+	detailPage.currentDetail = &details[0]
+	detailPage.activePane = detailActionPane
+	detailPage.setActionsList()
+	detailPage.actionsList.Select(len(detailPage.actionsList.Items()) - 1)
+	assert.Equal(t, "History...", renderDetailActionItem(detailPage.actionsList.SelectedItem()))
+	detailPage.handleEnter()
+	assert.Equal(t, historyDetail, detailPage.currentUserFlow)
+	assert.Equal(t, historyDetailChoose, detailPage.currentStage)
+	assert.Equal(t, "2024-05-01 10:30  tui  vim → nvim", renderHistoryItem(detailPage.historyList.Items()[0]))
+	assert.Contains(t, detailPage.generateTitle(), "| History | EDITOR")
+	assert.NotEmpty(t, detailPage.View())
+
+	// a delete has nothing to restore
+	detailPage.historyList.Select(1)
+	detailPage.handleEnter()
+	assert.True(t, detailPage.isErrInfo)
+	assert.Equal(t, historyDetailChoose, detailPage.currentStage)
+
+	detailPage.historyList.Select(2)
+	detailPage.handleEnter()
+	assert.Equal(t, historyDetailConfirm, detailPage.currentStage)
+	assert.Equal(t, detailActionPane, detailPage.activePane)
+	assert.NotEmpty(t, detailPage.View())
+	detailPage.handleTab(false)
+	assert.Equal(t, historyDetailCancel, detailPage.currentStage)
+	detailPage.handleTab(true)
+
+	msg := detailPage.handleEnter()()
+	assert.Equal(t, "vim", restored.NewValue)
+	detailPage.Update(msg)
+	assert.Equal(t, listDetails, detailPage.currentUserFlow)
+	assert.Equal(t, "Restored EDITOR to its value from 2024-05-01 09:30", detailPage.infoMsg)
+}
+
+func TestDetailHistoryEmpty(t *testing.T) {
+	detailPage := NewDetailPage(detailModelStub{})
+	detailPage.currentUserFlow = viewDetail
+	detailPage.currentDetail = &data.Detail{ID: 1, Key: "EDITOR", DetailType: data.EnvDetail}
+	detailPage.startHistory()
+	assert.Equal(t, viewDetail, detailPage.currentUserFlow)
+	assert.Equal(t, "There is no history for EDITOR", detailPage.infoMsg)
+}
@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/edit"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// the changes of an edit scroll past this many lines
+const maxEditorChangesHeight = 10
+
+// editorFunc runs the editor on the file at path, with the program suspended while it
+// runs. done is called once it exits.
+type editorFunc func(path string, done tea.ExecCallback) tea.Cmd
+
+// editorClosedMsg is sent once the editor exits
+type editorClosedMsg struct {
+	path string
+	err  error
+}
+
+// editorAppliedMsg reports the changes made in the editor stored
+type editorAppliedMsg struct {
+	count int
+}
+
+// editorDraft is a file that failed to parse, opened again instead of the stored
+// values so the mistake can be fixed. detailID is 0 for a whole profile.
+type editorDraft struct {
+	detailID int
+	text     string
+}
+
+var (
+	editorAddStyle    = lipgloss.NewStyle().Foreground(green)
+	editorUpdateStyle = lipgloss.NewStyle().Foreground(yellow)
+	editorDeleteStyle = lipgloss.NewStyle().Foreground(red)
+)
+
+// openEditor runs $EDITOR, or vi if it is not set. EDITOR can carry arguments, e.g.
+// `code --wait`.
+func openEditor(path string, done tea.ExecCallback) tea.Cmd {
+	args := strings.Fields(os.Getenv("EDITOR"))
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return tea.ExecProcess(exec.Command(args[0], append(args[1:], path)...), done)
+}
+
+// handleOpenEditor writes the current detail, or the whole profile when no detail is
+// shown or profile is set, to a temporary file and opens it in the editor. a secret is
+// written in plaintext when it is the detail edited, to a file only the user can read
+// that is removed once the editor exits.
+func (d *DetailPage) handleOpenEditor(profile bool) tea.Cmd {
+	d.resetInfoBag()
+	var text string
+	if profile || d.currentDetail == nil || d.emptyDisplay {
+		d.editorDetail = nil
+		d.editorBase = d.details
+		text = edit.Profile(d.currentProfile.Name, d.details)
+	} else {
+		detail := *d.currentDetail
+		if detail.Secret {
+			value, err := d.repository.RevealValue(detail)
+			if err != nil {
+				return func() tea.Msg {
+					return IssueMsg{Inner: err}
+				}
+			}
+			detail.Value = value
+		}
+		d.editorDetail = &detail
+		d.editorBase = []data.Detail{detail}
+		text = edit.Detail(d.currentProfile.Name, detail)
+	}
+	if d.editorDraft != nil && d.editorDraft.detailID == d.editorDetailID() {
+		text = d.editorDraft.text
+	}
+	path, err := writeEditorFile(d.currentProfile.Name, text)
+	if err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	return d.editor(path, func(err error) tea.Msg {
+		return editorClosedMsg{path: path, err: err}
+	})
+}
+
+func (d *DetailPage) editorDetailID() int {
+	if d.editorDetail == nil {
+		return 0
+	}
+	return d.editorDetail.ID
+}
+
+func writeEditorFile(profileName, text string) (string, error) {
+	name := strings.ReplaceAll(profileName, string(os.PathSeparator), "-")
+	file, err := os.CreateTemp("", fmt.Sprintf("maggi-%s-*.env", name))
+	if err != nil {
+		return "", err
+	}
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// handleEditorClosed reads back the file and shows what it changes for confirmation.
+// a file that fails to parse is kept as a draft for the next time the editor opens.
+func (d *DetailPage) handleEditorClosed(msg editorClosedMsg) tea.Cmd {
+	content, readErr := os.ReadFile(msg.path)
+	os.Remove(msg.path)
+	if msg.err != nil {
+		d.infoFlag = true
+		d.isErrInfo = true
+		d.infoMsg = "Unable to run $EDITOR: " + msg.err.Error()
+		return nil
+	}
+	if readErr != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: readErr}
+		}
+	}
+	text := string(content)
+	changes, err := d.editorChanges(text)
+	if err != nil {
+		d.editorDraft = &editorDraft{detailID: d.editorDetailID(), text: text}
+		d.infoFlag = true
+		d.isErrInfo = true
+		d.infoMsg = capitalize(err.Error()) + ". Open the editor again to fix it"
+		return nil
+	}
+	d.editorDraft = nil
+	if changes.Len() == 0 {
+		d.infoFlag = true
+		d.infoMsg = "Nothing changed in the editor"
+		return nil
+	}
+	d.editorEdit = changes
+	lines := edit.Describe(changes, d.editorBase)
+	d.editorView = viewport.New(defaultDisplayWidth-6, min(len(lines), maxEditorChangesHeight))
+	d.editorView.SetContent(renderEditorChanges(lines))
+	d.currentUserFlow = editorDetails
+	d.currentStage = editorDetailConfirm
+	d.activePane = detailActionPane
+	d.updatePaneStyles()
+	return nil
+}
+
+func (d *DetailPage) editorChanges(text string) (data.DetailEdit, error) {
+	if d.editorDetail == nil {
+		entries, err := edit.Parse(text, data.EnvDetail)
+		if err != nil {
+			return data.DetailEdit{}, err
+		}
+		return edit.ProfileChanges(d.currentProfile.ID, d.details, entries)
+	}
+	entries, err := edit.Parse(text, d.editorDetail.DetailType)
+	if err != nil {
+		return data.DetailEdit{}, err
+	}
+	return edit.DetailChanges(*d.editorDetail, d.details, entries)
+}
+
+// renderEditorChanges colours the lines of the change list by the sign they start with
+func renderEditorChanges(lines []string) string {
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		switch trimmed := strings.TrimLeft(line, " "); {
+		case strings.HasPrefix(trimmed, "+"):
+			rendered[i] = editorAddStyle.Render(line)
+		case strings.HasPrefix(trimmed, "-"):
+			rendered[i] = editorDeleteStyle.Render(line)
+		case strings.HasPrefix(trimmed, "~"):
+			rendered[i] = editorUpdateStyle.Render(line)
+		default:
+			rendered[i] = line
+		}
+	}
+	return strings.Join(rendered, "\n")
+}
+
+func (d *DetailPage) handleEditorDetailTab() {
+	switch d.currentStage {
+	case editorDetailConfirm:
+		d.currentStage = editorDetailCancel
+	case editorDetailCancel:
+		d.currentStage = editorDetailConfirm
+	}
+}
+
+func (d *DetailPage) handleEditorDetailEnter() tea.Cmd {
+	switch d.currentStage {
+	case editorDetailCancel:
+		d.editorEdit = data.DetailEdit{}
+		d.handleCancel()
+		return nil
+	case editorDetailConfirm:
+		changes := d.editorEdit
+		return func() tea.Msg {
+			if err := d.repository.EditDetails(changes); err != nil {
+				return IssueMsg{Inner: err}
+			}
+			return editorAppliedMsg{count: changes.Len()}
+		}
+	}
+	return nil
+}
+
+func (d *DetailPage) viewEditorDetails() string {
+	heading := fmt.Sprintf("Apply %s from the editor:", countChanges(d.editorEdit.Len()))
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			d.titleStyle.Render(d.generateTitle()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				d.viewSideBar(),
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					"",
+					d.viewInfo(),
+					d.displayStyle.Render(
+						lipgloss.JoinVertical(
+							lipgloss.Left,
+							d.keyDisplayStyle.Render(heading),
+							d.valueDisplayStyle.Render(d.editorView.View()),
+						),
+					),
+					d.actionsStyle.Render(
+						lipgloss.JoinHorizontal(
+							lipgloss.Left,
+							d.confirmButton.Render("Apply"),
+							d.cancelButton.Render("Cancel"),
+						),
+					),
+				),
+			),
+			d.helpMenu.View(d.keys),
+		),
+	)
+}
+
+func countChanges(n int) string {
+	if n == 1 {
+		return "1 change"
+	}
+	return fmt.Sprintf("%d changes", n)
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEditor replaces the file with the next of edits, keeping what it was given
+type fakeEditor struct {
+	opened []string
+	edits  []string
+}
+
+func (f *fakeEditor) open(path string, done tea.ExecCallback) tea.Cmd {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return func() tea.Msg { return done(err) }
+	}
+	f.opened = append(f.opened, string(content))
+	err = os.WriteFile(path, []byte(f.edits[0]), 0o600)
+	f.edits = f.edits[1:]
+	return func() tea.Msg { return done(err) }
+}
+
+func TestEditProfileInEditor(t *testing.T) {
+	details := []data.Detail{
+		{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 2, Key: "PAGER", Value: "less", DetailType: data.EnvDetail, ProfileID: 1},
+	}
+	var applied data.DetailEdit
+	detailPage := NewDetailPage(detailModelStub{
+		getAll:   func(profileID int) ([]data.Detail, error) { return details, nil },
+		edit:     func(edit data.DetailEdit) error { applied = edit; return nil },
+		settings: map[string]string{},
+	})
+	editor := &fakeEditor{edits: []string{"EDITOR=nvim\n[alias]\nll=ls -l\n"}}
+	detailPage.editor = editor.open
+	_, cmd := detailPage.Update(DetailStartMsg{currentProfile: data.Profile{ID: 1, Name: "work"}})
+	detailPage.Update(cmd())
+
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	msg := cmd()
+	require.Len(t, editor.opened, 1)
+	assert.Contains(t, editor.opened[0], "[env]\nEDITOR=vim\nPAGER=less\n")
+	detailPage.Update(msg)
+	_, err := os.Stat(msg.(editorClosedMsg).path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, editorDetails, detailPage.currentUserFlow)
+	view := detailPage.View()
+	assert.Contains(t, view, "Apply 3 changes from the editor:")
+	assert.Contains(t, view, "+ alias ll=ls -l")
+	assert.Contains(t, view, "~ env EDITOR: vim → nvim")
+	assert.Contains(t, view, "- env PAGER=less")
+
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	detailPage.Update(cmd())
+	assert.Len(t, applied.Added, 1)
+	assert.Len(t, applied.Updated, 1)
+	assert.Equal(t, []data.Detail{details[1]}, applied.Deleted)
+	assert.Equal(t, listDetails, detailPage.currentUserFlow)
+	assert.Equal(t, "Applied 3 changes from the editor", detailPage.infoMsg)
+}
+
+func TestEditDetailInEditor(t *testing.T) {
+	details := []data.Detail{
+		{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1},
+		{ID: 2, Key: "TOKEN", Value: "sealed", DetailType: data.EnvDetail, ProfileID: 1, Secret: true},
+	}
+	detailPage := NewDetailPage(detailModelStub{
+		getAll:   func(profileID int) ([]data.Detail, error) { return details, nil },
+		reveal:   func(detail data.Detail) (string, error) { return "abc", nil },
+		edit:     func(edit data.DetailEdit) error { return nil },
+		settings: map[string]string{},
+	})
+	editor := &fakeEditor{edits: []string{"TOKEN='def", "TOKEN='def'", "TOKEN=abc"}}
+	detailPage.editor = editor.open
+	_, cmd := detailPage.Update(DetailStartMsg{currentProfile: data.Profile{ID: 1, Name: "work"}, focusID: 2})
+	detailPage.Update(cmd())
+
+	// the secret is opened revealed
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	detailPage.Update(cmd())
+	assert.Contains(t, editor.opened[0], "\nTOKEN=abc\n")
+	assert.True(t, detailPage.isErrInfo)
+	assert.Equal(t, "Line 1: TOKEN has no closing quote. Open the editor again to fix it", detailPage.infoMsg)
+	assert.Equal(t, listDetails, detailPage.currentUserFlow)
+
+	// the broken file is opened again rather than the stored value
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	detailPage.Update(cmd())
+	assert.Equal(t, "TOKEN='def", editor.opened[1])
+	assert.Equal(t, editorDetails, detailPage.currentUserFlow)
+	assert.Contains(t, detailPage.View(), "~ env TOKEN: secret value changed")
+	assert.Contains(t, detailPage.View(), "Edit in $EDITOR | TOKEN")
+
+	// cancelling leaves the detail as it is and the draft is gone
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyTab})
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, listDetails, detailPage.currentUserFlow)
+	detailPage.focusDetail(2)
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	detailPage.Update(cmd())
+	assert.Contains(t, editor.opened[2], "\nTOKEN=abc\n")
+	assert.Equal(t, "Nothing changed in the editor", detailPage.infoMsg)
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// profileSearchText is what a profile is searched on: its full name and its tags as
+// listed, then its description
+func profileSearchText(name string, tags []string, description string) string {
+	return name + tagsSuffix(tags) + " " + description
+}
+
+func tagsSuffix(tags []string) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		b.WriteString(" #" + tag)
+	}
+	return b.String()
+}
+
+// searchProfiles fuzzy matches the profiles against query and keeps the matching ones,
+// best match first. matches holds the byte offsets of the matched characters in the
+// search text of each profile, by id.
+func searchProfiles(profiles []data.Profile, query string) (found []data.Profile, matches map[int][]int) {
+	targets := make([]string, len(profiles))
+	for i, profile := range profiles {
+		targets[i] = profileSearchText(profile.Name, profile.Tags, profile.Description)
+	}
+	matches = make(map[int][]int)
+	for _, rank := range list.DefaultFilter(query, targets) {
+		profile := profiles[rank.Index]
+		found = append(found, profile)
+		matches[profile.ID] = rank.MatchedIndexes
+	}
+	return found, matches
+}
+
+// highlights are the runes of the rendered item that matched the search. matches in
+// the groups of the name and in the description are not shown, so not highlighted.
+func (p profileItem) highlights() []int {
+	if len(p.matches) == 0 {
+		return nil
+	}
+	shown := p.shownName() + tagsSuffix(p.tags)
+	// where the shown name starts in the search text
+	start := len(p.name) - len(p.shownName())
+	lead := utf8.RuneCountInString(p.prefix())
+	var runes []int
+	for _, match := range p.matches {
+		offset := match - start
+		if offset < 0 || offset >= len(shown) {
+			continue
+		}
+		runes = append(runes, lead+utf8.RuneCountInString(shown[:offset]))
+	}
+	return runes
+}
+
+// searchQuery is what the profiles are searched for. profiles match on their full
+// name, so searching for a group lists all of its profiles.
+func (p *ProfilePage) searchQuery() string {
+	return strings.ToLower(strings.TrimSpace(p.searchInput.Value()))
+}
+
+// handleSearchKey types into the search, which narrows the list on every key. enter
+// keeps the search and goes back to the list, esc drops it.
+func (p *ProfilePage) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		p.searching = false
+		p.searchInput.Blur()
+		return nil
+	case tea.KeyEsc:
+		p.clearSearch()
+		return nil
+	}
+	var cmd tea.Cmd
+	p.searchInput, cmd = p.searchInput.Update(msg)
+	p.setProfileList()
+	p.setActionsList()
+	return cmd
+}
+
+func (p *ProfilePage) clearSearch() {
+	p.searching = false
+	p.searchInput.SetValue("")
+	p.searchInput.Blur()
+	p.setProfileList()
+	p.setActionsList()
+}
+
+// profilePane is the profile list, led by the search while there is one
+func (p *ProfilePage) profilePane() string {
+	if !p.searching && p.searchQuery() == "" {
+		return p.profileList.View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, p.searchInput.View(), p.profileList.View())
+}
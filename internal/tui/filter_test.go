@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProfiles(t *testing.T) {
+	profiles := []data.Profile{
+		{ID: 1, Name: "prod-eu"},
+		{ID: 2, Name: "personal", Description: "side projects"},
+		{ID: 3, Name: "work", Tags: []string{"prod"}},
+		{ID: 4, Name: "home"},
+	}
+	found, matches := searchProfiles(profiles, "prod")
+	require.Len(t, found, 3)
+	// the closest match comes first, tags and descriptions match too
+	assert.Equal(t, "prod-eu", found[0].Name)
+	assert.ElementsMatch(t, []string{"personal", "work"}, []string{found[1].Name, found[2].Name})
+	assert.Equal(t, []int{0, 1, 2, 3}, matches[1])
+	assert.NotContains(t, matches, 4)
+
+	found, _ = searchProfiles(profiles, "zzz")
+	assert.Empty(t, found)
+}
+
+func TestProfileItemHighlights(t *testing.T) {
+	item := profileItem{name: "client-a/staging", label: "staging", depth: 1, tags: []string{"aws"}, selected: true}
+	assert.Nil(t, item.highlights())
+
+	// "a/st" matched at 7, 8, 9 and 10 of "client-a/staging #aws". the group is not
+	// shown in the row, so only the runes of staging light up, after the indent and
+	// the selection mark
+	item.matches = []int{7, 8, 9, 10}
+	assert.Equal(t, []int{4, 5}, item.highlights())
+
+	// tags are shown, descriptions are not
+	item = profileItem{name: "work", tags: []string{"aws"}, description: "day job", matches: []int{6, 12}}
+	assert.Equal(t, []int{6}, item.highlights())
+	assert.Equal(t, "", profileItem{name: "Add Profile...", action: true}.FilterValue())
+	assert.Equal(t, "", profileItem{name: "client-a", group: "client-a"}.FilterValue())
+}
+
+func TestProfileSearchEnter(t *testing.T) {
+	profiles := []data.Profile{
+		{ID: 1, Name: "work", Description: "day job"},
+		{ID: 2, Name: "home"},
+		{ID: 3, Name: "scratch", Tags: []string{"tmp"}},
+	}
+	profilePage := NewProfilePage(profileModelStub{getAll: func() ([]data.Profile, error) { return profiles, nil }})
+	profilePage.Update(profilePage.getProfiles())
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "tmp" {
+		profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"Add Profile...", "scratch #tmp"}, renderProfileItems(profilePage.profileList.Items()))
+
+	// the cursor starts on the best match, and enter takes the profile listed there
+	assert.Equal(t, 1, profilePage.profileList.Index())
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, profilePage.currentProfile)
+	assert.Equal(t, "scratch", profilePage.currentProfile.Name)
+	assert.Equal(t, actionsPane, profilePage.activePane)
+
+	// adding a profile stays reachable whatever the search
+	profilePage.activePane = profilesPane
+	profilePage.profileList.Select(0)
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, newProfile, profilePage.currentUserFlow)
+}
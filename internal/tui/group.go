@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// profiles are grouped by the parts of their name before a `/`, e.g. client-a/staging
+// and client-a/prod are listed under client-a. groups nest, so client-a/eu/staging is
+// in client-a/eu, itself in client-a.
+const groupSeparator = "/"
+
+type profileEntry struct {
+	group    string
+	profile  data.Profile
+	profiles []data.Profile
+}
+
+// profileTreeItems lists the profiles under their groups. a group takes the place of
+// its first profile, so the groups follow the order of the list too. the profiles of
+// collapsed groups are left out, unless searching, in which case matches holds the
+// matches of every profile listed.
+func (p *ProfilePage) profileTreeItems(profiles []data.Profile, matches map[int][]int, prefix string, depth int) []list.Item {
+	var entries []*profileEntry
+	groups := make(map[string]*profileEntry)
+	for _, profile := range profiles {
+		rest := strings.TrimPrefix(profile.Name, prefix)
+		i := strings.Index(rest, groupSeparator)
+		if i <= 0 || i == len(rest)-1 {
+			entries = append(entries, &profileEntry{profile: profile})
+			continue
+		}
+		group := prefix + rest[:i]
+		entry, ok := groups[group]
+		if !ok {
+			entry = &profileEntry{group: group}
+			groups[group] = entry
+			entries = append(entries, entry)
+		}
+		entry.profiles = append(entry.profiles, profile)
+	}
+
+	var items []list.Item
+	for _, entry := range entries {
+		if entry.group == "" {
+			profile := entry.profile
+			items = append(items, profileItem{
+				id:          profile.ID,
+				name:        profile.Name,
+				label:       strings.TrimPrefix(profile.Name, prefix),
+				depth:       depth,
+				description: profile.Description,
+				tags:        profile.Tags,
+				selected:    p.selected[profile.ID],
+				matches:     matches[profile.ID],
+			})
+			continue
+		}
+		collapsed := p.collapsed[entry.group] && matches == nil
+		items = append(items, profileItem{
+			name:      entry.group,
+			label:     strings.TrimPrefix(entry.group, prefix),
+			depth:     depth,
+			group:     entry.group,
+			count:     len(entry.profiles),
+			collapsed: collapsed,
+		})
+		if !collapsed {
+			items = append(items, p.profileTreeItems(entry.profiles, matches, entry.group+groupSeparator, depth+1)...)
+		}
+	}
+	return items
+}
+
+// groupProfiles returns every profile in the group and the groups nested in it,
+// whether they are listed or not
+func (p *ProfilePage) groupProfiles(group string) []data.Profile {
+	var profiles []data.Profile
+	for _, profile := range p.profiles {
+		if strings.HasPrefix(profile.Name, group+groupSeparator) {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// setGroupActionsList offers the actions on the highlighted group
+func (p *ProfilePage) setGroupActionsList(group string) {
+	toggle := "Collapse Group"
+	if p.collapsed[group] {
+		toggle = "Expand Group"
+	}
+	actionsList := []list.Item{
+		actionItem{
+			description: toggle,
+			next:        toggleGroup,
+		},
+		actionItem{
+			description: "Export Group",
+			next:        exportProfile,
+		},
+		actionItem{
+			description: "Delete Group",
+			next:        deleteProfile,
+		},
+	}
+	w := defaultActionsWidth
+	if p.width < defaultWidth {
+		w = p.width - defaultProfileWidth
+	}
+	h := defaultHeight
+	if (len(p.profiles) + 1) > h {
+		h = len(p.profiles) + 1
+	}
+	p.actionsGroup = group
+	p.actionList = GenerateList(actionsList, renderActionItem, w, h, false)
+	p.updateActionStyle()
+}
+
+// highlightedGroup is the group under the cursor of the profile list, if any
+func (p *ProfilePage) highlightedGroup() string {
+	item, ok := p.profileList.SelectedItem().(profileItem)
+	if !ok {
+		return ""
+	}
+	return item.group
+}
+
+// handleToggleGroup collapses or expands the group and goes back to it in the list
+func (p *ProfilePage) handleToggleGroup(group string) {
+	if p.collapsed == nil {
+		p.collapsed = make(map[string]bool)
+	}
+	if p.collapsed[group] {
+		delete(p.collapsed, group)
+	} else {
+		p.collapsed[group] = true
+	}
+	p.currentUserFlow = listProfiles
+	p.currentStage = chooseAction
+	p.activePane = profilesPane
+	p.setProfileList()
+	for i, item := range p.profileList.Items() {
+		if item, ok := item.(profileItem); ok && item.group == group {
+			p.profileList.Select(i)
+			break
+		}
+	}
+	p.setActionsList()
+	p.updateActionStyle()
+	p.updateProfileStyle()
+}
+
+// selectGroup marks the profiles of the group for the export and delete actions, which
+// work on the selection
+func (p *ProfilePage) selectGroup(group string) {
+	p.currentGroup = group
+	p.selected = make(map[int]bool)
+	for _, profile := range p.groupProfiles(group) {
+		p.selected[profile.ID] = true
+	}
+	p.refreshSelection()
+}
+
+// clearGroup drops the selection made by selectGroup once its action is left
+func (p *ProfilePage) clearGroup() {
+	if p.currentGroup == "" {
+		return
+	}
+	p.currentGroup = ""
+	p.selected = nil
+	p.refreshSelection()
+}
+
+func renderGroupItem(p profileItem) string {
+	indent := strings.Repeat("  ", p.depth)
+	if p.collapsed {
+		return fmt.Sprintf("%s▸ %s%s (%d)", indent, p.label, groupSeparator, p.count)
+	}
+	return fmt.Sprintf("%s▾ %s%s", indent, p.label, groupSeparator)
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderProfileItems(items []list.Item) []string {
+	var rendered []string
+	for _, item := range items {
+		rendered = append(rendered, renderProfileItem(item))
+	}
+	return rendered
+}
+
+func groupedProfiles() []data.Profile {
+	return []data.Profile{
+		{ID: 1, Name: "client-a/prod"},
+		{ID: 2, Name: "home"},
+		{ID: 3, Name: "client-a/eu/staging"},
+		{ID: 4, Name: "client-a/staging"},
+		{ID: 5, Name: "client-b/prod"},
+	}
+}
+
+func TestProfileTree(t *testing.T) {
+	profilePage := NewProfilePage(profileModelStub{getAll: func() ([]data.Profile, error) { return groupedProfiles(), nil }})
+	profilePage.Update(profilePage.getProfiles())
+	assert.Equal(t, []string{
+		"Add Profile...",
+		"▾ client-a/",
+		"  ▾ eu/",
+		"    staging",
+		"  prod",
+		"  staging",
+		"▾ client-b/",
+		"  prod",
+		"home",
+	}, renderProfileItems(profilePage.profileList.Items()))
+
+	// the actions follow the highlighted row
+	assert.Equal(t, "client-a", profilePage.actionsGroup)
+	assert.Equal(t, "Collapse Group", renderActionItem(profilePage.actionList.Items()[0]))
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyDown})
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "", profilePage.actionsGroup)
+	assert.Equal(t, "View Profile", renderActionItem(profilePage.actionList.Items()[0]))
+
+	profilePage.profileList.Select(1)
+	profilePage.setActionsList()
+	profilePage.activePane = actionsPane
+	profilePage.handleListProfilesEnter()
+	assert.Equal(t, listProfiles, profilePage.currentUserFlow)
+	assert.Equal(t, profilesPane, profilePage.activePane)
+	assert.Equal(t, []string{
+		"Add Profile...",
+		"▸ client-a/ (3)",
+		"▾ client-b/",
+		"  prod",
+		"home",
+	}, renderProfileItems(profilePage.profileList.Items()))
+	assert.Equal(t, "Expand Group", renderActionItem(profilePage.actionList.Items()[0]))
+
+	// searching looks into collapsed groups, and matches on the group too. the best
+	// match comes first
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "a/st" {
+		profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	assert.Equal(t, []string{
+		"Add Profile...",
+		"▾ client-a/",
+		"  staging",
+		"  ▾ eu/",
+		"    staging",
+	}, renderProfileItems(profilePage.profileList.Items()))
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, profilePage.searching)
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, "", profilePage.searchQuery())
+	assert.Len(t, profilePage.profileList.Items(), 5)
+}
+
+func TestProfileGroupActions(t *testing.T) {
+	var deleted []data.Profile
+	profilePage := NewProfilePage(profileModelStub{
+		getAll: func() ([]data.Profile, error) { return groupedProfiles(), nil },
+		deleteAll: func(profiles []data.Profile) error {
+			deleted = profiles
+			return nil
+		},
+	})
+	profilePage.exportDir = t.TempDir()
+	profilePage.Update(profilePage.getProfiles())
+
+	// cancelling drops the selection made for the group
+	profilePage.activePane = actionsPane
+	profilePage.actionList.Select(1)
+	profilePage.handleListProfilesEnter()
+	assert.Equal(t, exportProfile, profilePage.currentUserFlow)
+	assert.Equal(t, "client-a", profilePage.currentGroup)
+	assert.Contains(t, profilePage.exportPath(), "maggi-client-a.json")
+	assert.Len(t, profilePage.selected, 3)
+	profilePage.handleEsc()
+	assert.Empty(t, profilePage.selected)
+	assert.Equal(t, "", profilePage.currentGroup)
+
+	profilePage.activePane = actionsPane
+	profilePage.actionList.Select(2)
+	profilePage.handleListProfilesEnter()
+	assert.Equal(t, deleteProfile, profilePage.currentUserFlow)
+	assert.Contains(t, profilePage.generateTitle(), " Delete Group | client-a ")
+	profilePage.currentStage = deleteProfileConfirm
+	cmd := profilePage.handleDeleteProfileEnter()
+	assert.Equal(t, profileDeleteMsg{}, cmd())
+	require.Len(t, deleted, 3)
+	assert.Equal(t, "client-a/prod", deleted[0].Name)
+	assert.Equal(t, "client-a/eu/staging", deleted[1].Name)
+	assert.Equal(t, "client-a/staging", deleted[2].Name)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/bento01dev/maggi/internal/data"
 	"github.com/charmbracelet/bubbles/list"
@@ -30,6 +31,8 @@ const (
 	profile
 	detail
 	issue
+	trashBin
+	globalSearch
 )
 
 type Page interface {
@@ -56,19 +59,31 @@ type ListItemDelegate struct {
 func (i ListItemDelegate) Height() int                             { return 1 }
 func (i ListItemDelegate) Spacing() int                            { return 0 }
 func (i ListItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+// highlightedItem is an item with runes to highlight when rendered, e.g. the matches of
+// a search
+type highlightedItem interface {
+	highlights() []int
+}
+
 func (i ListItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
 	itemStr := i.RenderFunc(listItem)
 	if itemStr == "" {
 		return
 	}
 
-	styleFn := unselectedItemStyle.Render
+	style := unselectedItemStyle
 	if index == m.Index() {
-		styleFn = func(s ...string) string {
-			return selectedItemStyle.Render("> " + strings.Join(s, " "))
-		}
+		style = selectedItemStyle
+	}
+	if item, ok := listItem.(highlightedItem); ok && len(item.highlights()) > 0 {
+		inline := style.Copy().UnsetPadding()
+		itemStr = lipgloss.StyleRunes(itemStr, item.highlights(), inline.Copy().Underline(true).Bold(true), inline)
+	}
+	if index == m.Index() {
+		itemStr = "> " + itemStr
 	}
-	fmt.Fprint(w, styleFn(i.RenderFunc(listItem)))
+	fmt.Fprint(w, style.Render(itemStr))
 }
 
 func GenerateList(items []list.Item, renderFunc ListRenderFunc, width int, height int, filtering bool) list.Model {
@@ -98,27 +113,100 @@ type MaggiModel struct {
 	currentPage pageType
 	pages       map[pageType]Page
 	profile     data.Profile
-	err         error
+	// detailID is the detail to open the detail page on, picked on the search page
+	detailID int
+	// searchFrom is the page the search was opened from
+	searchFrom pageType
+	err        error
 }
 
 type tuiRepository interface {
 	GetDetailsByProfileName(profileName string) ([]data.Detail, error)
 	GetAllDetails(profileId int) ([]data.Detail, error)
-	AddDetail(key string, value string, detailType data.DetailType, profileID int) (*data.Detail, error)
+	AddDetail(detail data.Detail) (*data.Detail, error)
 	UpdateDetail(detail data.Detail, key string, value string) (*data.Detail, error)
 	DeleteDetail(detail data.Detail) error
+	DeleteDetails(details []data.Detail) error
+	RevealValue(detail data.Detail) (string, error)
 	GetAllProfiles() ([]data.Profile, error)
 	AddProfile(name string) (data.Profile, error)
 	UpdateProfile(profile data.Profile, newName string) (data.Profile, error)
 	DeleteProfile(profile data.Profile) error
+	DeleteProfiles(profiles []data.Profile) error
+	CloneProfile(src data.Profile, newName string) (data.Profile, error)
+	SetProfileTags(profile data.Profile, tags []string) (data.Profile, error)
+	CopyDetails(details []data.Detail, target data.Profile, policy data.ConflictPolicy) (data.TransferResult, error)
+	MoveDetails(details []data.Detail, target data.Profile, policy data.ConflictPolicy) (data.TransferResult, error)
+	Undo() (data.Operation, error)
+	Redo() (data.Operation, error)
+	GetTrash() (data.Trash, error)
+	RestoreProfile(profile data.Profile) error
+	RestoreDetail(detail data.Detail) error
+	PurgeProfile(profile data.Profile) error
+	PurgeDetail(detail data.Detail) error
+	PurgeTrash(before time.Time) (int, error)
+	GetHistory(filter data.HistoryFilter) ([]data.HistoryEntry, error)
+	RestoreValue(detail data.Detail, entry data.HistoryEntry) (*data.Detail, error)
+	EditDetails(edit data.DetailEdit) error
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
+}
+
+// journalMsg reports an undo or redo made from any page
+type journalMsg struct {
+	op   data.Operation
+	redo bool
+	err  error
+}
+
+type journalRepository interface {
+	Undo() (data.Operation, error)
+	Redo() (data.Operation, error)
+}
+
+func replayJournal(repository journalRepository, redo bool) tea.Cmd {
+	return func() tea.Msg {
+		replay := repository.Undo
+		if redo {
+			replay = repository.Redo
+		}
+		op, err := replay()
+		return journalMsg{op: op, redo: redo, err: err}
+	}
+}
+
+// nothingToReplay is an empty journal, which is reported on the page rather than as
+// an issue
+func (j journalMsg) nothingToReplay() bool {
+	return errors.Is(j.err, data.ErrNothingToUndo) || errors.Is(j.err, data.ErrNothingToRedo)
+}
+
+func (j journalMsg) info() string {
+	switch {
+	case j.err != nil:
+		return capitalize(j.err.Error())
+	case j.redo:
+		return "Redid " + j.op.Label
+	default:
+		return "Undid " + j.op.Label
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
 }
 
 func NewMaggiModel(debugFlag bool, maggiRepository tuiRepository) *MaggiModel {
 	return &MaggiModel{
 		pages: map[pageType]Page{
-			issue:   NewIssuePage(debugFlag),
-			profile: NewProfilePage(maggiRepository),
-			detail:  NewDetailPage(maggiRepository),
+			issue:        NewIssuePage(debugFlag),
+			profile:      NewProfilePage(maggiRepository),
+			detail:       NewDetailPage(maggiRepository),
+			trashBin:     NewTrashPage(maggiRepository),
+			globalSearch: NewSearchPage(maggiRepository),
 		},
 	}
 }
@@ -143,6 +231,12 @@ func (m *MaggiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 		}
+		// the search is a key away on every page
+		if msg.Type == tea.KeyCtrlF && m.currentPage != globalSearch && m.currentPage != start {
+			m.searchFrom = m.currentPage
+			m.currentPage = globalSearch
+			return m, m.pageInitCmd()
+		}
 	case PageTurner:
 		m.handlePageTransition(msg)
 		return m, m.pageInitCmd()
@@ -158,9 +252,13 @@ func (m *MaggiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *MaggiModel) handlePageTransition(msg PageTurner) {
+	m.detailID = 0
 	switch msg := msg.(type) {
 	case ProfileDoneMsg:
 		m.profile = msg.profile
+	case SearchDoneMsg:
+		m.profile = msg.profile
+		m.detailID = msg.detailID
 	}
 	m.currentPage = msg.Next()
 }
@@ -171,7 +269,11 @@ func (m *MaggiModel) pageInitCmd() tea.Cmd {
 	case profile:
 		msg = ProfileStartMsg{}
 	case detail:
-		msg = DetailStartMsg{currentProfile: m.profile}
+		msg = DetailStartMsg{currentProfile: m.profile, focusID: m.detailID}
+	case trashBin:
+		msg = TrashStartMsg{}
+	case globalSearch:
+		msg = SearchStartMsg{from: m.searchFrom}
 	}
 	return func() tea.Msg {
 		return msg
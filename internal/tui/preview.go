@@ -0,0 +1,249 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/bento01dev/maggi/internal/generate"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// the preview scrolls past this many lines
+const maxPreviewHeight = 15
+
+// previewShells are the shells the preview cycles through with <ctrl+e>
+var previewShells = []generate.Shell{generate.PosixShell, generate.BashShell, generate.ZshShell, generate.FishShell}
+
+type previewFunc func(profileName string, shell generate.Shell) ([]generate.ScriptLine, error)
+
+// scriptPreviewedMsg carries the script generated for the profile
+type scriptPreviewedMsg struct {
+	shell generate.Shell
+	lines []generate.ScriptLine
+	err   error
+}
+
+var (
+	shellKeywordStyle = lipgloss.NewStyle().Foreground(blue)
+	shellStringStyle  = lipgloss.NewStyle().Foreground(green)
+	shellVarStyle     = lipgloss.NewStyle().Foreground(yellow)
+	shellCommentStyle = lipgloss.NewStyle().Foreground(muted)
+	previewWarnStyle  = lipgloss.NewStyle().Foreground(yellow)
+	previewSkipStyle  = lipgloss.NewStyle().Foreground(red)
+)
+
+// shellKeywords are highlighted where a command starts
+var shellKeywords = []string{
+	"alias", "export", "set", "eval", "source", ".", "case", "esac", "if", "then", "else", "fi",
+	"function", "functions", "end", "test", "contains", "not", "or", "and", "printf", "unset", "unalias",
+}
+
+// handlePreview toggles the preview of the script generated for the profile. the shell
+// starts as the one maggi generate would pick.
+func (d *DetailPage) handlePreview() tea.Cmd {
+	if d.currentUserFlow == previewDetails {
+		return d.handleEsc()
+	}
+	if d.previewShell == "" {
+		d.previewShell = generate.DetectShell()
+	}
+	d.resetInfoBag()
+	d.currentUserFlow = previewDetails
+	d.activePane = detailDisplayPane
+	d.previewLines = nil
+	d.updatePaneStyles()
+	return d.generatePreview()
+}
+
+func (d *DetailPage) generatePreview() tea.Cmd {
+	profileName, shell := d.currentProfile.Name, d.previewShell
+	return func() tea.Msg {
+		lines, err := d.preview(profileName, shell)
+		return scriptPreviewedMsg{shell: shell, lines: lines, err: err}
+	}
+}
+
+// handleCyclePreviewShell generates the script again for the next shell
+func (d *DetailPage) handleCyclePreviewShell() tea.Cmd {
+	i := slices.Index(previewShells, d.previewShell)
+	d.previewShell = previewShells[(i+1)%len(previewShells)]
+	return d.generatePreview()
+}
+
+func (d *DetailPage) handlePreviewGenerated(msg scriptPreviewedMsg) {
+	// the shell may have changed while generating
+	if d.currentUserFlow != previewDetails || msg.shell != d.previewShell {
+		return
+	}
+	if msg.err != nil {
+		d.infoFlag = true
+		d.isErrInfo = true
+		d.infoMsg = capitalize(msg.err.Error())
+		return
+	}
+	d.previewLines = msg.lines
+	if d.previewLines == nil {
+		// nil is kept for a preview still generating
+		d.previewLines = []generate.ScriptLine{}
+	}
+	content := renderPreview(msg.lines, defaultDisplayWidth)
+	d.previewView = viewport.New(defaultDisplayWidth, min(lipgloss.Height(content), maxPreviewHeight))
+	d.previewView.SetContent(content)
+}
+
+// handlePreviewKey scrolls the preview. the other keys of the page don't apply to it.
+func (d *DetailPage) handlePreviewKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.Type == tea.KeyEsc || msg.String() == "p":
+		return d.handlePreview()
+	case msg.Type == tea.KeyCtrlE:
+		return d.handleCyclePreviewShell()
+	}
+	var cmd tea.Cmd
+	d.previewView, cmd = d.previewView.Update(msg)
+	return cmd
+}
+
+// previewWarnings counts the details with a warning in the preview
+func previewWarnings(lines []generate.ScriptLine) int {
+	var warnings int
+	for _, line := range lines {
+		if line.Warning != "" {
+			warnings++
+		}
+	}
+	return warnings
+}
+
+// renderPreview shows the script a detail per line. the script itself has no line
+// breaks between details, joining the lines gives it back as generate prints it.
+// details that fail validation are shown where they would be, marked with ⚠.
+func renderPreview(lines []generate.ScriptLine, width int) string {
+	if len(lines) == 0 {
+		return shellCommentStyle.Render("# nothing to apply")
+	}
+	wrap := lipgloss.NewStyle().Width(width - 2)
+	var rows []string
+	for _, line := range lines {
+		if line.Skipped {
+			rows = append(rows, previewWarnStyle.Render("⚠ ")+wrap.Render(previewSkipStyle.Render(fmt.Sprintf("# skipped %s %s: %s", line.DetailType, line.Key, line.Warning))))
+			continue
+		}
+		gutter := "  "
+		if line.Warning != "" {
+			gutter = previewWarnStyle.Render("⚠ ")
+		}
+		for _, text := range strings.Split(strings.TrimSuffix(line.Text, "\n"), "\n") {
+			rows = append(rows, gutter+wrap.Render(highlightShell(text)))
+		}
+		if line.Warning != "" {
+			rows = append(rows, "  "+wrap.Render(previewWarnStyle.Render("# "+line.Warning)))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// highlightShell colours a line of the generated script: keywords where a command
+// starts, quoted strings, variables and comments. it only needs to cope with what
+// generate writes, not with shell syntax at large.
+func highlightShell(line string) string {
+	var b strings.Builder
+	runes := []rune(line)
+	commandStart := true
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '#' && (i == 0 || unicode.IsSpace(runes[i-1])):
+			b.WriteString(shellCommentStyle.Render(string(runes[i:])))
+			return b.String()
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && (runes[end] != r || (r == '"' && runes[end-1] == '\\')) {
+				end++
+			}
+			end = min(end+1, len(runes))
+			b.WriteString(shellStringStyle.Render(string(runes[i:end])))
+			i = end
+			commandStart = false
+		case r == '$' && i+1 < len(runes) && (runes[i+1] == '{' || runes[i+1] == '_' || unicode.IsLetter(runes[i+1])):
+			end := i + 1
+			if runes[end] == '{' {
+				for end < len(runes) && runes[end] != '}' {
+					end++
+				}
+				end = min(end+1, len(runes))
+			} else {
+				for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+					end++
+				}
+			}
+			b.WriteString(shellVarStyle.Render(string(runes[i:end])))
+			i = end
+			commandStart = false
+		case strings.ContainsRune(";|&()", r):
+			b.WriteRune(r)
+			i++
+			commandStart = true
+		case unicode.IsSpace(r):
+			b.WriteRune(r)
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(";|&()'\"$", runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			if commandStart && slices.Contains(shellKeywords, word) {
+				word = shellKeywordStyle.Render(word)
+			}
+			b.WriteString(word)
+			i = end
+			commandStart = false
+		}
+	}
+	return b.String()
+}
+
+func (d *DetailPage) viewPreviewDetails() string {
+	heading := fmt.Sprintf("maggi generate --profile %s --shell %s", d.currentProfile.Name, d.previewShell)
+	content := d.previewView.View()
+	if d.previewLines == nil && !d.isErrInfo {
+		content = "Generating.."
+	}
+	var footer string
+	if warnings := previewWarnings(d.previewLines); warnings > 0 {
+		footer = previewWarnStyle.Render(fmt.Sprintf("⚠ %s with warnings", countDetails(warnings)))
+	}
+	return lipgloss.Place(
+		d.width,
+		d.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			d.titleStyle.Render(d.generateTitle()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				d.viewSideBar(),
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					"",
+					d.viewInfo(),
+					d.displayStyle.Render(
+						lipgloss.JoinVertical(
+							lipgloss.Left,
+							d.keyDisplayStyle.Render(heading),
+							content,
+							footer,
+						),
+					),
+				),
+			),
+			d.helpMenu.View(d.keys),
+		),
+	)
+}
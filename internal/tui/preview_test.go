@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/generate"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetailPreview(t *testing.T) {
+	details := []data.Detail{{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail}}
+	detailPage := NewDetailPage(detailModelStub{getAll: func(profileID int) ([]data.Detail, error) { return details, nil }, settings: map[string]string{}})
+	var shells []generate.Shell
+	detailPage.preview = func(profileName string, shell generate.Shell) ([]generate.ScriptLine, error) {
+		assert.Equal(t, "work", profileName)
+		shells = append(shells, shell)
+		if shell == generate.FishShell {
+			return nil, errors.New("no key for secrets found")
+		}
+		return []generate.ScriptLine{
+			{Text: "export EDITOR=vim;", Key: "EDITOR", DetailType: data.EnvDetail},
+			{Text: "export PATH=/bin;", Key: "PATH", DetailType: data.EnvDetail, Warning: "setting PATH replaces it entirely"},
+			{Key: "MY-VAR", DetailType: data.EnvDetail, Warning: "not a valid env var name", Skipped: true},
+		}, nil
+	}
+	detailPage.previewShell = generate.ZshShell
+	_, cmd := detailPage.Update(DetailStartMsg{currentProfile: data.Profile{ID: 1, Name: "work"}})
+	detailPage.Update(cmd())
+
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	assert.Equal(t, previewDetails, detailPage.currentUserFlow)
+	assert.Contains(t, detailPage.View(), "Generating..")
+	detailPage.Update(cmd())
+	view := detailPage.View()
+	assert.Contains(t, view, "maggi generate --profile work --shell zsh")
+	assert.Contains(t, view, "export EDITOR=vim;")
+	assert.Contains(t, view, "⚠ # skipped env MY-VAR: not a valid env var name")
+	assert.Contains(t, view, "# setting PATH replaces it entirely")
+	assert.Contains(t, view, "2 details with warnings")
+
+	// the other keys of the page are left alone while previewing
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	assert.Equal(t, previewDetails, detailPage.currentUserFlow)
+
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	detailPage.Update(cmd())
+	assert.Equal(t, generate.FishShell, detailPage.previewShell)
+	assert.True(t, detailPage.isErrInfo)
+	assert.Equal(t, "No key for secrets found", detailPage.infoMsg)
+	_, cmd = detailPage.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	detailPage.Update(cmd())
+	assert.Equal(t, []generate.Shell{generate.ZshShell, generate.FishShell, generate.PosixShell}, shells)
+
+	detailPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	assert.Equal(t, listDetails, detailPage.currentUserFlow)
+	assert.Equal(t, envPane, detailPage.activePane)
+}
+
+func TestHighlightShell(t *testing.T) {
+	// highlighting only adds colour, the text stays as generated
+	for _, line := range []string{
+		`export URL='https://example.com/$x';alias ll=ls -l;`,
+		`case ":${PATH}:" in *":/opt/bin:"*) ;; *) export PATH="${PATH:+${PATH}:}/opt/bin";; esac;`,
+		`set -gx EDITOR vim; # a comment`,
+		`eval "$(printf '%b' 'unterminated`,
+	} {
+		assert.Equal(t, line, highlightShell(line))
+	}
+	require.Equal(t, "# nothing to apply", renderPreview(nil, defaultDisplayWidth))
+}
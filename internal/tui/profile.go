@@ -4,10 +4,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
+	"github.com/bento01dev/maggi/internal/compare"
 	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/export"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -28,6 +32,7 @@ func (p ProfileDoneMsg) Next() pageType {
 
 type retrieveMsg struct {
 	profiles []data.Profile
+	sort     sortMode
 	err      error
 }
 
@@ -37,6 +42,16 @@ type profileAddMsg struct {
 
 type profileDeleteMsg struct{}
 
+// profilesExportedMsg reports the selected profiles written to an export file
+type profilesExportedMsg struct {
+	path  string
+	count int
+}
+
+type profileCompareMsg struct {
+	result compare.Result
+}
+
 const (
 	defaultWidth        int = 120
 	defaultProfileWidth int = 30
@@ -58,6 +73,12 @@ const (
 	viewProfile
 	updateProfile
 	deleteProfile
+	compareProfile
+	cloneProfile
+	exportProfile
+	describeProfile
+	tagProfile
+	toggleGroup
 )
 
 type profilePagePane int
@@ -84,6 +105,19 @@ const (
 	deleteProfileView
 	deleteProfileConfirm
 	deleteProfileCancel
+	compareProfileChoose
+	compareProfileView
+	cloneProfileName
+	cloneProfileConfirm
+	cloneProfileCancel
+	exportProfileConfirm
+	exportProfileCancel
+	describeProfileText
+	describeProfileConfirm
+	describeProfileCancel
+	tagProfileText
+	tagProfileConfirm
+	tagProfileCancel
 )
 
 type actionItem struct {
@@ -101,18 +135,60 @@ func renderActionItem(i list.Item) string {
 }
 
 type profileItem struct {
-	id     int
-	name   string
-	action bool
+	id   int
+	name string
+	// label is the name shown in the list, without the groups it is listed under
+	label       string
+	depth       int
+	description string
+	tags        []string
+	action      bool
+	selected    bool
+	// group is set on the rows of groups, to the full name of the group. count is the
+	// number of profiles listed in it
+	group     string
+	count     int
+	collapsed bool
+	// matches are the byte offsets matched by the search in the search text
+	matches []int
+}
+
+func (p profileItem) profile() *data.Profile {
+	return &data.Profile{ID: p.id, Name: p.name, Description: p.description, Tags: p.tags}
+}
+
+func (p profileItem) FilterValue() string {
+	if p.action || p.group != "" {
+		return ""
+	}
+	return profileSearchText(p.name, p.tags, p.description)
+}
+
+func (p profileItem) shownName() string {
+	if p.label != "" {
+		return p.label
+	}
+	return p.name
+}
+
+// prefix is what the row starts with before the name
+func (p profileItem) prefix() string {
+	prefix := strings.Repeat("  ", p.depth)
+	if p.selected {
+		prefix += "* "
+	}
+	return prefix
 }
 
-func (p profileItem) FilterValue() string { return "" }
 func renderProfileItem(i list.Item) string {
 	p, ok := i.(profileItem)
 	if !ok {
 		return ""
 	}
-	return p.name
+	if p.group != "" {
+		return renderGroupItem(p)
+	}
+	return p.prefix() + p.shownName() + tagsSuffix(p.tags)
 }
 
 type profileHelpKeys struct {
@@ -120,6 +196,15 @@ type profileHelpKeys struct {
 	Quit       key.Binding
 	Up         key.Binding
 	Down       key.Binding
+	Select     key.Binding
+	SelectAll  key.Binding
+	Undo       key.Binding
+	Redo       key.Binding
+	Trash      key.Binding
+	Sort       key.Binding
+	Filter     key.Binding
+	Search     key.Binding
+	SearchAll  key.Binding
 	Esc        key.Binding
 }
 
@@ -130,6 +215,8 @@ func (h profileHelpKeys) ShortHelp() []key.Binding {
 func (h profileHelpKeys) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{h.ToggleView, h.Up, h.Down},
+		{h.Select, h.SelectAll, h.Sort, h.Filter, h.Search},
+		{h.SearchAll, h.Undo, h.Redo, h.Trash},
 		{h.Esc, h.Quit},
 	}
 }
@@ -150,31 +237,59 @@ type profilePageRepository interface {
 	AddProfile(name string) (data.Profile, error)
 	UpdateProfile(profile data.Profile, newName string) (data.Profile, error)
 	DeleteProfile(profile data.Profile) error
+	DeleteProfiles(profiles []data.Profile) error
+	GetDetailsByProfileName(name string) ([]data.Detail, error)
+	RevealValue(detail data.Detail) (string, error)
+	CloneProfile(src data.Profile, newName string) (data.Profile, error)
+	SetProfileTags(profile data.Profile, tags []string) (data.Profile, error)
+	Undo() (data.Operation, error)
+	Redo() (data.Operation, error)
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
 }
 
 type ProfilePage struct {
-	newProfileOption  bool
-	infoFlag          bool
-	isErrInfo         bool
-	width             int
-	height            int
-	currentUserFlow   profileUserFlow
-	activePane        profilePagePane
-	currentStage      profileStage
-	repository        profilePageRepository
-	currentProfile    *data.Profile
-	infoMsg           string
-	actions           []string
-	profiles          []data.Profile
-	actionList        list.Model
-	actionsStyle      lipgloss.Style
-	profileList       list.Model
-	profilesStyle     lipgloss.Style
+	newProfileOption bool
+	infoFlag         bool
+	isErrInfo        bool
+	width            int
+	height           int
+	currentUserFlow  profileUserFlow
+	activePane       profilePagePane
+	currentStage     profileStage
+	repository       profilePageRepository
+	currentProfile   *data.Profile
+	infoMsg          string
+	actions          []string
+	profiles         []data.Profile
+	actionList       list.Model
+	actionsStyle     lipgloss.Style
+	profileList      list.Model
+	profilesStyle    lipgloss.Style
+	compareList      list.Model
+	comparison       *compare.Result
+	selected         map[int]bool
+	sort             sortMode
+	// tagFilter limits the list to the profiles with the tag. empty lists them all
+	tagFilter string
+	// collapsed holds the groups listed without their profiles
+	collapsed map[string]bool
+	// actionsGroup is the group the actions list offers actions for, empty when it
+	// offers the actions on a profile. currentGroup is the group being exported or
+	// deleted.
+	actionsGroup string
+	currentGroup string
+	searching    bool
+	searchInput  textinput.Model
+	// export files are written here. empty means the working directory
+	exportDir         string
 	helpMenu          help.Model
 	keys              profileHelpKeys
 	titleStyle        lipgloss.Style
 	headingStyle      lipgloss.Style
 	textInput         textinput.Model
+	descriptionInput  textinput.Model
+	tagsInput         textinput.Model
 	highlightedButton lipgloss.Style
 	mutedButton       lipgloss.Style
 	deleteButton      lipgloss.Style
@@ -213,6 +328,42 @@ func NewProfilePage(repository profilePageRepository) *ProfilePage {
 			key.WithKeys("down"),
 			key.WithHelp("↓", "move down"),
 		),
+		Select: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("<space>", "select"),
+		),
+		SelectAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "select all"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("<ctrl+r>", "redo"),
+		),
+		Trash: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "trash"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort: name"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "tag: all"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search"),
+		),
+		SearchAll: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("<ctrl+f>", "search all profiles"),
+		),
 		Esc: key.NewBinding(
 			key.WithKeys("<esc>"),
 			key.WithHelp("<esc>", "quit view"),
@@ -221,7 +372,7 @@ func NewProfilePage(repository profilePageRepository) *ProfilePage {
 	actionsStyle := lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).Width(defaultActionsWidth).UnsetPadding()
 	profilesStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultProfileWidth).UnsetPadding()
 	issuesStyle := lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).UnsetPadding().BorderForeground(red)
-	actions := []string{"View Profile", "Update Profile", "Delete Profile"}
+	actions := []string{"View Profile", "Update Profile", "Delete Profile", "Compare Profile", "Clone Profile"}
 	titleStyle := lipgloss.NewStyle().Foreground(green)
 	headingStyle := lipgloss.NewStyle().Foreground(blue)
 
@@ -231,6 +382,25 @@ func NewProfilePage(repository profilePageRepository) *ProfilePage {
 	input.Width = 50
 	input.Prompt = ""
 
+	descriptionInput := textinput.New()
+	descriptionInput.Placeholder = "What is this profile for? (optional).."
+	descriptionInput.Width = 50
+	descriptionInput.Prompt = ""
+
+	tagsInput := textinput.New()
+	tagsInput.Placeholder = "aws, k8s.."
+	tagsInput.Width = 50
+	tagsInput.Prompt = ""
+
+	searchInput := textinput.New()
+	searchInput.Placeholder = "Search.."
+	searchInput.CharLimit = 50
+	searchInput.Width = defaultProfileWidth - 2
+	searchInput.Prompt = "> "
+	searchInput.PromptStyle = lipgloss.NewStyle().Foreground(blue)
+	searchInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(muted)
+	searchInput.TextStyle = lipgloss.NewStyle().Foreground(blue)
+
 	baseButton := lipgloss.NewStyle().Padding(buttonPaddingVertical, buttonPaddingHorizontal).MarginLeft(1).Foreground(lipgloss.Color("0"))
 	confirmButton := baseButton.Copy().Background(green)
 	cancelButton := baseButton.Copy().Background(muted)
@@ -247,6 +417,9 @@ func NewProfilePage(repository profilePageRepository) *ProfilePage {
 		headingStyle:      headingStyle,
 		issuesStyle:       issuesStyle,
 		textInput:         input,
+		descriptionInput:  descriptionInput,
+		tagsInput:         tagsInput,
+		searchInput:       searchInput,
 		highlightedButton: confirmButton,
 		mutedButton:       cancelButton,
 		deleteButton:      deleteButton,
@@ -265,6 +438,9 @@ func (p *ProfilePage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return p.getProfiles()
 		}
 	case tea.KeyMsg:
+		if p.searching {
+			return p, p.handleSearchKey(msg)
+		}
 		switch msg.Type {
 		case tea.KeyTab:
 			p.handleTab(false)
@@ -275,8 +451,45 @@ func (p *ProfilePage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEnter:
 			return p, p.handleEnter()
 		case tea.KeyEsc:
+			if p.selecting() && p.searchQuery() != "" {
+				p.clearSearch()
+				return p, nil
+			}
 			p.handleEsc()
 			return p, nil
+		case tea.KeySpace:
+			if p.selecting() {
+				p.handleToggleSelect()
+				return p, nil
+			}
+		case tea.KeyRunes:
+			if msg.String() == "a" && p.selecting() {
+				p.handleSelectAll()
+				return p, nil
+			}
+			if msg.String() == "u" && p.selecting() {
+				return p, replayJournal(p.repository, false)
+			}
+			if msg.String() == "t" && p.selecting() {
+				return p, func() tea.Msg {
+					return GenericTurner(trashBin)
+				}
+			}
+			if msg.String() == "s" && p.selecting() {
+				return p, p.handleSort()
+			}
+			if msg.String() == "f" && p.selecting() {
+				p.handleTagFilter()
+				return p, nil
+			}
+			if msg.String() == "/" && p.selecting() {
+				p.searching = true
+				return p, p.searchInput.Focus()
+			}
+		case tea.KeyCtrlR:
+			if p.selecting() {
+				return p, replayJournal(p.repository, true)
+			}
 		}
 	case retrieveMsg:
 		if msg.err != nil {
@@ -293,11 +506,17 @@ func (p *ProfilePage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		p.currentUserFlow = listProfiles
 		p.profiles = msg.profiles
+		p.setSort(msg.sort)
+		p.selected = nil
 		p.activePane = profilesPane
 		p.setActionsList()
 		p.setProfileList()
 		return p, nil
 	case profileAddMsg, profileDeleteMsg:
+		if _, ok := msg.(profileDeleteMsg); ok {
+			p.selected = nil
+			p.currentGroup = ""
+		}
 		err := p.resetProfiles()
 		if err != nil {
 			return p, func() tea.Msg {
@@ -307,11 +526,53 @@ func (p *ProfilePage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		p.setActionsList()
 		p.setProfileList()
 		return p, nil
+	case profilesExportedMsg:
+		p.selected = nil
+		p.currentGroup = ""
+		p.currentUserFlow = listProfiles
+		p.currentStage = chooseAction
+		p.activePane = profilesPane
+		p.infoFlag = true
+		p.isErrInfo = false
+		p.infoMsg = fmt.Sprintf("Exported %d profiles to %s", msg.count, msg.path)
+		p.setActionsList()
+		p.setProfileList()
+		return p, nil
+	case journalMsg:
+		return p, p.handleJournal(msg)
+	case profileCompareMsg:
+		p.comparison = &msg.result
+		p.currentStage = compareProfileView
+		return p, nil
 	}
 	cmd := p.handleEvent(msg)
 	return p, cmd
 }
 
+// handleJournal reloads the profiles after an undo or redo, which can touch any of them
+func (p *ProfilePage) handleJournal(msg journalMsg) tea.Cmd {
+	if msg.err != nil && !msg.nothingToReplay() {
+		return func() tea.Msg {
+			return IssueMsg{Inner: msg.err}
+		}
+	}
+	if err := p.resetProfiles(); err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	p.selected = nil
+	p.currentUserFlow = listProfiles
+	p.currentStage = chooseAction
+	p.activePane = profilesPane
+	p.infoFlag = true
+	p.isErrInfo = msg.err != nil
+	p.infoMsg = msg.info()
+	p.setActionsList()
+	p.setProfileList()
+	return nil
+}
+
 func (p *ProfilePage) resetInfoBag() {
 	p.infoFlag = false
 	p.isErrInfo = false
@@ -323,7 +584,96 @@ func (p *ProfilePage) getProfiles() retrieveMsg {
 	if err != nil {
 		return retrieveMsg{err: err}
 	}
-	return retrieveMsg{profiles: profiles}
+	sort, err := loadSortMode(p.repository, profileSortSetting, profileSortModes)
+	if err != nil {
+		return retrieveMsg{err: err}
+	}
+	return retrieveMsg{profiles: profiles, sort: sort}
+}
+
+func (p *ProfilePage) setSort(sort sortMode) {
+	if sort == "" {
+		sort = sortByName
+	}
+	p.sort = sort
+	p.keys.Sort.SetHelp("s", "sort: "+sort.label())
+}
+
+// handleSort switches to the next sort of the list and stores it for the next start.
+// the cursor stays on the profile it was on.
+func (p *ProfilePage) handleSort() tea.Cmd {
+	sort := p.sort.next(profileSortModes)
+	if err := p.repository.SetSetting(profileSortSetting, string(sort)); err != nil {
+		return func() tea.Msg {
+			return IssueMsg{Inner: err}
+		}
+	}
+	current, _ := p.profileList.SelectedItem().(profileItem)
+	p.setSort(sort)
+	p.setProfileList()
+	for i, item := range p.profileList.Items() {
+		if item, ok := item.(profileItem); ok && item.id == current.id && item.action == current.action && item.group == current.group {
+			p.profileList.Select(i)
+			break
+		}
+	}
+	p.infoFlag = true
+	p.isErrInfo = false
+	p.infoMsg = "Profiles sorted by " + sort.label()
+	return nil
+}
+
+// handleTagFilter limits the list to the next tag in use, and back to every profile
+// after the last one
+func (p *ProfilePage) handleTagFilter() {
+	tags := p.tags()
+	i := slices.Index(tags, p.tagFilter)
+	p.tagFilter = ""
+	if i+1 < len(tags) {
+		p.tagFilter = tags[i+1]
+	}
+	p.setTagFilterHelp()
+	p.setProfileList()
+	p.infoFlag = true
+	p.isErrInfo = false
+	p.infoMsg = "Showing all profiles"
+	if p.tagFilter != "" {
+		p.infoMsg = "Showing profiles tagged " + p.tagFilter
+	}
+}
+
+func (p *ProfilePage) setTagFilterHelp() {
+	if p.tagFilter == "" {
+		p.keys.Filter.SetHelp("f", "tag: all")
+		return
+	}
+	p.keys.Filter.SetHelp("f", "tag: "+p.tagFilter)
+}
+
+// tags returns every tag of the profiles, sorted
+func (p *ProfilePage) tags() []string {
+	var tags []string
+	for _, profile := range p.profiles {
+		tags = append(tags, profile.Tags...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// visibleProfiles are the profiles passing the tag filter and the search, in the order
+// they are listed: best match first while searching, else by the sort of the list.
+// matches is nil unless searching, see searchProfiles.
+func (p *ProfilePage) visibleProfiles() (profiles []data.Profile, matches map[int][]int) {
+	for _, profile := range p.profiles {
+		if p.tagFilter == "" || slices.Contains(profile.Tags, p.tagFilter) {
+			profiles = append(profiles, profile)
+		}
+	}
+	sortProfiles(profiles, p.sort)
+	if query := p.searchQuery(); query != "" {
+		return searchProfiles(profiles, query)
+	}
+	return profiles, nil
 }
 
 func (p *ProfilePage) addProfile(name string) error {
@@ -343,7 +693,20 @@ func (p *ProfilePage) updateProfile(profile *data.Profile, newName string) error
 	return nil
 }
 
+func (p *ProfilePage) cloneProfile(profile *data.Profile, newName string) error {
+	_, err := p.repository.CloneProfile(*profile, newName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// deleteProfile deletes the selected profiles if any, which is also how a group is
+// deleted
 func (p *ProfilePage) deleteProfile(profile *data.Profile) error {
+	if len(p.selected) > 0 {
+		return p.repository.DeleteProfiles(p.selectedProfiles())
+	}
 	err := p.repository.DeleteProfile(*profile)
 	if err != nil {
 		return err
@@ -371,6 +734,15 @@ func (p *ProfilePage) checkDuplicate(name string) bool {
 }
 
 func (p *ProfilePage) setActionsList() {
+	if len(p.selected) > 0 {
+		p.setBulkActionsList()
+		return
+	}
+	if group := p.highlightedGroup(); group != "" {
+		p.setGroupActionsList(group)
+		return
+	}
+	p.actionsGroup = ""
 	actionItems := []actionItem{
 		actionItem{
 			description: "View Profile",
@@ -384,6 +756,22 @@ func (p *ProfilePage) setActionsList() {
 			description: "Delete Profile",
 			next:        deleteProfile,
 		},
+		actionItem{
+			description: "Compare Profile",
+			next:        compareProfile,
+		},
+		actionItem{
+			description: "Clone Profile",
+			next:        cloneProfile,
+		},
+		actionItem{
+			description: "Describe Profile",
+			next:        describeProfile,
+		},
+		actionItem{
+			description: "Tag Profile",
+			next:        tagProfile,
+		},
 	}
 	var elems []string
 	var actionsList []list.Item
@@ -405,6 +793,114 @@ func (p *ProfilePage) setActionsList() {
 	p.updateActionStyle()
 }
 
+// setBulkActionsList offers the actions that work on every selected profile at once
+func (p *ProfilePage) setBulkActionsList() {
+	actionsList := []list.Item{
+		actionItem{
+			description: "Delete Selected",
+			next:        deleteProfile,
+		},
+		actionItem{
+			description: "Export Selected",
+			next:        exportProfile,
+		},
+	}
+	w := defaultActionsWidth
+	if p.width < defaultWidth {
+		w = p.width - defaultProfileWidth
+	}
+	h := defaultHeight
+	if (len(p.profiles) + 1) > h {
+		h = len(p.profiles) + 1
+	}
+	p.actionsGroup = ""
+	p.actionList = GenerateList(actionsList, renderActionItem, w, h, false)
+	p.updateActionStyle()
+}
+
+func (p *ProfilePage) selecting() bool {
+	return p.currentUserFlow == listProfiles && p.activePane == profilesPane
+}
+
+func (p *ProfilePage) handleToggleSelect() {
+	item, ok := p.profileList.SelectedItem().(profileItem)
+	if !ok || item.action || item.group != "" {
+		return
+	}
+	if p.selected == nil {
+		p.selected = make(map[int]bool)
+	}
+	if p.selected[item.id] {
+		delete(p.selected, item.id)
+	} else {
+		p.selected[item.id] = true
+	}
+	p.refreshSelection()
+}
+
+// handleSelectAll marks every listed profile, or unmarks them all if they are marked
+// already
+func (p *ProfilePage) handleSelectAll() {
+	profiles, _ := p.visibleProfiles()
+	if len(profiles) == 0 {
+		return
+	}
+	if len(p.selected) == len(profiles) {
+		p.selected = nil
+	} else {
+		p.selected = make(map[int]bool)
+		for _, profile := range profiles {
+			p.selected[profile.ID] = true
+		}
+	}
+	p.refreshSelection()
+}
+
+func (p *ProfilePage) refreshSelection() {
+	items := p.profileList.Items()
+	for i, listItem := range items {
+		if item, ok := listItem.(profileItem); ok && !item.action && item.group == "" {
+			item.selected = p.selected[item.id]
+			items[i] = item
+		}
+	}
+	p.profileList.SetItems(items)
+	p.setActionsList()
+}
+
+func (p *ProfilePage) selectedProfiles() []data.Profile {
+	var profiles []data.Profile
+	for _, profile := range p.profiles {
+		if p.selected[profile.ID] {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// exportPath is the file the selected profiles are exported to. it is replaced if it
+// exists already.
+func (p *ProfilePage) exportPath() string {
+	if p.currentGroup != "" {
+		return filepath.Join(p.exportDir, "maggi-"+strings.ReplaceAll(p.currentGroup, groupSeparator, "-")+".json")
+	}
+	return filepath.Join(p.exportDir, "maggi-profiles.json")
+}
+
+// exportProfiles writes the profiles in the format of `maggi export`, as a list.
+// secrets are left out, the same as the command does by default.
+func (p *ProfilePage) exportProfiles(path string, profiles []data.Profile) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = export.ExportProfiles(file, profiles, false, p.repository)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 func (p *ProfilePage) resetProfiles() error {
 	newProfiles, err := p.repository.GetAllProfiles()
 	if err != nil {
@@ -418,17 +914,52 @@ func (p *ProfilePage) resetProfiles() error {
 }
 
 func (p *ProfilePage) setProfileList() {
-	profilesList := []list.Item{}
-	for _, profile := range p.profiles {
-		profilesList = append(profilesList, profileItem{id: profile.ID, name: profile.Name})
+	// the last profile with the tag may have just lost it
+	if !slices.Contains(p.tags(), p.tagFilter) {
+		p.tagFilter = ""
+		p.setTagFilterHelp()
 	}
-	profilesList = append(profilesList, profileItem{name: "Add Profile...", action: true})
+	profiles, matches := p.visibleProfiles()
+	// adding a profile stays at the top, whatever the list is narrowed down to
+	profilesList := []list.Item{profileItem{name: "Add Profile...", action: true}}
+	profilesList = append(profilesList, p.profileTreeItems(profiles, matches, "", 0)...)
 	h := len(profilesList)
 	if h < defaultHeight {
 		h = defaultHeight
 	}
 	p.profileList = GenerateList(profilesList, renderProfileItem, 30, h, false)
+	if len(profilesList) > 1 {
+		p.profileList.Select(1)
+	}
 	p.updateProfileStyle()
+	// a group may be highlighted now, or no longer
+	if p.highlightedGroup() != p.actionsGroup {
+		p.setActionsList()
+	}
+}
+
+// setCompareList lists every profile except the current one to compare it with
+func (p *ProfilePage) setCompareList() {
+	var items []list.Item
+	for _, profile := range p.profiles {
+		if profile.ID == p.currentProfile.ID {
+			continue
+		}
+		items = append(items, profileItem{id: profile.ID, name: profile.Name})
+	}
+	h := len(items)
+	if h < defaultHeight {
+		h = defaultHeight
+	}
+	p.compareList = GenerateList(items, renderProfileItem, defaultProfileWidth, h, false)
+}
+
+func (p *ProfilePage) compareProfiles(other string) tea.Msg {
+	result, err := compare.Profiles(p.repository, p.currentProfile.Name, other)
+	if err != nil {
+		return IssueMsg{Inner: err}
+	}
+	return profileCompareMsg{result: result}
 }
 
 func (p *ProfilePage) updateActionStyle() {
@@ -459,6 +990,16 @@ func (p *ProfilePage) handleTab(shift bool) {
 		p.handleUpdateProfileTab(shift)
 	case deleteProfile:
 		p.handleDeleteProfileTab(shift)
+	case compareProfile:
+		p.handleListProfilesTab()
+	case cloneProfile:
+		p.handleCloneProfileTab(shift)
+	case exportProfile:
+		p.handleExportProfileTab(shift)
+	case describeProfile:
+		p.handleDescribeProfileTab(shift)
+	case tagProfile:
+		p.handleTagProfileTab(shift)
 	}
 	p.updateActionStyle()
 	p.updateProfileStyle()
@@ -544,19 +1085,20 @@ func (p *ProfilePage) handleUpdateProfileTab(shift bool) {
 	}
 }
 
-func (p *ProfilePage) handleDeleteProfileTab(shift bool) {
+func (p *ProfilePage) handleCloneProfileTab(shift bool) {
 	if shift {
 		switch p.activePane {
 		case profilesPane:
 			p.activePane = actionsPane
 		case actionsPane:
 			switch p.currentStage {
-			case deleteProfileView:
+			case cloneProfileName:
 				p.activePane = profilesPane
-			case deleteProfileConfirm:
-				p.currentStage = deleteProfileView
-			case deleteProfileCancel:
-				p.currentStage = deleteProfileConfirm
+				p.resetInfoBag()
+			case cloneProfileConfirm:
+				p.currentStage = cloneProfileName
+			case cloneProfileCancel:
+				p.currentStage = cloneProfileConfirm
 			}
 		}
 		return
@@ -567,61 +1109,219 @@ func (p *ProfilePage) handleDeleteProfileTab(shift bool) {
 		p.activePane = actionsPane
 	case actionsPane:
 		switch p.currentStage {
-		case deleteProfileView:
-			p.currentStage = deleteProfileConfirm
-		case deleteProfileConfirm:
-			p.currentStage = deleteProfileCancel
-		case deleteProfileCancel:
-			p.currentStage = deleteProfileView
+		case cloneProfileName:
+			p.currentStage = cloneProfileConfirm
+		case cloneProfileConfirm:
+			p.currentStage = cloneProfileCancel
+		case cloneProfileCancel:
+			p.currentStage = cloneProfileName
 			p.activePane = profilesPane
+			p.resetInfoBag()
 		}
 	}
 }
 
-func (p *ProfilePage) handleEnter() tea.Cmd {
-	switch p.currentUserFlow {
-	case listProfiles:
-		return p.handleListProfilesEnter()
-	case newProfile:
-		return p.handleNewProfileEnter()
-	case updateProfile:
-		return p.handleUpdateProfileEnter()
-	case deleteProfile:
-		return p.handleDeleteProfileEnter()
-	default:
-		return nil
+func (p *ProfilePage) handleDescribeProfileTab(shift bool) {
+	if shift {
+		switch p.activePane {
+		case profilesPane:
+			p.activePane = actionsPane
+		case actionsPane:
+			switch p.currentStage {
+			case describeProfileText:
+				p.activePane = profilesPane
+				p.resetInfoBag()
+			case describeProfileConfirm:
+				p.currentStage = describeProfileText
+			case describeProfileCancel:
+				p.currentStage = describeProfileConfirm
+			}
+		}
+		return
 	}
-}
 
-func (p *ProfilePage) handleListProfilesEnter() tea.Cmd {
 	switch p.activePane {
 	case profilesPane:
-		item, ok := p.profileList.SelectedItem().(profileItem)
-		if !ok {
-			return func() tea.Msg {
-				return errors.New("item not found in list. unknown issue")
-			}
-		}
 		p.activePane = actionsPane
-		p.updateActionStyle()
-		p.updateProfileStyle()
-		if item.action {
-			p.currentUserFlow = newProfile
-			p.currentStage = addProfileName
-			return tea.Batch(p.textInput.Focus(), p.textInput.Cursor.BlinkCmd())
-		}
-		p.currentProfile = &data.Profile{ID: item.id, Name: item.name}
-		p.currentStage = chooseAction
-		return nil
 	case actionsPane:
-		item, ok := p.actionList.SelectedItem().(actionItem)
-		if !ok {
-			return func() tea.Msg {
-				return errors.New("item not found in list. unknown issue")
+		switch p.currentStage {
+		case describeProfileText:
+			p.currentStage = describeProfileConfirm
+		case describeProfileConfirm:
+			p.currentStage = describeProfileCancel
+		case describeProfileCancel:
+			p.currentStage = describeProfileText
+			p.activePane = profilesPane
+			p.resetInfoBag()
+		}
+	}
+}
+
+func (p *ProfilePage) handleTagProfileTab(shift bool) {
+	if shift {
+		switch p.activePane {
+		case profilesPane:
+			p.activePane = actionsPane
+		case actionsPane:
+			switch p.currentStage {
+			case tagProfileText:
+				p.activePane = profilesPane
+				p.resetInfoBag()
+			case tagProfileConfirm:
+				p.currentStage = tagProfileText
+			case tagProfileCancel:
+				p.currentStage = tagProfileConfirm
 			}
 		}
-		p.currentUserFlow = item.next
-		switch p.currentUserFlow {
+		return
+	}
+
+	switch p.activePane {
+	case profilesPane:
+		p.activePane = actionsPane
+	case actionsPane:
+		switch p.currentStage {
+		case tagProfileText:
+			p.currentStage = tagProfileConfirm
+		case tagProfileConfirm:
+			p.currentStage = tagProfileCancel
+		case tagProfileCancel:
+			p.currentStage = tagProfileText
+			p.activePane = profilesPane
+			p.resetInfoBag()
+		}
+	}
+}
+
+func (p *ProfilePage) handleExportProfileTab(shift bool) {
+	if shift {
+		switch p.activePane {
+		case profilesPane:
+			p.activePane = actionsPane
+			p.currentStage = exportProfileCancel
+		case actionsPane:
+			switch p.currentStage {
+			case exportProfileConfirm:
+				p.activePane = profilesPane
+			case exportProfileCancel:
+				p.currentStage = exportProfileConfirm
+			}
+		}
+		return
+	}
+
+	switch p.activePane {
+	case profilesPane:
+		p.activePane = actionsPane
+		p.currentStage = exportProfileConfirm
+	case actionsPane:
+		switch p.currentStage {
+		case exportProfileConfirm:
+			p.currentStage = exportProfileCancel
+		case exportProfileCancel:
+			p.activePane = profilesPane
+		}
+	}
+}
+
+func (p *ProfilePage) handleDeleteProfileTab(shift bool) {
+	if shift {
+		switch p.activePane {
+		case profilesPane:
+			p.activePane = actionsPane
+		case actionsPane:
+			switch p.currentStage {
+			case deleteProfileView:
+				p.activePane = profilesPane
+			case deleteProfileConfirm:
+				p.currentStage = deleteProfileView
+			case deleteProfileCancel:
+				p.currentStage = deleteProfileConfirm
+			}
+		}
+		return
+	}
+
+	switch p.activePane {
+	case profilesPane:
+		p.activePane = actionsPane
+	case actionsPane:
+		switch p.currentStage {
+		case deleteProfileView:
+			p.currentStage = deleteProfileConfirm
+		case deleteProfileConfirm:
+			p.currentStage = deleteProfileCancel
+		case deleteProfileCancel:
+			p.currentStage = deleteProfileView
+			p.activePane = profilesPane
+		}
+	}
+}
+
+func (p *ProfilePage) handleEnter() tea.Cmd {
+	switch p.currentUserFlow {
+	case listProfiles:
+		return p.handleListProfilesEnter()
+	case newProfile:
+		return p.handleNewProfileEnter()
+	case updateProfile:
+		return p.handleUpdateProfileEnter()
+	case deleteProfile:
+		return p.handleDeleteProfileEnter()
+	case compareProfile:
+		return p.handleCompareProfileEnter()
+	case cloneProfile:
+		return p.handleCloneProfileEnter()
+	case exportProfile:
+		return p.handleExportProfileEnter()
+	case describeProfile:
+		return p.handleDescribeProfileEnter()
+	case tagProfile:
+		return p.handleTagProfileEnter()
+	default:
+		return nil
+	}
+}
+
+func (p *ProfilePage) handleListProfilesEnter() tea.Cmd {
+	p.resetInfoBag()
+	switch p.activePane {
+	case profilesPane:
+		item, ok := p.profileList.SelectedItem().(profileItem)
+		if !ok {
+			return func() tea.Msg {
+				return errors.New("item not found in list. unknown issue")
+			}
+		}
+		p.activePane = actionsPane
+		p.updateActionStyle()
+		p.updateProfileStyle()
+		if item.action {
+			p.currentUserFlow = newProfile
+			p.currentStage = addProfileName
+			return tea.Batch(p.textInput.Focus(), p.textInput.Cursor.BlinkCmd())
+		}
+		p.currentProfile = item.profile()
+		p.currentStage = chooseAction
+		return nil
+	case actionsPane:
+		item, ok := p.actionList.SelectedItem().(actionItem)
+		if !ok {
+			return func() tea.Msg {
+				return errors.New("item not found in list. unknown issue")
+			}
+		}
+		if p.actionsGroup != "" && (item.next == exportProfile || item.next == deleteProfile) {
+			p.selectGroup(p.actionsGroup)
+		}
+		p.currentUserFlow = item.next
+		switch p.currentUserFlow {
+		case toggleGroup:
+			p.handleToggleGroup(p.actionsGroup)
+			return nil
+		case exportProfile:
+			p.resetInfoBag()
+			p.currentStage = exportProfileConfirm
 		case updateProfile:
 			item, ok := p.profileList.SelectedItem().(profileItem)
 			if !ok {
@@ -629,7 +1329,7 @@ func (p *ProfilePage) handleListProfilesEnter() tea.Cmd {
 					return errors.New("unknown item in list")
 				}
 			}
-			p.currentProfile = &data.Profile{ID: item.id, Name: item.name}
+			p.currentProfile = item.profile()
 			p.infoFlag = true
 			p.infoMsg = fmt.Sprintf("You are trying to update %s with a new name. Please follow the instructions below.", p.currentProfile.Name)
 			p.currentStage = updateProfileName
@@ -642,14 +1342,68 @@ func (p *ProfilePage) handleListProfilesEnter() tea.Cmd {
 					return errors.New("unknown item in list")
 				}
 			}
-			p.currentProfile = &data.Profile{ID: item.id, Name: item.name}
+			p.currentProfile = item.profile()
+		case cloneProfile:
+			item, ok := p.profileList.SelectedItem().(profileItem)
+			if !ok {
+				return func() tea.Msg {
+					return errors.New("unknown item in list")
+				}
+			}
+			p.currentProfile = item.profile()
+			p.infoFlag = true
+			p.infoMsg = fmt.Sprintf("You are cloning %s with all its details into a new profile. Please follow the instructions below.", p.currentProfile.Name)
+			p.currentStage = cloneProfileName
+			return tea.Batch(p.textInput.Focus(), p.textInput.Cursor.BlinkCmd())
+		case describeProfile:
+			item, ok := p.profileList.SelectedItem().(profileItem)
+			if !ok {
+				return func() tea.Msg {
+					return errors.New("unknown item in list")
+				}
+			}
+			p.currentProfile = item.profile()
+			p.infoFlag = true
+			p.infoMsg = fmt.Sprintf("Note what %s is for, shown on its details page and written by export. Leave it empty to remove the description.", p.currentProfile.Name)
+			p.descriptionInput.SetValue(p.currentProfile.Description)
+			p.currentStage = describeProfileText
+			return tea.Batch(p.descriptionInput.Focus(), p.descriptionInput.Cursor.BlinkCmd())
+		case tagProfile:
+			item, ok := p.profileList.SelectedItem().(profileItem)
+			if !ok {
+				return func() tea.Msg {
+					return errors.New("unknown item in list")
+				}
+			}
+			p.currentProfile = item.profile()
+			p.infoFlag = true
+			p.infoMsg = fmt.Sprintf("Tag %s to filter the list by it and apply all profiles with a tag with `maggi generate --tag <tag>`. Separate tags with commas or spaces.", p.currentProfile.Name)
+			p.tagsInput.SetValue(strings.Join(p.currentProfile.Tags, ", "))
+			p.currentStage = tagProfileText
+			return tea.Batch(p.tagsInput.Focus(), p.tagsInput.Cursor.BlinkCmd())
+		case compareProfile:
+			item, ok := p.profileList.SelectedItem().(profileItem)
+			if !ok {
+				return func() tea.Msg {
+					return errors.New("unknown item in list")
+				}
+			}
+			p.currentProfile = item.profile()
+			p.comparison = nil
+			p.setCompareList()
+			p.currentStage = compareProfileChoose
+			if len(p.compareList.Items()) == 0 {
+				p.infoFlag = true
+				p.isErrInfo = true
+				p.infoMsg = fmt.Sprintf("There is no other profile to compare %s with. You can exit flow by pressing <esc>", item.name)
+			}
 		case viewProfile:
 			return func() tea.Msg {
 				item, ok := p.profileList.SelectedItem().(profileItem)
 				if !ok {
 					return errors.New("unknown item in list")
 				}
-				return ProfileDoneMsg{profile: data.Profile{ID: item.id, Name: item.name}}
+				return ProfileDoneMsg{profile: *item.profile()}
 			}
 		}
 		return nil
@@ -751,6 +1505,132 @@ func (p *ProfilePage) handleUpdateProfileEnter() tea.Cmd {
 	return nil
 }
 
+func (p *ProfilePage) handleCloneProfileEnter() tea.Cmd {
+	p.resetInfoBag()
+	input := strings.TrimSpace(p.textInput.Value())
+	switch p.currentStage {
+	case cloneProfileName:
+		if input == "" {
+			p.infoFlag = true
+			p.isErrInfo = true
+			p.infoMsg = fmt.Sprintf("Please provide a name for the clone of %s. You can exit flow by pressing <esc> if needed", p.currentProfile.Name)
+			return tea.Batch(p.textInput.Focus(), p.textInput.Cursor.BlinkCmd())
+		}
+		p.currentStage = cloneProfileConfirm
+		return nil
+	case cloneProfileConfirm:
+		if p.checkDuplicate(input) {
+			p.infoMsg = fmt.Sprintf("The name %s is already taken. Try another name or update the existing one first!", input)
+			p.infoFlag = true
+			p.isErrInfo = true
+			p.textInput.SetValue("")
+			p.currentStage = cloneProfileName
+			p.issuesStyle = p.issuesStyle.Copy().Width(len(p.infoMsg) + 1)
+			return tea.Batch(p.textInput.Focus(), p.textInput.Cursor.BlinkCmd())
+		}
+		p.currentUserFlow = listProfiles
+		p.currentStage = chooseAction
+		p.activePane = profilesPane
+
+		return func() tea.Msg {
+			err := p.cloneProfile(p.currentProfile, input)
+			if err != nil {
+				return IssueMsg{Inner: err}
+			}
+			p.textInput.SetValue("")
+			return profileAddMsg{success: true}
+		}
+	case cloneProfileCancel:
+		p.currentStage = chooseAction
+		p.textInput.SetValue("")
+		p.currentUserFlow = listProfiles
+		p.activePane = profilesPane
+		p.updateActionStyle()
+		p.updateProfileStyle()
+		return nil
+	}
+	return nil
+}
+
+func (p *ProfilePage) handleDescribeProfileEnter() tea.Cmd {
+	p.resetInfoBag()
+	input := strings.TrimSpace(p.descriptionInput.Value())
+	switch p.currentStage {
+	case describeProfileText:
+		p.currentStage = describeProfileConfirm
+		return nil
+	case describeProfileConfirm:
+		if input == p.currentProfile.Description {
+			p.infoFlag = true
+			p.isErrInfo = true
+			p.infoMsg = fmt.Sprintf("The description of %s is unchanged. You can exit flow by pressing <esc> if needed", p.currentProfile.Name)
+			p.currentStage = describeProfileText
+			return tea.Batch(p.descriptionInput.Focus(), p.descriptionInput.Cursor.BlinkCmd())
+		}
+		p.currentUserFlow = listProfiles
+		p.currentStage = chooseAction
+		p.activePane = profilesPane
+
+		profile := *p.currentProfile
+		profile.Description = input
+		return func() tea.Msg {
+			if _, err := p.repository.UpdateProfile(profile, profile.Name); err != nil {
+				return IssueMsg{Inner: err}
+			}
+			p.descriptionInput.SetValue("")
+			return profileAddMsg{success: true}
+		}
+	case describeProfileCancel:
+		p.currentStage = chooseAction
+		p.descriptionInput.SetValue("")
+		p.currentUserFlow = listProfiles
+		p.activePane = profilesPane
+		p.updateActionStyle()
+		p.updateProfileStyle()
+		return nil
+	}
+	return nil
+}
+
+func (p *ProfilePage) handleTagProfileEnter() tea.Cmd {
+	p.resetInfoBag()
+	tags := data.ParseTags(p.tagsInput.Value())
+	switch p.currentStage {
+	case tagProfileText:
+		p.currentStage = tagProfileConfirm
+		return nil
+	case tagProfileConfirm:
+		if slices.Equal(tags, p.currentProfile.Tags) {
+			p.infoFlag = true
+			p.isErrInfo = true
+			p.infoMsg = fmt.Sprintf("The tags of %s are unchanged. You can exit flow by pressing <esc> if needed", p.currentProfile.Name)
+			p.currentStage = tagProfileText
+			return tea.Batch(p.tagsInput.Focus(), p.tagsInput.Cursor.BlinkCmd())
+		}
+		p.currentUserFlow = listProfiles
+		p.currentStage = chooseAction
+		p.activePane = profilesPane
+
+		profile := *p.currentProfile
+		return func() tea.Msg {
+			if _, err := p.repository.SetProfileTags(profile, tags); err != nil {
+				return IssueMsg{Inner: err}
+			}
+			p.tagsInput.SetValue("")
+			return profileAddMsg{success: true}
+		}
+	case tagProfileCancel:
+		p.currentStage = chooseAction
+		p.tagsInput.SetValue("")
+		p.currentUserFlow = listProfiles
+		p.activePane = profilesPane
+		p.updateActionStyle()
+		p.updateProfileStyle()
+		return nil
+	}
+	return nil
+}
+
 func (p *ProfilePage) handleDeleteProfileEnter() tea.Cmd {
 	switch p.currentStage {
 	case deleteProfileView:
@@ -761,6 +1641,7 @@ func (p *ProfilePage) handleDeleteProfileEnter() tea.Cmd {
 		p.currentProfile = nil
 		p.activePane = profilesPane
 		p.currentUserFlow = listProfiles
+		p.clearGroup()
 		p.updateActionStyle()
 		p.updateProfileStyle()
 		return nil
@@ -781,12 +1662,57 @@ func (p *ProfilePage) handleDeleteProfileEnter() tea.Cmd {
 	return nil
 }
 
+func (p *ProfilePage) handleExportProfileEnter() tea.Cmd {
+	switch p.currentStage {
+	case exportProfileCancel:
+		p.currentStage = chooseAction
+		p.activePane = profilesPane
+		p.currentUserFlow = listProfiles
+		p.clearGroup()
+		p.updateActionStyle()
+		p.updateProfileStyle()
+		return nil
+	case exportProfileConfirm:
+		profiles := p.selectedProfiles()
+		path := p.exportPath()
+		return func() tea.Msg {
+			if err := p.exportProfiles(path, profiles); err != nil {
+				return IssueMsg{Inner: err}
+			}
+			return profilesExportedMsg{path: path, count: len(profiles)}
+		}
+	}
+	return nil
+}
+
+func (p *ProfilePage) handleCompareProfileEnter() tea.Cmd {
+	switch p.currentStage {
+	case compareProfileChoose:
+		item, ok := p.compareList.SelectedItem().(profileItem)
+		if !ok {
+			return nil
+		}
+		p.resetInfoBag()
+		return func() tea.Msg {
+			return p.compareProfiles(item.name)
+		}
+	case compareProfileView:
+		// back to the list to compare with another profile
+		p.comparison = nil
+		p.currentStage = compareProfileChoose
+	}
+	return nil
+}
+
 func (p *ProfilePage) handleEsc() {
 	p.currentUserFlow = listProfiles
 	p.activePane = profilesPane
+	p.clearGroup()
 	p.updateActionStyle()
 	p.updateProfileStyle()
 	p.textInput.SetValue("")
+	p.descriptionInput.SetValue("")
+	p.tagsInput.SetValue("")
 	p.infoMsg = ""
 	p.isErrInfo = false
 	p.infoFlag = false
@@ -807,12 +1733,23 @@ func (p *ProfilePage) handleEvent(msg tea.Msg) tea.Cmd {
 			p.newProfileOption = false
 			p.currentUserFlow = listProfiles
 		}
+		if item.group != p.actionsGroup && len(p.selected) == 0 {
+			p.setActionsList()
+		}
 	case actionsPane:
 		switch p.currentUserFlow {
 		case listProfiles:
 			p.actionList, cmd = p.actionList.Update(msg)
-		case newProfile, updateProfile:
+		case newProfile, updateProfile, cloneProfile:
 			p.textInput, cmd = p.textInput.Update(msg)
+		case describeProfile:
+			p.descriptionInput, cmd = p.descriptionInput.Update(msg)
+		case tagProfile:
+			p.tagsInput, cmd = p.tagsInput.Update(msg)
+		case compareProfile:
+			if p.currentStage == compareProfileChoose {
+				p.compareList, cmd = p.compareList.Update(msg)
+			}
 		}
 	}
 	return cmd
@@ -830,6 +1767,25 @@ func (p *ProfilePage) generateTitle() string {
 		second = fmt.Sprintf(" Update Profile | %s ", p.currentProfile.Name)
 	case deleteProfile:
 		second = fmt.Sprintf(" Delete Profile | %s ", p.currentProfile.Name)
+		if len(p.selected) > 0 {
+			second = fmt.Sprintf(" Delete Selected | %d profiles ", len(p.selected))
+		}
+		if p.currentGroup != "" {
+			second = fmt.Sprintf(" Delete Group | %s ", p.currentGroup)
+		}
+	case exportProfile:
+		second = fmt.Sprintf(" Export Selected | %d profiles ", len(p.selected))
+		if p.currentGroup != "" {
+			second = fmt.Sprintf(" Export Group | %s ", p.currentGroup)
+		}
+	case compareProfile:
+		second = fmt.Sprintf(" Compare Profile | %s ", p.currentProfile.Name)
+	case cloneProfile:
+		second = fmt.Sprintf(" Clone Profile | %s ", p.currentProfile.Name)
+	case describeProfile:
+		second = fmt.Sprintf(" Describe Profile | %s ", p.currentProfile.Name)
+	case tagProfile:
+		second = fmt.Sprintf(" Tag Profile | %s ", p.currentProfile.Name)
 	}
 	third := strings.Repeat("-", (defaultWidth - (len(second) + 3)))
 	return first + second + third
@@ -1004,8 +1960,223 @@ func (p *ProfilePage) viewUpdateProfile() string {
 	)
 }
 
+func (p *ProfilePage) viewCloneProfile() string {
+	var textInputStyle, confirmButtonStyle, cancelButtonStyle, infoStyle lipgloss.Style
+	switch p.currentStage {
+	case cloneProfileName:
+		textInputStyle = p.actionsStyle.Copy()
+		confirmButtonStyle = p.mutedButton.Copy()
+		if p.textInput.Value() != "" {
+			confirmButtonStyle = p.highlightedButton.Copy()
+		}
+		cancelButtonStyle = p.mutedButton.Copy()
+	case cloneProfileConfirm:
+		textInputStyle = p.actionsStyle.BorderForeground(muted)
+		confirmButtonStyle = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+		if p.textInput.Value() == "" {
+			confirmButtonStyle = p.mutedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+		}
+		cancelButtonStyle = p.mutedButton.Copy()
+	case cloneProfileCancel:
+		textInputStyle = p.actionsStyle.BorderForeground(muted)
+		confirmButtonStyle = p.mutedButton.Copy()
+		cancelButtonStyle = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+	}
+
+	form := lipgloss.JoinHorizontal(
+		lipgloss.Center,
+		p.profilesStyle.Render(p.profileList.View()),
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			p.headingStyle.Render("Clone Name:"),
+			textInputStyle.Render(p.textInput.View()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				confirmButtonStyle.Render("Clone"),
+				cancelButtonStyle.Render("Cancel"),
+			),
+		),
+	)
+
+	if p.infoFlag {
+		infoStyle = p.issuesStyle.Copy().BorderForeground(green)
+		if p.isErrInfo {
+			infoStyle = p.issuesStyle.Copy().BorderForeground(red)
+		}
+
+		return lipgloss.Place(
+			p.width,
+			p.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				p.titleStyle.Render(p.generateTitle()),
+				infoStyle.Render(p.infoMsg),
+				form,
+				p.helpMenu.View(p.keys),
+			),
+		)
+	}
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			p.titleStyle.Render(p.generateTitle()),
+			form,
+			p.helpMenu.View(p.keys),
+		),
+	)
+}
+
+func (p *ProfilePage) viewDescribeProfile() string {
+	var textInputStyle, confirmButtonStyle, cancelButtonStyle, infoStyle lipgloss.Style
+	switch p.currentStage {
+	case describeProfileText:
+		textInputStyle = p.actionsStyle.Copy()
+		confirmButtonStyle = p.highlightedButton.Copy()
+		cancelButtonStyle = p.mutedButton.Copy()
+	case describeProfileConfirm:
+		textInputStyle = p.actionsStyle.BorderForeground(muted)
+		confirmButtonStyle = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+		cancelButtonStyle = p.mutedButton.Copy()
+	case describeProfileCancel:
+		textInputStyle = p.actionsStyle.BorderForeground(muted)
+		confirmButtonStyle = p.mutedButton.Copy()
+		cancelButtonStyle = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+	}
+
+	form := lipgloss.JoinHorizontal(
+		lipgloss.Center,
+		p.profilesStyle.Render(p.profileList.View()),
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			p.headingStyle.Render("Description:"),
+			textInputStyle.Render(p.descriptionInput.View()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				confirmButtonStyle.Render("Save"),
+				cancelButtonStyle.Render("Cancel"),
+			),
+		),
+	)
+
+	if p.infoFlag {
+		infoStyle = p.issuesStyle.Copy().BorderForeground(green)
+		if p.isErrInfo {
+			infoStyle = p.issuesStyle.Copy().BorderForeground(red)
+		}
+
+		return lipgloss.Place(
+			p.width,
+			p.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				p.titleStyle.Render(p.generateTitle()),
+				infoStyle.Render(p.infoMsg),
+				form,
+				p.helpMenu.View(p.keys),
+			),
+		)
+	}
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			p.titleStyle.Render(p.generateTitle()),
+			form,
+			p.helpMenu.View(p.keys),
+		),
+	)
+}
+
+func (p *ProfilePage) viewTagProfile() string {
+	var textInputStyle, confirmButtonStyle, cancelButtonStyle, infoStyle lipgloss.Style
+	switch p.currentStage {
+	case tagProfileText:
+		textInputStyle = p.actionsStyle.Copy()
+		confirmButtonStyle = p.highlightedButton.Copy()
+		cancelButtonStyle = p.mutedButton.Copy()
+	case tagProfileConfirm:
+		textInputStyle = p.actionsStyle.BorderForeground(muted)
+		confirmButtonStyle = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+		cancelButtonStyle = p.mutedButton.Copy()
+	case tagProfileCancel:
+		textInputStyle = p.actionsStyle.BorderForeground(muted)
+		confirmButtonStyle = p.mutedButton.Copy()
+		cancelButtonStyle = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+	}
+
+	form := lipgloss.JoinHorizontal(
+		lipgloss.Center,
+		p.profilesStyle.Render(p.profileList.View()),
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			p.headingStyle.Render("Tags:"),
+			textInputStyle.Render(p.tagsInput.View()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				confirmButtonStyle.Render("Save"),
+				cancelButtonStyle.Render("Cancel"),
+			),
+		),
+	)
+
+	if p.infoFlag {
+		infoStyle = p.issuesStyle.Copy().BorderForeground(green)
+		if p.isErrInfo {
+			infoStyle = p.issuesStyle.Copy().BorderForeground(red)
+		}
+
+		return lipgloss.Place(
+			p.width,
+			p.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				p.titleStyle.Render(p.generateTitle()),
+				infoStyle.Render(p.infoMsg),
+				form,
+				p.helpMenu.View(p.keys),
+			),
+		)
+	}
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			p.titleStyle.Render(p.generateTitle()),
+			form,
+			p.helpMenu.View(p.keys),
+		),
+	)
+}
+
 func (p *ProfilePage) viewListProfile() string {
-	if p.newProfileOption {
+	title := p.titleStyle.Render(p.generateTitle())
+	if p.infoFlag {
+		infoStyle := p.issuesStyle.Copy().BorderForeground(green)
+		if p.isErrInfo {
+			infoStyle = p.issuesStyle.Copy().BorderForeground(red)
+		}
+		title = lipgloss.JoinVertical(lipgloss.Center, title, infoStyle.Render(p.infoMsg))
+	}
+	if p.newProfileOption && len(p.selected) == 0 {
 		h := defaultHeight
 		if len(p.profiles)+1 > h {
 			h = len(p.profiles) + 1
@@ -1017,10 +2188,10 @@ func (p *ProfilePage) viewListProfile() string {
 			lipgloss.Center,
 			lipgloss.JoinVertical(
 				lipgloss.Center,
-				p.titleStyle.Render(p.generateTitle()),
+				title,
 				lipgloss.JoinHorizontal(
 					lipgloss.Center,
-					p.profilesStyle.Render(p.profileList.View()),
+					p.profilesStyle.Render(p.profilePane()),
 					p.actionsStyle.Copy().Height(h).Render(""),
 				),
 				p.helpMenu.View(p.keys),
@@ -1034,10 +2205,10 @@ func (p *ProfilePage) viewListProfile() string {
 		lipgloss.Center,
 		lipgloss.JoinVertical(
 			lipgloss.Center,
-			p.titleStyle.Render(p.generateTitle()),
+			title,
 			lipgloss.JoinHorizontal(
 				lipgloss.Center,
-				p.profilesStyle.Render(p.profileList.View()),
+				p.profilesStyle.Render(p.profilePane()),
 				p.actionsStyle.Render(p.actionList.View()),
 			),
 			p.helpMenu.View(p.keys),
@@ -1045,9 +2216,32 @@ func (p *ProfilePage) viewListProfile() string {
 	)
 }
 
+// selectionSummary lists the selected profiles below msg, for the confirmation of bulk
+// actions
+func (p *ProfilePage) selectionSummary(msg string) string {
+	lines := []string{msg}
+	profiles := p.selectedProfiles()
+	for i, profile := range profiles {
+		if i == maxSelectionLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(profiles)-i))
+			break
+		}
+		lines = append(lines, "  "+profile.Name)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (p *ProfilePage) viewDeleteProfile() string {
-	msg := fmt.Sprintf("Deleting profile %s will also delete all the aliases and envs attached to the profile. Are you sure?", p.currentProfile.Name)
-	paddingTotal := defaultActionsWidth - len(msg)
+	var msg string
+	paddingTotal := 2
+	if p.currentGroup != "" {
+		msg = p.selectionSummary(fmt.Sprintf("Deleting group %s deletes its %d profiles and all the details attached to them. Are you sure?", p.currentGroup, len(p.selected)))
+	} else if len(p.selected) > 0 {
+		msg = p.selectionSummary(fmt.Sprintf("Deleting these %d profiles will also delete all the details attached to them. Are you sure?", len(p.selected)))
+	} else {
+		msg = fmt.Sprintf("Deleting profile %s will also delete all the aliases and envs attached to the profile. Are you sure?", p.currentProfile.Name)
+		paddingTotal = defaultActionsWidth - len(msg)
+	}
 	var infoStyle, deleteButton, cancelButton lipgloss.Style
 	infoStyle = p.issuesStyle.Copy().BorderForeground(red).PaddingLeft(paddingTotal / 2).PaddingRight(paddingTotal / 2)
 	switch p.currentStage {
@@ -1089,6 +2283,84 @@ func (p *ProfilePage) viewDeleteProfile() string {
 	)
 }
 
+func (p *ProfilePage) viewExportProfile() string {
+	msg := p.selectionSummary(fmt.Sprintf("Export these %d profiles to %s? Secret values are left out.", len(p.selected), p.exportPath()))
+	if p.currentGroup != "" {
+		msg = p.selectionSummary(fmt.Sprintf("Export the %d profiles of group %s to %s? Secret values are left out.", len(p.selected), p.currentGroup, p.exportPath()))
+	}
+	confirmButton, cancelButton := p.highlightedButton, p.mutedButton
+	switch p.currentStage {
+	case exportProfileConfirm:
+		confirmButton = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+	case exportProfileCancel:
+		cancelButton = p.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(blue)
+	}
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			p.titleStyle.Render(p.generateTitle()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				p.profilesStyle.Render(p.profileList.View()),
+				lipgloss.JoinVertical(
+					lipgloss.Center,
+					p.actionsStyle.Render(msg),
+					lipgloss.JoinHorizontal(
+						lipgloss.Center,
+						confirmButton.Render("Export"),
+						cancelButton.Render("Cancel"),
+					),
+				),
+			),
+			p.helpMenu.View(p.keys),
+		),
+	)
+}
+
+func (p *ProfilePage) viewCompareProfile() string {
+	heading := fmt.Sprintf("Compare %s with:", p.currentProfile.Name)
+	content := p.compareList.View()
+	if p.currentStage == compareProfileView && p.comparison != nil {
+		heading = fmt.Sprintf("%s vs %s. <enter> to pick another profile", p.comparison.A, p.comparison.B)
+		content = "No differences in envs and aliases"
+		if p.comparison.Drifted() {
+			content = compare.Format(*p.comparison, p.actionsStyle.GetWidth()-2)
+		}
+		content = strings.TrimRight(content, "\n")
+	}
+	sections := []string{p.titleStyle.Render(p.generateTitle())}
+	if p.infoFlag {
+		infoStyle := p.issuesStyle.Copy().BorderForeground(green)
+		if p.isErrInfo {
+			infoStyle = p.issuesStyle.Copy().BorderForeground(red)
+		}
+		sections = append(sections, infoStyle.Render(p.infoMsg))
+	}
+	sections = append(sections,
+		lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			p.profilesStyle.Render(p.profileList.View()),
+			lipgloss.JoinVertical(
+				lipgloss.Left,
+				p.headingStyle.Render(heading),
+				p.actionsStyle.Render(content),
+			),
+		),
+		p.helpMenu.View(p.keys),
+	)
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, sections...),
+	)
+}
+
 func (p *ProfilePage) View() string {
 	switch p.currentUserFlow {
 	case listProfiles:
@@ -1099,6 +2371,16 @@ func (p *ProfilePage) View() string {
 		return p.viewUpdateProfile()
 	case deleteProfile:
 		return p.viewDeleteProfile()
+	case compareProfile:
+		return p.viewCompareProfile()
+	case cloneProfile:
+		return p.viewCloneProfile()
+	case exportProfile:
+		return p.viewExportProfile()
+	case describeProfile:
+		return p.viewDescribeProfile()
+	case tagProfile:
+		return p.viewTagProfile()
 	default:
 		// this should never get invoked. just adding here till debugging is done
 		return "profile page.."
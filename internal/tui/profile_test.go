@@ -3,14 +3,18 @@ package tui
 import (
 	"database/sql"
 	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/bento01dev/maggi/internal/data"
 	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type profileModelStub struct {
@@ -18,6 +22,31 @@ type profileModelStub struct {
 	add           func(name string) (data.Profile, error)
 	update        func(profile data.Profile, newName string) (data.Profile, error)
 	deleteProfile func(profile data.Profile) error
+	clone         func(src data.Profile, newName string) (data.Profile, error)
+	setTags       func(profile data.Profile, tags []string) (data.Profile, error)
+	deleteAll     func(profiles []data.Profile) error
+	replay        func(redo bool) (data.Operation, error)
+	details       map[string][]data.Detail
+	settings      map[string]string
+}
+
+func (ps profileModelStub) GetSetting(key string) (string, error) {
+	return ps.settings[key], nil
+}
+
+func (ps profileModelStub) SetSetting(key, value string) error {
+	if ps.settings != nil {
+		ps.settings[key] = value
+	}
+	return nil
+}
+
+func (ps profileModelStub) Undo() (data.Operation, error) {
+	return ps.replay(false)
+}
+
+func (ps profileModelStub) Redo() (data.Operation, error) {
+	return ps.replay(true)
 }
 
 func (ps profileModelStub) GetAllProfiles() ([]data.Profile, error) {
@@ -36,6 +65,26 @@ func (ps profileModelStub) DeleteProfile(profile data.Profile) error {
 	return ps.deleteProfile(profile)
 }
 
+func (ps profileModelStub) DeleteProfiles(profiles []data.Profile) error {
+	return ps.deleteAll(profiles)
+}
+
+func (ps profileModelStub) GetDetailsByProfileName(name string) ([]data.Detail, error) {
+	return ps.details[name], nil
+}
+
+func (ps profileModelStub) CloneProfile(src data.Profile, newName string) (data.Profile, error) {
+	return ps.clone(src, newName)
+}
+
+func (ps profileModelStub) SetProfileTags(profile data.Profile, tags []string) (data.Profile, error) {
+	return ps.setTags(profile, tags)
+}
+
+func (ps profileModelStub) RevealValue(detail data.Detail) (string, error) {
+	return detail.Value, nil
+}
+
 type profileDetailModelStub struct {
 	deleteAll func(tx *sql.Tx, profileID int) error
 }
@@ -1000,3 +1049,336 @@ func TestHandleDeleteProfileEnter(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleCompareProfile(t *testing.T) {
+	profiles := []data.Profile{{ID: 1, Name: "staging"}, {ID: 2, Name: "prod"}}
+	profilePage := NewProfilePage(profileModelStub{
+		getAll: func() ([]data.Profile, error) { return profiles, nil },
+		details: map[string][]data.Detail{
+			"staging": {
+				{Key: "CLUSTER", Value: "staging", DetailType: data.EnvDetail},
+				{Key: "DEBUG", Value: "1", DetailType: data.EnvDetail},
+			},
+			"prod": {
+				{Key: "CLUSTER", Value: "prod", DetailType: data.EnvDetail},
+			},
+		},
+	})
+	profilePage.profiles = profiles
+	profilePage.setProfileList()
+	// sorted by name, so staging comes after prod, below adding a profile
+	profilePage.profileList.Select(2)
+	profilePage.actionList = GenerateList([]list.Item{actionItem{description: "compare", next: compareProfile}}, renderActionItem, 30, 5, false)
+	profilePage.activePane = actionsPane
+
+	profilePage.handleListProfilesEnter()
+	assert.Equal(t, compareProfile, profilePage.currentUserFlow)
+	assert.Equal(t, compareProfileChoose, profilePage.currentStage)
+	assert.Equal(t, []list.Item{profileItem{id: 2, name: "prod"}}, profilePage.compareList.Items())
+
+	cmd := profilePage.handleEnter()
+	profilePage.Update(cmd())
+	assert.Equal(t, compareProfileView, profilePage.currentStage)
+	assert.Equal(t, "DEBUG", profilePage.comparison.OnlyInA[0].Key)
+	assert.Equal(t, "CLUSTER", profilePage.comparison.Differs[0].Key)
+	assert.True(t, strings.Contains(profilePage.View(), "staging vs prod"))
+
+	profilePage.handleEnter()
+	assert.Equal(t, compareProfileChoose, profilePage.currentStage)
+	assert.Nil(t, profilePage.comparison)
+
+	profilePage.handleEsc()
+	assert.Equal(t, listProfiles, profilePage.currentUserFlow)
+}
+
+func TestHandleCompareProfileWithoutOthers(t *testing.T) {
+	profilePage := NewProfilePage(profileModelStub{})
+	profilePage.profiles = []data.Profile{{ID: 1, Name: "only"}}
+	profilePage.setProfileList()
+	profilePage.actionList = GenerateList([]list.Item{actionItem{description: "compare", next: compareProfile}}, renderActionItem, 30, 5, false)
+	profilePage.activePane = actionsPane
+
+	profilePage.handleListProfilesEnter()
+	assert.True(t, profilePage.isErrInfo)
+	assert.Nil(t, profilePage.handleEnter())
+}
+
+func TestHandleCloneProfileEnter(t *testing.T) {
+	testcases := []struct {
+		name           string
+		currentStage   profileStage
+		newStage       profileStage
+		userFlow       profileUserFlow
+		newPane        profilePagePane
+		profiles       []data.Profile
+		infoFlag       bool
+		isErrInfo      bool
+		textInputValue string
+		cloned         string
+	}{
+		{
+			name:           "switch stage to confirm if clone name is set",
+			currentStage:   cloneProfileName,
+			newStage:       cloneProfileConfirm,
+			textInputValue: "prod-eu",
+		},
+		{
+			name:         "show error message when clone name is empty",
+			currentStage: cloneProfileName,
+			newStage:     cloneProfileName,
+			infoFlag:     true,
+			isErrInfo:    true,
+		},
+		{
+			name:           "clone should fail if name is duplicate",
+			currentStage:   cloneProfileConfirm,
+			newStage:       cloneProfileName,
+			infoFlag:       true,
+			isErrInfo:      true,
+			textInputValue: "prod-us",
+			profiles:       []data.Profile{{ID: 1, Name: "prod-us"}},
+		},
+		{
+			name:           "clone should go back to the list once name checks pass",
+			currentStage:   cloneProfileConfirm,
+			newStage:       chooseAction,
+			userFlow:       listProfiles,
+			newPane:        profilesPane,
+			textInputValue: "prod-eu",
+			profiles:       []data.Profile{{ID: 1, Name: "prod-us"}},
+			cloned:         "prod-eu",
+		},
+		{
+			name:         "cancel should reset everything as needed",
+			currentStage: cloneProfileCancel,
+			newStage:     chooseAction,
+			userFlow:     listProfiles,
+			newPane:      profilesPane,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			var cloned string
+			profilePage := NewProfilePage(profileModelStub{clone: func(src data.Profile, newName string) (data.Profile, error) {
+				cloned = newName
+				return data.Profile{ID: 2, Name: newName}, nil
+			}})
+			profilePage.currentUserFlow = cloneProfile
+			profilePage.activePane = actionsPane
+			profilePage.currentStage = testcase.currentStage
+			profilePage.textInput.SetValue(testcase.textInputValue)
+			profilePage.profiles = testcase.profiles
+			profilePage.currentProfile = &data.Profile{ID: 1, Name: "prod-us"}
+			cmd := profilePage.handleCloneProfileEnter()
+			if testcase.cloned != "" {
+				assert.Equal(t, profileAddMsg{success: true}, cmd())
+			}
+
+			assert.Equal(t, testcase.cloned, cloned)
+			assert.Equal(t, testcase.newStage, profilePage.currentStage)
+			assert.Equal(t, testcase.infoFlag, profilePage.infoFlag)
+			assert.Equal(t, testcase.isErrInfo, profilePage.isErrInfo)
+			if testcase.userFlow == listProfiles {
+				assert.Equal(t, listProfiles, profilePage.currentUserFlow)
+				assert.Equal(t, testcase.newPane, profilePage.activePane)
+			}
+		})
+	}
+}
+
+func TestHandleDescribeProfileEnter(t *testing.T) {
+	var updated data.Profile
+	profilePage := NewProfilePage(profileModelStub{update: func(profile data.Profile, newName string) (data.Profile, error) {
+		updated = profile
+		return profile, nil
+	}})
+	profilePage.currentUserFlow = describeProfile
+	profilePage.activePane = actionsPane
+	profilePage.currentStage = describeProfileConfirm
+	profilePage.currentProfile = &data.Profile{ID: 1, Name: "prod", Description: "production"}
+
+	profilePage.descriptionInput.SetValue("production")
+	profilePage.handleDescribeProfileEnter()
+	assert.Equal(t, describeProfileText, profilePage.currentStage)
+	assert.True(t, profilePage.isErrInfo)
+
+	profilePage.currentStage = describeProfileConfirm
+	profilePage.descriptionInput.SetValue(" production cluster ")
+	cmd := profilePage.handleDescribeProfileEnter()
+	assert.Equal(t, profileAddMsg{success: true}, cmd())
+	assert.Equal(t, data.Profile{ID: 1, Name: "prod", Description: "production cluster"}, updated)
+	assert.Equal(t, listProfiles, profilePage.currentUserFlow)
+	assert.Equal(t, chooseAction, profilePage.currentStage)
+}
+
+func TestHandleTagProfileEnter(t *testing.T) {
+	var tagged []string
+	profilePage := NewProfilePage(profileModelStub{setTags: func(profile data.Profile, tags []string) (data.Profile, error) {
+		tagged = tags
+		return profile, nil
+	}})
+	profilePage.currentUserFlow = tagProfile
+	profilePage.activePane = actionsPane
+	profilePage.currentStage = tagProfileConfirm
+	profilePage.currentProfile = &data.Profile{ID: 1, Name: "prod", Tags: []string{"aws"}}
+
+	profilePage.tagsInput.SetValue("aws,")
+	profilePage.handleTagProfileEnter()
+	assert.Equal(t, tagProfileText, profilePage.currentStage)
+	assert.True(t, profilePage.isErrInfo)
+
+	profilePage.currentStage = tagProfileConfirm
+	profilePage.tagsInput.SetValue("k8s, aws")
+	cmd := profilePage.handleTagProfileEnter()
+	assert.Equal(t, profileAddMsg{success: true}, cmd())
+	assert.Equal(t, []string{"aws", "k8s"}, tagged)
+	assert.Equal(t, listProfiles, profilePage.currentUserFlow)
+}
+
+func TestProfileTagFilter(t *testing.T) {
+	profiles := []data.Profile{
+		{ID: 1, Name: "eu", Tags: []string{"aws"}},
+		{ID: 2, Name: "home"},
+		{ID: 3, Name: "work", Tags: []string{"aws", "k8s"}},
+	}
+	profilePage := NewProfilePage(profileModelStub{getAll: func() ([]data.Profile, error) { return profiles, nil }})
+	profilePage.Update(profilePage.getProfiles())
+	assert.Len(t, profilePage.profileList.Items(), 4)
+	assert.Equal(t, "work #aws #k8s", renderProfileItem(profilePage.profileList.Items()[3]))
+
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	assert.Equal(t, "aws", profilePage.tagFilter)
+	assert.Equal(t, "Showing profiles tagged aws", profilePage.infoMsg)
+	items := profilePage.profileList.Items()
+	require.Len(t, items, 3)
+	assert.True(t, items[0].(profileItem).action)
+	assert.Equal(t, "eu", items[1].(profileItem).name)
+	assert.Equal(t, "work", items[2].(profileItem).name)
+
+	// select all only marks the listed profiles
+	profilePage.handleSelectAll()
+	assert.Equal(t, map[int]bool{1: true, 3: true}, profilePage.selected)
+	profilePage.selected = nil
+
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	assert.Equal(t, "k8s", profilePage.tagFilter)
+	assert.Len(t, profilePage.profileList.Items(), 2)
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	assert.Equal(t, "", profilePage.tagFilter)
+	assert.Len(t, profilePage.profileList.Items(), 4)
+
+	// the filter is dropped once no profile has the tag
+	profilePage.tagFilter = "k8s"
+	profiles[2].Tags = []string{"aws"}
+	profilePage.Update(profileAddMsg{success: true})
+	assert.Equal(t, "", profilePage.tagFilter)
+	assert.Len(t, profilePage.profileList.Items(), 4)
+}
+
+func TestProfileBulkActions(t *testing.T) {
+	profiles := []data.Profile{{ID: 1, Name: "work"}, {ID: 2, Name: "home"}, {ID: 3, Name: "scratch"}}
+	var deleted []data.Profile
+	profilePage := NewProfilePage(profileModelStub{
+		getAll: func() ([]data.Profile, error) { return profiles, nil },
+		deleteAll: func(profiles []data.Profile) error {
+			deleted = profiles
+			return nil
+		},
+		details: map[string][]data.Detail{
+			"work": {{Key: "k", Value: "kubectl", DetailType: data.AliasDetail}},
+			"home": {{Key: "TOKEN", Value: "abc", DetailType: data.EnvDetail, Secret: true}},
+		},
+	})
+	profilePage.exportDir = t.TempDir()
+	profilePage.Update(retrieveMsg{profiles: profiles})
+
+	space := tea.KeyMsg{Type: tea.KeySpace}
+	selectAll := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}
+	profilePage.Update(selectAll)
+	assert.Len(t, profilePage.selected, 3)
+	profilePage.Update(selectAll)
+	assert.Empty(t, profilePage.selected)
+
+	// the list is sorted by name below the add item: home, scratch, work
+	profilePage.Update(space)
+	profilePage.profileList.Select(3)
+	profilePage.Update(space)
+	// the add item cannot be selected
+	profilePage.profileList.Select(0)
+	profilePage.Update(space)
+	assert.Equal(t, map[int]bool{1: true, 2: true}, profilePage.selected)
+	assert.Equal(t, "* home", renderProfileItem(profilePage.profileList.Items()[1]))
+	assert.Equal(t, "Delete Selected", renderActionItem(profilePage.actionList.Items()[0]))
+
+	// export the selection
+	profilePage.activePane = actionsPane
+	profilePage.actionList.Select(1)
+	profilePage.handleEnter()
+	assert.Equal(t, exportProfile, profilePage.currentUserFlow)
+	assert.Contains(t, profilePage.View(), "scratch")
+	msg := profilePage.handleEnter()()
+	assert.Equal(t, profilesExportedMsg{path: filepath.Join(profilePage.exportDir, "maggi-profiles.json"), count: 2}, msg)
+	exported, err := os.ReadFile(msg.(profilesExportedMsg).path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(exported), `"name": "home"`)
+	assert.NotContains(t, string(exported), "abc")
+	profilePage.Update(msg)
+	assert.Equal(t, listProfiles, profilePage.currentUserFlow)
+	assert.Empty(t, profilePage.selected)
+
+	// delete a selection in one go
+	profilePage.profileList.Select(3)
+	profilePage.Update(space)
+	profilePage.profileList.Select(2)
+	profilePage.Update(space)
+	profilePage.activePane = actionsPane
+	profilePage.actionList.Select(0)
+	profilePage.handleEnter()
+	assert.Equal(t, deleteProfile, profilePage.currentUserFlow)
+	assert.Contains(t, profilePage.generateTitle(), "Delete Selected | 2 profiles")
+	profilePage.currentStage = deleteProfileConfirm
+	assert.Equal(t, profileDeleteMsg{}, profilePage.handleEnter()())
+	assert.Equal(t, []data.Profile{{ID: 1, Name: "work"}, {ID: 3, Name: "scratch"}}, deleted)
+}
+
+func TestProfileUndo(t *testing.T) {
+	profiles := []data.Profile{{ID: 1, Name: "work"}}
+	var replayed []bool
+	profilePage := NewProfilePage(profileModelStub{
+		getAll: func() ([]data.Profile, error) { return profiles, nil },
+		replay: func(redo bool) (data.Operation, error) {
+			replayed = append(replayed, redo)
+			if redo {
+				return data.Operation{Label: "add profile home"}, nil
+			}
+			return data.Operation{}, errors.New("replaying \"add profile home\": constraint failed")
+		},
+	})
+	profilePage.Update(retrieveMsg{profiles: profiles})
+
+	profiles = append(profiles, data.Profile{ID: 2, Name: "home"})
+	_, cmd := profilePage.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	_, cmd = profilePage.Update(cmd())
+	assert.Nil(t, cmd)
+	assert.Equal(t, "Redid add profile home", profilePage.infoMsg)
+	assert.Equal(t, profiles, profilePage.profiles)
+	assert.Len(t, profilePage.profileList.Items(), 3)
+
+	// a failed replay is an issue rather than a note
+	_, cmd = profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	_, cmd = profilePage.Update(cmd())
+	issue, ok := cmd().(IssueMsg)
+	assert.True(t, ok)
+	assert.ErrorContains(t, issue.Inner, "constraint failed")
+	assert.Equal(t, []bool{true, false}, replayed)
+
+	_, cmd = profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	assert.Equal(t, GenericTurner(trashBin), cmd())
+
+	// u is only an undo in the profile list
+	profilePage.activePane = actionsPane
+	profilePage.currentUserFlow = newProfile
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	assert.Equal(t, []bool{true, false}, replayed)
+}
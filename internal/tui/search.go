@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/search"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultSearchWidth int = 70
+
+// SearchStartMsg opens the search. from is the page esc goes back to.
+type SearchStartMsg struct {
+	from pageType
+}
+
+type searchLoadedMsg struct {
+	matches []search.Match
+	err     error
+}
+
+// SearchDoneMsg opens the profile of the chosen match on the detail page, at the
+// matching detail
+type SearchDoneMsg struct {
+	profile  data.Profile
+	detailID int
+}
+
+func (s SearchDoneMsg) Next() pageType {
+	return detail
+}
+
+type searchItem struct {
+	match search.Match
+	query string
+}
+
+func (s searchItem) FilterValue() string {
+	return search.Label(s.match)
+}
+
+// highlights are the runes of the label where the key and value contain the query
+func (s searchItem) highlights() []int {
+	query := strings.ToLower(strings.TrimSpace(s.query))
+	if query == "" {
+		return nil
+	}
+	detail := s.match.Detail
+	label := search.Label(s.match)
+	keyStart := len(s.match.Profile.Name + " › " + string(detail.DetailType) + " › ")
+	var runes []int
+	highlight := func(start int, text string) {
+		i := strings.Index(strings.ToLower(text), query)
+		// lower casing can change the length of some runes, those are not highlighted
+		if i < 0 || len(strings.ToLower(text)) != len(text) {
+			return
+		}
+		first := utf8.RuneCountInString(label[:start+i])
+		for n := range utf8.RuneCountInString(query) {
+			runes = append(runes, first+n)
+		}
+	}
+	highlight(keyStart, detail.Key)
+	if !detail.Secret {
+		highlight(keyStart+len(detail.Key+" = "), search.Value(detail))
+	}
+	return runes
+}
+
+func renderSearchItem(item list.Item) string {
+	s, ok := item.(searchItem)
+	if !ok {
+		return ""
+	}
+	return search.Label(s.match)
+}
+
+type searchHelpKeys struct {
+	Up   key.Binding
+	Down key.Binding
+	Open key.Binding
+	Esc  key.Binding
+	Quit key.Binding
+}
+
+func (h searchHelpKeys) ShortHelp() []key.Binding {
+	return []key.Binding{h.Up, h.Down, h.Open, h.Esc, h.Quit}
+}
+
+func (h searchHelpKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{h.Up, h.Down, h.Open},
+		{h.Esc, h.Quit},
+	}
+}
+
+// SearchPage looks for keys and values across every profile. typing narrows down the
+// matches and enter opens the chosen one on the detail page.
+type SearchPage struct {
+	width      int
+	height     int
+	from       pageType
+	repository search.SearchRepository
+	// matches holds every detail, the list shows the ones found for the query
+	matches     []search.Match
+	queryInput  textinput.Model
+	resultList  list.Model
+	resultStyle lipgloss.Style
+	titleStyle  lipgloss.Style
+	helpMenu    help.Model
+	keys        searchHelpKeys
+}
+
+func NewSearchPage(repository search.SearchRepository) *SearchPage {
+	helpMenu := help.New()
+	keyStyle := lipgloss.NewStyle().Foreground(muted)
+	descStyle := lipgloss.NewStyle().Foreground(muted)
+	sepStyle := lipgloss.NewStyle().Foreground(muted)
+	helpMenu.Styles = help.Styles{
+		ShortKey:       keyStyle,
+		ShortDesc:      descStyle,
+		ShortSeparator: sepStyle,
+		Ellipsis:       sepStyle.Copy(),
+		FullKey:        keyStyle.Copy(),
+		FullDesc:       descStyle.Copy(),
+		FullSeparator:  sepStyle.Copy(),
+	}
+	keys := searchHelpKeys{
+		Up: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑", "move up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("↓", "move down"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("<enter>"),
+			key.WithHelp("<enter>", "open in profile"),
+		),
+		Esc: key.NewBinding(
+			key.WithKeys("<esc>"),
+			key.WithHelp("<esc>", "go back"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("<ctrl+c>"),
+			key.WithHelp("<ctrl+c>", "quit"),
+		),
+	}
+	queryInput := textinput.New()
+	queryInput.Placeholder = "key or value, e.g. JAVA_HOME"
+	queryInput.CharLimit = 100
+	queryInput.Width = defaultSearchWidth - 2
+	queryInput.Prompt = "> "
+	queryInput.PromptStyle = lipgloss.NewStyle().Foreground(blue)
+	queryInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(muted)
+	queryInput.TextStyle = lipgloss.NewStyle().Foreground(blue)
+	return &SearchPage{
+		repository:  repository,
+		helpMenu:    helpMenu,
+		keys:        keys,
+		queryInput:  queryInput,
+		resultList:  GenerateList(nil, renderSearchItem, defaultSearchWidth, defaultHeight, false),
+		resultStyle: lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).BorderForeground(green).Width(defaultSearchWidth).UnsetPadding(),
+		titleStyle:  lipgloss.NewStyle().Foreground(green),
+	}
+}
+
+func (s *SearchPage) Init() tea.Cmd {
+	return nil
+}
+
+func (s *SearchPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SearchStartMsg:
+		s.from = msg.from
+		s.matches = nil
+		s.queryInput.SetValue("")
+		s.setResultList()
+		return s, tea.Batch(s.loadMatches, s.queryInput.Focus())
+	case searchLoadedMsg:
+		if msg.err != nil {
+			return s, func() tea.Msg {
+				return IssueMsg{Inner: msg.err}
+			}
+		}
+		s.matches = msg.matches
+		s.setResultList()
+		return s, nil
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEsc:
+			return s, func() tea.Msg {
+				return GenericTurner(s.from)
+			}
+		case tea.KeyEnter:
+			return s, s.handleEnter()
+		case tea.KeyUp, tea.KeyDown:
+			var cmd tea.Cmd
+			s.resultList, cmd = s.resultList.Update(msg)
+			return s, cmd
+		}
+	}
+	query := s.queryInput.Value()
+	var cmd tea.Cmd
+	s.queryInput, cmd = s.queryInput.Update(msg)
+	if s.queryInput.Value() != query {
+		s.setResultList()
+	}
+	return s, cmd
+}
+
+func (s *SearchPage) loadMatches() tea.Msg {
+	matches, err := search.Load(s.repository)
+	return searchLoadedMsg{matches: matches, err: err}
+}
+
+func (s *SearchPage) setResultList() {
+	query := s.queryInput.Value()
+	var items []list.Item
+	for _, match := range search.Find(s.matches, query) {
+		items = append(items, searchItem{match: match, query: query})
+	}
+	s.resultList = GenerateList(items, renderSearchItem, defaultSearchWidth, max(len(items), defaultHeight), false)
+}
+
+func (s *SearchPage) handleEnter() tea.Cmd {
+	item, ok := s.resultList.SelectedItem().(searchItem)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		return SearchDoneMsg{profile: item.match.Profile, detailID: item.match.Detail.ID}
+	}
+}
+
+func (s *SearchPage) View() string {
+	results := s.resultList.View()
+	switch {
+	case len(s.matches) == 0:
+		results = "No details to search yet"
+	case len(s.resultList.Items()) == 0:
+		results = "No matches"
+	}
+	return lipgloss.Place(
+		s.width,
+		s.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			s.titleStyle.Render("Search All Profiles"),
+			s.resultStyle.Render(lipgloss.JoinVertical(lipgloss.Left, s.queryInput.View(), results)),
+			s.helpMenu.View(s.keys),
+		),
+	)
+}
+
+func (s *SearchPage) UpdateSize(width, height int) {
+	s.width = width
+	s.height = height
+}
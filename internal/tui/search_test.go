@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/bento01dev/maggi/internal/data"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchStub() detailModelStub {
+	details := map[int][]data.Detail{
+		1: {
+			{ID: 11, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, ProfileID: 1},
+			{ID: 12, Key: "ll", Value: "ls -l", DetailType: data.AliasDetail, ProfileID: 1},
+		},
+		2: {
+			{ID: 21, Key: "JAVA_HOME", Value: "/usr/lib/jvm/java-21", DetailType: data.EnvDetail, ProfileID: 2},
+			{ID: 22, Key: "j", Value: "java -jar", DetailType: data.AliasDetail, ProfileID: 2},
+		},
+	}
+	return detailModelStub{
+		profiles: []data.Profile{{ID: 1, Name: "home"}, {ID: 2, Name: "work"}},
+		getAll: func(profileID int) ([]data.Detail, error) {
+			return details[profileID], nil
+		},
+		settings: map[string]string{},
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	searchPage := NewSearchPage(searchStub())
+	_, cmd := searchPage.Update(SearchStartMsg{from: trashBin})
+	searchPage.Update(searchPage.loadMatches())
+	assert.Len(t, searchPage.resultList.Items(), 4)
+	require.NotNil(t, cmd)
+
+	for _, r := range "java" {
+		searchPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	items := searchPage.resultList.Items()
+	require.Len(t, items, 2)
+	assert.Equal(t, "work › alias › j = java -jar", renderSearchItem(items[0]))
+	assert.Equal(t, "work › env › JAVA_HOME = /usr/lib/jvm/java-21", renderSearchItem(items[1]))
+	// the key and the value are highlighted where they match
+	assert.Equal(t, []int{19, 20, 21, 22}, items[0].(searchItem).highlights())
+	assert.Equal(t, []int{13, 14, 15, 16, 38, 39, 40, 41}, items[1].(searchItem).highlights())
+
+	searchPage.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = searchPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg := cmd()
+	assert.Equal(t, SearchDoneMsg{profile: data.Profile{ID: 2, Name: "work"}, detailID: 21}, msg)
+	assert.Equal(t, detail, msg.(SearchDoneMsg).Next())
+
+	for _, r := range "zzz" {
+		searchPage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	assert.Contains(t, searchPage.View(), "No matches")
+	_, cmd = searchPage.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, GenericTurner(trashBin), cmd())
+}
+
+func TestDetailPageFocus(t *testing.T) {
+	detailPage := NewDetailPage(searchStub())
+	_, cmd := detailPage.Update(DetailStartMsg{currentProfile: data.Profile{ID: 2, Name: "work"}, focusID: 22})
+	detailPage.Update(cmd())
+	assert.Equal(t, aliasPane, detailPage.activePane)
+	assert.Equal(t, detailTypeAlias, detailPage.detailType)
+	assert.False(t, detailPage.emptyDisplay)
+	require.NotNil(t, detailPage.currentDetail)
+	assert.Equal(t, "j", detailPage.currentDetail.Key)
+	assert.Equal(t, "Update Alias", renderDetailActionItem(detailPage.actionsList.Items()[0]))
+
+	// the focus is only for the first load
+	detailPage.Update(cmd())
+	assert.Equal(t, envPane, detailPage.activePane)
+}
+
+func TestSearchFromAnyPage(t *testing.T) {
+	model := NewMaggiModel(false, nil)
+	model.pages[globalSearch] = NewSearchPage(searchStub())
+	model.currentPage = trashBin
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	assert.Equal(t, globalSearch, model.currentPage)
+	assert.Equal(t, SearchStartMsg{from: trashBin}, cmd())
+
+	model.handlePageTransition(SearchDoneMsg{profile: data.Profile{ID: 2, Name: "work"}, detailID: 21})
+	assert.Equal(t, DetailStartMsg{currentProfile: data.Profile{ID: 2, Name: "work"}, focusID: 21}, model.pageInitCmd()())
+	model.handlePageTransition(GenericTurner(detail))
+	assert.Equal(t, DetailStartMsg{currentProfile: data.Profile{ID: 2, Name: "work"}}, model.pageInitCmd()())
+}
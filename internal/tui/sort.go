@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+)
+
+// sortMode orders the profile list and the detail lists. the chosen mode is kept in
+// the settings, one for the profiles and one for the details.
+type sortMode string
+
+const (
+	sortByName   sortMode = "name"
+	sortByEdited sortMode = "edited"
+	sortByUsed   sortMode = "used"
+)
+
+const (
+	profileSortSetting = "profiles.sort"
+	detailSortSetting  = "details.sort"
+)
+
+// details are only applied along with their profile, so their lists can't be sorted
+// by use
+var (
+	profileSortModes = []sortMode{sortByName, sortByEdited, sortByUsed}
+	detailSortModes  = []sortMode{sortByName, sortByEdited}
+)
+
+type settingsRepository interface {
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
+}
+
+func (s sortMode) label() string {
+	switch s {
+	case sortByEdited:
+		return "recently edited"
+	case sortByUsed:
+		return "recently used"
+	default:
+		return "name"
+	}
+}
+
+// next is the mode after s in modes, wrapping around
+func (s sortMode) next(modes []sortMode) sortMode {
+	i := slices.Index(modes, s)
+	return modes[(i+1)%len(modes)]
+}
+
+// loadSortMode reads the stored mode. anything unknown, including nothing stored yet,
+// sorts by name.
+func loadSortMode(repository settingsRepository, setting string, modes []sortMode) (sortMode, error) {
+	value, err := repository.GetSetting(setting)
+	if err != nil {
+		return sortByName, err
+	}
+	if !slices.Contains(modes, sortMode(value)) {
+		return sortByName, nil
+	}
+	return sortMode(value), nil
+}
+
+func sortProfiles(profiles []data.Profile, mode sortMode) {
+	slices.SortStableFunc(profiles, func(a, b data.Profile) int {
+		switch mode {
+		case sortByEdited:
+			if c := compareRecent(a.UpdatedAt, b.UpdatedAt); c != 0 {
+				return c
+			}
+		case sortByUsed:
+			if c := compareRecent(a.LastAppliedAt, b.LastAppliedAt); c != 0 {
+				return c
+			}
+		}
+		return compareNames(a.Name, b.Name)
+	})
+}
+
+func sortDetails(details []data.Detail, mode sortMode) {
+	slices.SortStableFunc(details, func(a, b data.Detail) int {
+		if mode == sortByEdited {
+			if c := compareRecent(a.UpdatedAt, b.UpdatedAt); c != 0 {
+				return c
+			}
+		}
+		return compareNames(a.Key, b.Key)
+	})
+}
+
+// compareRecent puts the later time first. unknown times go last
+func compareRecent(a, b time.Time) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	}
+	return b.Compare(a)
+}
+
+func compareNames(a, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
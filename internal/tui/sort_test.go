@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortProfiles(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	profiles := []data.Profile{
+		{ID: 1, Name: "work", UpdatedAt: at, LastAppliedAt: at.Add(time.Hour)},
+		{ID: 2, Name: "Home", UpdatedAt: at.Add(time.Hour)},
+		{ID: 3, Name: "scratch", UpdatedAt: at, LastAppliedAt: at},
+		{ID: 4, Name: "legacy"},
+	}
+	ids := func() []int {
+		var ids []int
+		for _, profile := range profiles {
+			ids = append(ids, profile.ID)
+		}
+		return ids
+	}
+	sortProfiles(profiles, sortByName)
+	assert.Equal(t, []int{2, 4, 3, 1}, ids())
+	// unknown times go last, ties by name
+	sortProfiles(profiles, sortByEdited)
+	assert.Equal(t, []int{2, 3, 1, 4}, ids())
+	sortProfiles(profiles, sortByUsed)
+	assert.Equal(t, []int{1, 3, 2, 4}, ids())
+}
+
+func TestSortModeNext(t *testing.T) {
+	assert.Equal(t, sortByEdited, sortByName.next(profileSortModes))
+	assert.Equal(t, sortByName, sortByUsed.next(profileSortModes))
+	assert.Equal(t, sortByName, sortByEdited.next(detailSortModes))
+}
+
+func TestProfileSortKey(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	profiles := []data.Profile{
+		{ID: 1, Name: "alpha", UpdatedAt: at},
+		{ID: 2, Name: "beta", UpdatedAt: at.Add(time.Hour)},
+	}
+	settings := map[string]string{profileSortSetting: "bogus"}
+	profilePage := NewProfilePage(profileModelStub{
+		getAll:   func() ([]data.Profile, error) { return profiles, nil },
+		settings: settings,
+	})
+	profilePage.Update(profilePage.getProfiles())
+	assert.Equal(t, sortByName, profilePage.sort)
+	assert.Equal(t, "alpha", renderProfileItem(profilePage.profileList.Items()[1]))
+
+	// the cursor follows the profile it was on
+	profilePage.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	assert.Equal(t, "edited", settings[profileSortSetting])
+	assert.Equal(t, "beta", renderProfileItem(profilePage.profileList.Items()[1]))
+	assert.Equal(t, 2, profilePage.profileList.Index())
+	assert.Equal(t, "Profiles sorted by recently edited", profilePage.infoMsg)
+
+	// the stored sort is used on the next start
+	profilePage = NewProfilePage(profileModelStub{
+		getAll:   func() ([]data.Profile, error) { return profiles, nil },
+		settings: settings,
+	})
+	profilePage.Update(profilePage.getProfiles())
+	assert.Equal(t, sortByEdited, profilePage.sort)
+	assert.Equal(t, "beta", renderProfileItem(profilePage.profileList.Items()[1]))
+}
+
+func TestDetailSortKey(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	details := []data.Detail{
+		{ID: 1, Key: "EDITOR", Value: "vim", DetailType: data.EnvDetail, UpdatedAt: at},
+		{ID: 2, Key: "PAGER", Value: "less", DetailType: data.EnvDetail, UpdatedAt: at.Add(time.Hour)},
+	}
+	settings := map[string]string{}
+	detailPage := NewDetailPage(detailModelStub{
+		getAll:   func(profileID int) ([]data.Detail, error) { return details, nil },
+		settings: settings,
+	})
+	detailPage.Update(detailPage.getDetails())
+	assert.Equal(t, "EDITOR", renderDetailItem(detailPage.envList.Items()[1]))
+
+	sortKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}
+	detailPage.Update(sortKey)
+	assert.Equal(t, "edited", settings[detailSortSetting])
+	assert.Equal(t, "PAGER", renderDetailItem(detailPage.envList.Items()[1]))
+	// details can't be sorted by use, so it goes back to name
+	detailPage.Update(sortKey)
+	assert.Equal(t, "name", settings[detailSortSetting])
+	assert.Equal(t, "EDITOR", renderDetailItem(detailPage.envList.Items()[1]))
+}
@@ -0,0 +1,467 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/trash"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultTrashWidth int = 50
+
+type TrashStartMsg struct{}
+
+type trashLoadedMsg struct {
+	trash data.Trash
+	err   error
+}
+
+// trashChangedMsg is the outcome of a restore or purge. err is shown on the page, as
+// a restore can fail on a name taken since the delete.
+type trashChangedMsg struct {
+	info string
+	err  error
+}
+
+type trashPagePane int
+
+const (
+	trashEntriesPane trashPagePane = iota
+	trashActionsPane
+)
+
+type trashStage int
+
+const (
+	trashChooseAction trashStage = iota
+	trashPurgeConfirm
+	trashPurgeCancel
+)
+
+type trashAction int
+
+const (
+	restoreTrashEntry trashAction = iota
+	purgeTrashEntry
+	emptyTrash
+)
+
+type trashEntry struct {
+	profile *data.TrashedProfile
+	detail  *data.TrashedDetail
+}
+
+func (t trashEntry) FilterValue() string {
+	return t.label()
+}
+
+func (t trashEntry) label() string {
+	if t.profile != nil {
+		return "profile " + trash.ProfileLabel(*t.profile)
+	}
+	return trash.DetailLabel(*t.detail)
+}
+
+func (t trashEntry) deletedAt() time.Time {
+	if t.profile != nil {
+		return t.profile.DeletedAt
+	}
+	return t.detail.DeletedAt
+}
+
+func renderTrashEntry(item list.Item) string {
+	t, ok := item.(trashEntry)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s · %s", t.label(), t.deletedAt().Format("Jan 2 15:04"))
+}
+
+type trashActionItem struct {
+	description string
+	action      trashAction
+}
+
+func (t trashActionItem) FilterValue() string {
+	return t.description
+}
+
+func renderTrashActionItem(item list.Item) string {
+	t, ok := item.(trashActionItem)
+	if !ok {
+		return ""
+	}
+	return t.description
+}
+
+type trashHelpKeys struct {
+	ToggleView key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	SearchAll  key.Binding
+	Esc        key.Binding
+	Quit       key.Binding
+}
+
+func (h trashHelpKeys) ShortHelp() []key.Binding {
+	return []key.Binding{h.ToggleView, h.Up, h.Down, h.Esc, h.Quit}
+}
+
+func (h trashHelpKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{h.ToggleView, h.Up, h.Down},
+		{h.SearchAll, h.Esc, h.Quit},
+	}
+}
+
+type trashPageRepository interface {
+	GetTrash() (data.Trash, error)
+	RestoreProfile(profile data.Profile) error
+	RestoreDetail(detail data.Detail) error
+	PurgeProfile(profile data.Profile) error
+	PurgeDetail(detail data.Detail) error
+	PurgeTrash(before time.Time) (int, error)
+}
+
+type TrashPage struct {
+	width             int
+	height            int
+	infoFlag          bool
+	isErrInfo         bool
+	infoMsg           string
+	activePane        trashPagePane
+	currentStage      trashStage
+	pendingAction     trashAction
+	repository        trashPageRepository
+	trash             data.Trash
+	entryList         list.Model
+	actionList        list.Model
+	entriesStyle      lipgloss.Style
+	actionsStyle      lipgloss.Style
+	titleStyle        lipgloss.Style
+	issuesStyle       lipgloss.Style
+	deleteButton      lipgloss.Style
+	mutedButton       lipgloss.Style
+	highlightedButton lipgloss.Style
+	helpMenu          help.Model
+	keys              trashHelpKeys
+}
+
+func NewTrashPage(repository trashPageRepository) *TrashPage {
+	helpMenu := help.New()
+	keyStyle := lipgloss.NewStyle().Foreground(muted)
+	descStyle := lipgloss.NewStyle().Foreground(muted)
+	sepStyle := lipgloss.NewStyle().Foreground(muted)
+	helpMenu.Styles = help.Styles{
+		ShortKey:       keyStyle,
+		ShortDesc:      descStyle,
+		ShortSeparator: sepStyle,
+		Ellipsis:       sepStyle.Copy(),
+		FullKey:        keyStyle.Copy(),
+		FullDesc:       descStyle.Copy(),
+		FullSeparator:  sepStyle.Copy(),
+	}
+	keys := trashHelpKeys{
+		ToggleView: key.NewBinding(
+			key.WithKeys("<tab>"),
+			key.WithHelp("<tab>", "toggle panes"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑", "move up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("↓", "move down"),
+		),
+		SearchAll: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("<ctrl+f>", "search all profiles"),
+		),
+		Esc: key.NewBinding(
+			key.WithKeys("<esc>"),
+			key.WithHelp("<esc>", "back to profiles"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("<ctrl+c>"),
+			key.WithHelp("<ctrl+c>", "quit"),
+		),
+	}
+	baseButton := lipgloss.NewStyle().Padding(buttonPaddingVertical, buttonPaddingHorizontal).MarginLeft(1).Foreground(lipgloss.Color("0"))
+	return &TrashPage{
+		repository:        repository,
+		helpMenu:          helpMenu,
+		keys:              keys,
+		entriesStyle:      lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).Width(defaultTrashWidth).UnsetPadding(),
+		actionsStyle:      lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).Width(defaultProfileWidth).UnsetPadding(),
+		titleStyle:        lipgloss.NewStyle().Foreground(green),
+		issuesStyle:       lipgloss.NewStyle().BorderStyle(lipgloss.ThickBorder()).UnsetPadding().BorderForeground(red),
+		deleteButton:      baseButton.Copy().Background(red),
+		mutedButton:       baseButton.Copy().Background(muted),
+		highlightedButton: baseButton.Copy().Background(green),
+	}
+}
+
+func (t *TrashPage) Init() tea.Cmd {
+	return nil
+}
+
+func (t *TrashPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TrashStartMsg:
+		t.resetInfoBag()
+		return t, t.loadTrash
+	case trashLoadedMsg:
+		if msg.err != nil {
+			return t, func() tea.Msg {
+				return IssueMsg{Inner: msg.err}
+			}
+		}
+		t.trash = msg.trash
+		t.currentStage = trashChooseAction
+		t.activePane = trashEntriesPane
+		t.setEntryList()
+		t.setActionList()
+		t.updatePaneStyles()
+		return t, nil
+	case trashChangedMsg:
+		t.infoFlag = true
+		t.isErrInfo = msg.err != nil
+		t.infoMsg = msg.info
+		if msg.err != nil {
+			t.infoMsg = capitalize(msg.err.Error())
+		}
+		return t, t.loadTrash
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyTab, tea.KeyShiftTab:
+			t.handleTab()
+			return t, nil
+		case tea.KeyEnter:
+			return t, t.handleEnter()
+		case tea.KeyEsc:
+			if t.currentStage != trashChooseAction {
+				t.currentStage = trashChooseAction
+				return t, nil
+			}
+			return t, func() tea.Msg {
+				return GenericTurner(profile)
+			}
+		}
+	}
+	var cmd tea.Cmd
+	if t.currentStage != trashChooseAction {
+		return t, nil
+	}
+	switch t.activePane {
+	case trashEntriesPane:
+		t.entryList, cmd = t.entryList.Update(msg)
+	case trashActionsPane:
+		t.actionList, cmd = t.actionList.Update(msg)
+	}
+	return t, cmd
+}
+
+func (t *TrashPage) loadTrash() tea.Msg {
+	deleted, err := t.repository.GetTrash()
+	return trashLoadedMsg{trash: deleted, err: err}
+}
+
+func (t *TrashPage) resetInfoBag() {
+	t.infoFlag = false
+	t.isErrInfo = false
+	t.infoMsg = ""
+}
+
+func (t *TrashPage) setEntryList() {
+	var items []list.Item
+	for i := range t.trash.Profiles {
+		items = append(items, trashEntry{profile: &t.trash.Profiles[i]})
+	}
+	for i := range t.trash.Details {
+		items = append(items, trashEntry{detail: &t.trash.Details[i]})
+	}
+	h := max(len(items), defaultHeight)
+	t.entryList = GenerateList(items, renderTrashEntry, defaultTrashWidth, h, false)
+}
+
+func (t *TrashPage) setActionList() {
+	items := []list.Item{
+		trashActionItem{description: "Restore", action: restoreTrashEntry},
+		trashActionItem{description: "Purge", action: purgeTrashEntry},
+		trashActionItem{description: "Empty Trash", action: emptyTrash},
+	}
+	t.actionList = GenerateList(items, renderTrashActionItem, defaultProfileWidth, max(len(t.entryList.Items()), defaultHeight), false)
+}
+
+func (t *TrashPage) updatePaneStyles() {
+	switch t.activePane {
+	case trashEntriesPane:
+		t.entriesStyle = t.entriesStyle.Copy().BorderForeground(green)
+		t.actionsStyle = t.actionsStyle.Copy().BorderForeground(muted)
+	case trashActionsPane:
+		t.entriesStyle = t.entriesStyle.Copy().BorderForeground(muted)
+		t.actionsStyle = t.actionsStyle.Copy().BorderForeground(green)
+	}
+}
+
+func (t *TrashPage) handleTab() {
+	switch t.currentStage {
+	case trashChooseAction:
+		if t.trash.Empty() {
+			return
+		}
+		if t.activePane == trashEntriesPane {
+			t.activePane = trashActionsPane
+		} else {
+			t.activePane = trashEntriesPane
+		}
+		t.updatePaneStyles()
+	case trashPurgeConfirm:
+		t.currentStage = trashPurgeCancel
+	case trashPurgeCancel:
+		t.currentStage = trashPurgeConfirm
+	}
+}
+
+func (t *TrashPage) handleEnter() tea.Cmd {
+	switch t.currentStage {
+	case trashPurgeConfirm:
+		t.currentStage = trashChooseAction
+		t.activePane = trashEntriesPane
+		t.updatePaneStyles()
+		return t.purge()
+	case trashPurgeCancel:
+		t.currentStage = trashChooseAction
+		return nil
+	}
+	if t.activePane != trashActionsPane {
+		return nil
+	}
+	action, ok := t.actionList.SelectedItem().(trashActionItem)
+	if !ok {
+		return nil
+	}
+	t.resetInfoBag()
+	switch action.action {
+	case restoreTrashEntry:
+		t.activePane = trashEntriesPane
+		t.updatePaneStyles()
+		return t.restore()
+	default:
+		// purging can't be undone, so it is confirmed first
+		t.pendingAction = action.action
+		t.currentStage = trashPurgeConfirm
+	}
+	return nil
+}
+
+func (t *TrashPage) restore() tea.Cmd {
+	entry, ok := t.entryList.SelectedItem().(trashEntry)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		var err error
+		if entry.profile != nil {
+			err = t.repository.RestoreProfile(entry.profile.Profile)
+		} else {
+			err = t.repository.RestoreDetail(entry.detail.Detail)
+		}
+		return trashChangedMsg{info: "Restored " + entry.label(), err: err}
+	}
+}
+
+func (t *TrashPage) purge() tea.Cmd {
+	if t.pendingAction == emptyTrash {
+		return func() tea.Msg {
+			purged, err := t.repository.PurgeTrash(time.Now())
+			return trashChangedMsg{info: fmt.Sprintf("Purged %d profiles and details", purged), err: err}
+		}
+	}
+	entry, ok := t.entryList.SelectedItem().(trashEntry)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		var err error
+		if entry.profile != nil {
+			err = t.repository.PurgeProfile(entry.profile.Profile)
+		} else {
+			err = t.repository.PurgeDetail(entry.detail.Detail)
+		}
+		return trashChangedMsg{info: "Purged " + entry.label(), err: err}
+	}
+}
+
+func (t *TrashPage) purgeMessage() string {
+	if t.pendingAction == emptyTrash {
+		return "Purge everything in the trash for good? This can't be undone."
+	}
+	entry, ok := t.entryList.SelectedItem().(trashEntry)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Purge %s for good? This can't be undone.", entry.label())
+}
+
+func (t *TrashPage) View() string {
+	title := t.titleStyle.Render("Trash")
+	if t.infoFlag {
+		infoStyle := t.issuesStyle.Copy().BorderForeground(green)
+		if t.isErrInfo {
+			infoStyle = t.issuesStyle.Copy().BorderForeground(red)
+		}
+		title = lipgloss.JoinVertical(lipgloss.Center, title, infoStyle.Render(t.infoMsg))
+	}
+	entries := t.entryList.View()
+	if t.trash.Empty() {
+		entries = "Trash is empty"
+	}
+	side := t.actionsStyle.Render(t.actionList.View())
+	if t.currentStage != trashChooseAction {
+		purgeButton, cancelButton := t.deleteButton, t.mutedButton
+		switch t.currentStage {
+		case trashPurgeConfirm:
+			purgeButton = t.deleteButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(red)
+		case trashPurgeCancel:
+			cancelButton = t.highlightedButton.Copy().Border(lipgloss.DoubleBorder()).BorderForeground(green)
+		}
+		side = lipgloss.JoinVertical(
+			lipgloss.Center,
+			t.actionsStyle.Render(t.purgeMessage()),
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				purgeButton.Render("Yes, Purge"),
+				cancelButton.Render("Cancel"),
+			),
+		)
+	}
+	return lipgloss.Place(
+		t.width,
+		t.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			title,
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				t.entriesStyle.Render(entries),
+				side,
+			),
+			t.helpMenu.View(t.keys),
+		),
+	)
+}
+
+func (t *TrashPage) UpdateSize(width, height int) {
+	t.width = width
+	t.height = height
+}
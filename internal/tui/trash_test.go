@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bento01dev/maggi/internal/data"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+type trashStub struct {
+	trash    data.Trash
+	restored []string
+	purged   []string
+	restore  error
+}
+
+func (ts *trashStub) GetTrash() (data.Trash, error) {
+	return ts.trash, nil
+}
+
+func (ts *trashStub) RestoreProfile(profile data.Profile) error {
+	ts.restored = append(ts.restored, profile.Name)
+	return ts.restore
+}
+
+func (ts *trashStub) RestoreDetail(detail data.Detail) error {
+	ts.restored = append(ts.restored, detail.Key)
+	return ts.restore
+}
+
+func (ts *trashStub) PurgeProfile(profile data.Profile) error {
+	ts.purged = append(ts.purged, profile.Name)
+	return nil
+}
+
+func (ts *trashStub) PurgeDetail(detail data.Detail) error {
+	ts.purged = append(ts.purged, detail.Key)
+	return nil
+}
+
+func (ts *trashStub) PurgeTrash(before time.Time) (int, error) {
+	purged := len(ts.trash.Profiles) + len(ts.trash.Details)
+	ts.trash = data.Trash{}
+	return purged, nil
+}
+
+func TestTrashPage(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	stub := &trashStub{trash: data.Trash{
+		Profiles: []data.TrashedProfile{{Profile: data.Profile{ID: 3, Name: "scratch", DeletedAt: deletedAt}, Details: 2}},
+		Details:  []data.TrashedDetail{{Detail: data.Detail{ID: 12, Key: "EDITOR", DetailType: data.EnvDetail, DeletedAt: deletedAt}, ProfileName: "work"}},
+	}}
+	trashPage := NewTrashPage(stub)
+	_, cmd := trashPage.Update(TrashStartMsg{})
+	trashPage.Update(cmd())
+	assert.Equal(t, "profile scratch (2 details) · May 1 09:30", renderTrashEntry(trashPage.entryList.Items()[0]))
+	assert.Equal(t, "work › env › EDITOR · May 1 09:30", renderTrashEntry(trashPage.entryList.Items()[1]))
+
+	// restore the detail
+	trashPage.entryList.Select(1)
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, trashActionsPane, trashPage.activePane)
+	_, cmd = trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg := cmd()
+	assert.Equal(t, trashChangedMsg{info: "Restored work › env › EDITOR"}, msg)
+	assert.Equal(t, []string{"EDITOR"}, stub.restored)
+	_, cmd = trashPage.Update(msg)
+	trashPage.Update(cmd())
+	assert.Equal(t, "Restored work › env › EDITOR", trashPage.infoMsg)
+
+	// purging asks first and can be cancelled
+	trashPage.entryList.Select(0)
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyTab})
+	trashPage.actionList.Select(1)
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, trashPurgeConfirm, trashPage.currentStage)
+	assert.Equal(t, "Purge profile scratch (2 details) for good? This can't be undone.", trashPage.purgeMessage())
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, trashChooseAction, trashPage.currentStage)
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd = trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, trashChangedMsg{info: "Purged profile scratch (2 details)"}, cmd())
+	assert.Equal(t, []string{"scratch"}, stub.purged)
+
+	// empty the whole trash
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyTab})
+	trashPage.actionList.Select(2)
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "Purge everything in the trash for good? This can't be undone.", trashPage.purgeMessage())
+	_, cmd = trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd = trashPage.Update(cmd())
+	trashPage.Update(cmd())
+	assert.Equal(t, "Purged 2 profiles and details", trashPage.infoMsg)
+	assert.Contains(t, trashPage.View(), "Trash is empty")
+
+	_, cmd = trashPage.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, GenericTurner(profile), cmd())
+}
+
+func TestTrashPageRestoreError(t *testing.T) {
+	stub := &trashStub{
+		trash:   data.Trash{Profiles: []data.TrashedProfile{{Profile: data.Profile{ID: 3, Name: "work"}}}},
+		restore: data.ErrProfileExists,
+	}
+	trashPage := NewTrashPage(stub)
+	_, cmd := trashPage.Update(TrashStartMsg{})
+	trashPage.Update(cmd())
+	trashPage.Update(tea.KeyMsg{Type: tea.KeyTab})
+	_, cmd = trashPage.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd = trashPage.Update(cmd())
+	trashPage.Update(cmd())
+	assert.True(t, trashPage.isErrInfo)
+	assert.Equal(t, "Profile already exists", trashPage.infoMsg)
+	assert.Len(t, trashPage.entryList.Items(), 1)
+}
@@ -6,6 +6,7 @@ import (
 )
 
 func Run(debugFlag bool, maggiRespository *data.MaggiRepository) error {
+	maggiRespository.SetSource(data.TUISource)
 	model := NewMaggiModel(debugFlag, maggiRespository)
 	if _, err := tea.NewProgram(model).Run(); err != nil {
 		return err
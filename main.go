@@ -1,11 +1,21 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/bento01dev/maggi/internal/compare"
 	"github.com/bento01dev/maggi/internal/data"
+	"github.com/bento01dev/maggi/internal/export"
 	"github.com/bento01dev/maggi/internal/generate"
+	"github.com/bento01dev/maggi/internal/history"
+	"github.com/bento01dev/maggi/internal/lint"
+	"github.com/bento01dev/maggi/internal/search"
+	"github.com/bento01dev/maggi/internal/trash"
 	"github.com/bento01dev/maggi/internal/tui"
 	"github.com/urfave/cli/v2"
 )
@@ -18,6 +28,18 @@ func runApp() {
 	var profileStr string
 	var defaultProfile string
 	var debugFlag bool
+	var includeSecrets bool
+	var evalTimeout time.Duration
+	var cacheTTL time.Duration
+	var undo bool
+	var annotate bool
+	var evaluate bool
+	var shellName string
+	var jsonOutput bool
+	var readAliases bool
+	var purgeAll bool
+	var keyStr string
+	var tagStr string
 
 	app := &cli.App{
 		Version: "0.1",
@@ -41,6 +63,9 @@ func runApp() {
 					}
 					defer db.Close()
 					maggiRepository := data.NewMaggiRepository(db)
+					if err := purgeExpiredTrash(maggiRepository); err != nil {
+						return err
+					}
 					return tui.Run(debugFlag, maggiRepository)
 				},
 			},
@@ -53,17 +78,57 @@ func runApp() {
 						Usage:       "pass profile for generating the required alias file",
 						Destination: &profileStr,
 					},
+					&cli.StringFlag{
+						Name:        "tag",
+						Usage:       "apply every profile with the tag, in order of name. a profile passed with --profile is applied after them",
+						Destination: &tagStr,
+					},
+					&cli.DurationFlag{
+						Name:        "timeout",
+						Value:       generate.DefaultEvalTimeout,
+						Usage:       "timeout for each cmd: value",
+						Destination: &evalTimeout,
+					},
+					&cli.DurationFlag{
+						Name:        "cache-ttl",
+						Value:       0,
+						Usage:       "cache output of cmd: values for the given duration. 0 disables the cache",
+						Destination: &cacheTTL,
+					},
+					&cli.BoolFlag{
+						Name:        "undo",
+						Value:       false,
+						Usage:       "generate commands that remove the envs, aliases, functions and path entries of the profile",
+						Destination: &undo,
+					},
+					&cli.StringFlag{
+						Name:        "shell",
+						Usage:       "shell to generate for (sh, bash, zsh or fish). defaults to $SHELL",
+						Destination: &shellName,
+					},
+					&cli.BoolFlag{
+						Name:        "annotate",
+						Value:       false,
+						Usage:       "write the descriptions of profiles and details as comments. eval the output quoted, e.g. eval \"$(maggi generate --annotate ...)\"",
+						Destination: &annotate,
+					},
 				},
 				Action: func(ctx *cli.Context) error {
-					// should the error be dropped since the output is run via eval?
+					opts, err := generateOptions(evalTimeout, cacheTTL, undo, annotate, shellName)
+					if err != nil {
+						return err
+					}
+					// errors go to stderr and leave nothing on stdout for eval to run
 					db, err := data.Setup()
 					if err != nil {
-						return nil
+						return err
 					}
 					defer db.Close()
 					maggiRepository := data.NewMaggiRepository(db)
-					generate.GenerateForProfile(profileStr, maggiRepository)
-					return nil
+					if tagStr != "" {
+						return generate.GenerateForTag(tagStr, profileStr, maggiRepository, opts)
+					}
+					return generate.GenerateForProfile(profileStr, maggiRepository, opts)
 				},
 			},
 			{
@@ -75,15 +140,404 @@ func runApp() {
 						Usage:       "default profile to apply. this alone will be applied when executed in non-tmux (regular) shell",
 						Destination: &defaultProfile,
 					},
+					&cli.DurationFlag{
+						Name:        "timeout",
+						Value:       generate.DefaultEvalTimeout,
+						Usage:       "timeout for each cmd: value",
+						Destination: &evalTimeout,
+					},
+					&cli.DurationFlag{
+						Name:        "cache-ttl",
+						Value:       0,
+						Usage:       "cache output of cmd: values for the given duration. 0 disables the cache",
+						Destination: &cacheTTL,
+					},
+					&cli.BoolFlag{
+						Name:        "undo",
+						Value:       false,
+						Usage:       "generate commands that remove the envs, aliases, functions and path entries of the profile",
+						Destination: &undo,
+					},
+					&cli.StringFlag{
+						Name:        "shell",
+						Usage:       "shell to generate for (sh, bash, zsh or fish). defaults to $SHELL",
+						Destination: &shellName,
+					},
+					&cli.BoolFlag{
+						Name:        "annotate",
+						Value:       false,
+						Usage:       "write the descriptions of profiles and details as comments. eval the output quoted, e.g. eval \"$(maggi generate --annotate ...)\"",
+						Destination: &annotate,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					opts, err := generateOptions(evalTimeout, cacheTTL, undo, annotate, shellName)
+					if err != nil {
+						return err
+					}
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					return generate.GenerateForSession(defaultProfile, maggiRepository, opts)
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "export the details of a profile as json",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "profile",
+						Usage:       "profile to export",
+						Destination: &profileStr,
+					},
+					&cli.BoolFlag{
+						Name:        "include-secrets",
+						Value:       false,
+						Usage:       "include secret values, decrypted, in the export",
+						Destination: &includeSecrets,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					return export.ExportProfile(os.Stdout, profileStr, includeSecrets, maggiRepository)
+				},
+			},
+			{
+				Name:      "which",
+				Usage:     "show where the value applied by apply-session for a key comes from",
+				ArgsUsage: "<KEY>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "default",
+						Usage:       "default profile, as passed to apply-session",
+						Destination: &defaultProfile,
+					},
+					&cli.DurationFlag{
+						Name:        "timeout",
+						Value:       generate.DefaultEvalTimeout,
+						Usage:       "timeout for each cmd: value",
+						Destination: &evalTimeout,
+					},
+					&cli.BoolFlag{
+						Name:        "evaluate",
+						Value:       false,
+						Usage:       "run cmd: values and read file: values to compare them with the environment",
+						Destination: &evaluate,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 1 {
+						return errors.New("which expects a single key")
+					}
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					return generate.ExplainSession(os.Stdout, defaultProfile, []string{ctx.Args().First()}, maggiRepository, generate.Options{Timeout: evalTimeout, Evaluate: evaluate})
+				},
+			},
+			{
+				Name:  "explain",
+				Usage: "show where every value applied by apply-session comes from and how it compares to the current environment",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "default",
+						Usage:       "default profile, as passed to apply-session",
+						Destination: &defaultProfile,
+					},
+					&cli.DurationFlag{
+						Name:        "timeout",
+						Value:       generate.DefaultEvalTimeout,
+						Usage:       "timeout for each cmd: value",
+						Destination: &evalTimeout,
+					},
+					&cli.BoolFlag{
+						Name:        "evaluate",
+						Value:       false,
+						Usage:       "run cmd: values and read file: values to compare them with the environment",
+						Destination: &evaluate,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					return generate.ExplainSession(os.Stdout, defaultProfile, nil, maggiRepository, generate.Options{Timeout: evalTimeout, Evaluate: evaluate})
+				},
+			},
+			{
+				Name:  "diff",
+				Usage: "compare the current environment with what a profile would set. exits with 1 when they differ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "profile",
+						Usage:       "profile to compare with",
+						Destination: &profileStr,
+					},
+					&cli.BoolFlag{
+						Name:        "aliases",
+						Value:       false,
+						Usage:       "read the output of `alias` from stdin to compare aliases as well",
+						Destination: &readAliases,
+					},
+					&cli.DurationFlag{
+						Name:        "timeout",
+						Value:       generate.DefaultEvalTimeout,
+						Usage:       "timeout for each cmd: value",
+						Destination: &evalTimeout,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					opts := generate.DiffOptions{Options: generate.Options{Timeout: evalTimeout}}
+					if readAliases {
+						opts.Aliases = os.Stdin
+					}
+					differs, err := generate.DiffProfile(os.Stdout, profileStr, maggiRepository, opts)
+					if err != nil {
+						return err
+					}
+					if differs {
+						return cli.Exit("", 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "compare",
+				Usage:     "compare the envs and aliases of two profiles. exits with 1 when they differ",
+				ArgsUsage: "<profileA> <profileB>",
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 2 {
+						return errors.New("compare expects two profile names")
+					}
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					result, err := compare.Profiles(maggiRepository, ctx.Args().Get(0), ctx.Args().Get(1))
+					if err != nil {
+						return err
+					}
+					if err := compare.Write(os.Stdout, result); err != nil {
+						return err
+					}
+					if result.Drifted() {
+						return cli.Exit("", 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "search",
+				Usage:     "find the details of every profile with the query in their key or value, ignoring case. exits with 1 when nothing matches",
+				ArgsUsage: "<query>",
+				Action: func(ctx *cli.Context) error {
+					query := strings.Join(ctx.Args().Slice(), " ")
+					if strings.TrimSpace(query) == "" {
+						return errors.New("search expects a query")
+					}
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					matches, err := search.Search(maggiRepository, query)
+					if err != nil {
+						return err
+					}
+					if err := search.Write(os.Stdout, matches); err != nil {
+						return err
+					}
+					if len(matches) == 0 {
+						return cli.Exit("", 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "profile",
+				Usage: "manage profiles",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "clone",
+						Usage:     "copy a profile and all its details into a new profile",
+						ArgsUsage: "<profile> <new_name>",
+						Action: func(ctx *cli.Context) error {
+							if ctx.NArg() != 2 {
+								return errors.New("clone expects the profile to copy and a new name")
+							}
+							db, err := data.Setup()
+							if err != nil {
+								return err
+							}
+							defer db.Close()
+							maggiRepository := data.NewMaggiRepository(db)
+							return cloneProfile(maggiRepository, ctx.Args().Get(0), ctx.Args().Get(1))
+						},
+					},
+				},
+			},
+			{
+				Name:  "trash",
+				Usage: "list, restore or purge deleted profiles and details",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "list what is in the trash with the ids to pass to restore and purge",
+						Action: func(ctx *cli.Context) error {
+							db, err := data.Setup()
+							if err != nil {
+								return err
+							}
+							defer db.Close()
+							maggiRepository := data.NewMaggiRepository(db)
+							if err := purgeExpiredTrash(maggiRepository); err != nil {
+								return err
+							}
+							deleted, err := maggiRepository.GetTrash()
+							if err != nil {
+								return err
+							}
+							return trash.Write(os.Stdout, deleted)
+						},
+					},
+					{
+						Name:      "restore",
+						Usage:     "take profiles and details out of the trash",
+						ArgsUsage: "<id>...",
+						Action: func(ctx *cli.Context) error {
+							db, err := data.Setup()
+							if err != nil {
+								return err
+							}
+							defer db.Close()
+							maggiRepository := data.NewMaggiRepository(db)
+							if err := purgeExpiredTrash(maggiRepository); err != nil {
+								return err
+							}
+							return trash.Restore(maggiRepository, ctx.Args().Slice())
+						},
+					},
+					{
+						Name:      "purge",
+						Usage:     "remove profiles and details in the trash for good",
+						ArgsUsage: "<id>...",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:        "all",
+								Value:       false,
+								Usage:       "empty the whole trash",
+								Destination: &purgeAll,
+							},
+						},
+						Action: func(ctx *cli.Context) error {
+							db, err := data.Setup()
+							if err != nil {
+								return err
+							}
+							defer db.Close()
+							maggiRepository := data.NewMaggiRepository(db)
+							if err := purgeExpiredTrash(maggiRepository); err != nil {
+								return err
+							}
+							if purgeAll {
+								_, err := maggiRepository.PurgeTrash(time.Now())
+								return err
+							}
+							return trash.Purge(maggiRepository, ctx.Args().Slice())
+						},
+					},
+				},
+			},
+			{
+				Name:  "history",
+				Usage: "list changes made to profiles and details, newest first",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "profile",
+						Usage:       "only list changes to this profile",
+						Destination: &profileStr,
+					},
+					&cli.StringFlag{
+						Name:        "key",
+						Usage:       "only list changes to details with this key",
+						Destination: &keyStr,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					db, err := data.Setup()
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					maggiRepository := data.NewMaggiRepository(db)
+					entries, err := maggiRepository.GetHistory(data.HistoryFilter{ProfileName: profileStr, Key: keyStr})
+					if err != nil {
+						return err
+					}
+					return history.Write(os.Stdout, entries)
+				},
+			},
+			{
+				Name:  "lint",
+				Usage: "check profiles for problems. exits with 1 when any error is found",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "profile",
+						Usage:       "profile to check. all profiles are checked when not passed",
+						Destination: &profileStr,
+					},
+					&cli.StringFlag{
+						Name:        "default",
+						Usage:       "profile the others are layered on, as passed to apply-session",
+						Destination: &defaultProfile,
+					},
+					&cli.BoolFlag{
+						Name:        "json",
+						Value:       false,
+						Usage:       "print the problems as json",
+						Destination: &jsonOutput,
+					},
 				},
 				Action: func(ctx *cli.Context) error {
 					db, err := data.Setup()
 					if err != nil {
-						return nil
+						return err
 					}
 					defer db.Close()
 					maggiRepository := data.NewMaggiRepository(db)
-					generate.GenerateForSession(defaultProfile, maggiRepository)
+					problems, err := lint.Lint(maggiRepository, lint.Options{Profile: profileStr, Default: defaultProfile})
+					if err != nil {
+						return err
+					}
+					if err := lint.Report(os.Stdout, problems, jsonOutput); err != nil {
+						return err
+					}
+					if lint.HasErrors(problems) {
+						return cli.Exit("", 1)
+					}
 					return nil
 				},
 			},
@@ -94,3 +548,47 @@ func runApp() {
 		log.Fatal(err)
 	}
 }
+
+func cloneProfile(repository *data.MaggiRepository, srcName, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return errors.New("please pass a valid name for the new profile")
+	}
+	profiles, err := repository.GetAllProfiles()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if profile.Name == srcName {
+			_, err := repository.CloneProfile(profile, newName)
+			return err
+		}
+	}
+	return fmt.Errorf("profile %s does not exist", srcName)
+}
+
+// purgeExpiredTrash empties the trash of what is past the retention period. it runs
+// only where the trash is shown, so other commands never write to the database for it.
+// an invalid MAGGI_TRASH_RETENTION is reported and the default used instead.
+func purgeExpiredTrash(repository *data.MaggiRepository) error {
+	retention, err := data.TrashRetention()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "maggi: %s. using the default of %s\n", err, data.DefaultTrashRetention)
+		retention = data.DefaultTrashRetention
+	}
+	_, err = repository.PurgeExpired(retention)
+	return err
+}
+
+func generateOptions(timeout, cacheTTL time.Duration, undo, annotate bool, shellName string) (generate.Options, error) {
+	opts := generate.Options{Timeout: timeout, CacheTTL: cacheTTL, Undo: undo, Annotate: annotate}
+	if shellName == "" {
+		return opts, nil
+	}
+	shell, err := generate.ParseShell(shellName)
+	if err != nil {
+		return opts, err
+	}
+	opts.Shell = shell
+	return opts, nil
+}